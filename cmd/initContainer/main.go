@@ -4,6 +4,7 @@ Cleans up stale webhookconfigurations created by kyverno that were not cleanedup
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"regexp"
@@ -16,8 +17,6 @@ import (
 	client "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/signal"
 	"k8s.io/apimachinery/pkg/api/errors"
-	rest "k8s.io/client-go/rest"
-	clientcmd "k8s.io/client-go/tools/clientcmd"
 )
 
 var (
@@ -34,7 +33,7 @@ func main() {
 	// os signal handler
 	stopCh := signal.SetupSignalHandler()
 	// create client config
-	clientConfig, err := createClientConfig(kubeconfig)
+	clientConfig, err := config.CreateClientConfig(kubeconfig)
 	if err != nil {
 		glog.Fatalf("Error building kubeconfig: %v\n", err)
 	}
@@ -100,7 +99,7 @@ func init() {
 func removeWebhookIfExists(client *client.Client, kind string, name string) error {
 	var err error
 	// Get resource
-	_, err = client.GetResource(kind, "", name)
+	_, err = client.GetResource(context.TODO(), kind, "", name)
 	if errors.IsNotFound(err) {
 		glog.V(4).Infof("%s(%s) not found", name, kind)
 		return nil
@@ -110,7 +109,7 @@ func removeWebhookIfExists(client *client.Client, kind string, name string) erro
 		return err
 	}
 	// Delete resource
-	err = client.DeleteResource(kind, "", name, false)
+	err = client.DeleteResource(context.TODO(), kind, "", name, false)
 	if err != nil {
 		glog.Errorf("failed to delete resource %s(%s)", name, kind)
 		return err
@@ -119,15 +118,6 @@ func removeWebhookIfExists(client *client.Client, kind string, name string) erro
 	return nil
 }
 
-func createClientConfig(kubeconfig string) (*rest.Config, error) {
-	if kubeconfig == "" {
-		glog.Info("Using in-cluster configuration")
-		return rest.InClusterConfig()
-	}
-	glog.Infof("Using configuration from '%s'", kubeconfig)
-	return clientcmd.BuildConfigFromFlags("", kubeconfig)
-}
-
 type request struct {
 	kind string
 	name string
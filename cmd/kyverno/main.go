@@ -9,9 +9,11 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/nirmata/kyverno/pkg/checker"
+	"github.com/nirmata/kyverno/pkg/cleanuppolicy"
 	kyvernoclient "github.com/nirmata/kyverno/pkg/client/clientset/versioned"
 	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions"
 	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/cosign"
 	dclient "github.com/nirmata/kyverno/pkg/dclient"
 	event "github.com/nirmata/kyverno/pkg/event"
 	"github.com/nirmata/kyverno/pkg/generate"
@@ -20,12 +22,15 @@ import (
 	"github.com/nirmata/kyverno/pkg/policystatus"
 	"github.com/nirmata/kyverno/pkg/policystore"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
+	"github.com/nirmata/kyverno/pkg/resourcecache"
+	"github.com/nirmata/kyverno/pkg/resultcache"
 	"github.com/nirmata/kyverno/pkg/signal"
 	"github.com/nirmata/kyverno/pkg/utils"
 	"github.com/nirmata/kyverno/pkg/version"
 	"github.com/nirmata/kyverno/pkg/webhookconfig"
 	"github.com/nirmata/kyverno/pkg/webhooks"
 	webhookgenerate "github.com/nirmata/kyverno/pkg/webhooks/generate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 )
 
@@ -39,6 +44,12 @@ var (
 	filterK8Resources string
 	// User FQDN as CSR CN
 	fqdncn bool
+	// number of admission requests for which validation results are cached, 0 disables the cache
+	resultCacheSize int
+	// no calls are made outside of the cluster, e.g. to image registries
+	offline bool
+	// generated resources are submitted in dry-run mode before they are persisted
+	generateDryRun bool
 )
 
 func main() {
@@ -90,6 +101,17 @@ func main() {
 	kubeInformer := kubeinformers.NewSharedInformerFactoryWithOptions(
 		kubeClient,
 		10*time.Second)
+	// serve namespaces, and the config maps labelled as clone sources, from the informer caches
+	// - no resync, the cache only serves reads
+	cloneSourceInformer := kubeinformers.NewSharedInformerFactoryWithOptions(
+		kubeClient,
+		0,
+		kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = dclient.CloneSourceLabel + "=true"
+		}))
+	client.SetTypedCache(
+		kubeInformer.Core().V1().Namespaces(),
+		cloneSourceInformer.Core().V1().ConfigMaps())
 	// KUBERNETES Dynamic informer
 	// - cahce resync time: 10 seconds
 	kubedynamicInformer := client.NewDynamicSharedInformerFactory(10 * time.Second)
@@ -143,6 +165,13 @@ func main() {
 		pclient,
 		policyMetaStore)
 
+	// ROLLOUT CONTROLLER
+	// - audits the policies with a rollout strategy during the observation window
+	// - promotes them to enforce, the decision is recorded in the policy status
+	rolloutController := policystatus.NewRolloutController(
+		pInformer.Kyverno().V1().ClusterPolicies().Lister(),
+		statusSync.Listener)
+
 	// POLICY VIOLATION GENERATOR
 	// -- generate policy violation
 	pvgen := policyviolation.NewPVGenerator(pclient,
@@ -161,6 +190,7 @@ func main() {
 		pInformer.Kyverno().V1().ClusterPolicies(),
 		pInformer.Kyverno().V1().ClusterPolicyViolations(),
 		pInformer.Kyverno().V1().PolicyViolations(),
+		pInformer.Kyverno().V1().PolicyExceptions(),
 		configData,
 		egen,
 		pvgen,
@@ -170,31 +200,46 @@ func main() {
 		glog.Fatalf("error creating policy controller: %v\n", err)
 	}
 
-	// GENERATE REQUEST GENERATOR
+	// UPDATE REQUEST GENERATOR
 	grgen := webhookgenerate.NewGenerator(pclient, stopCh)
 
-	// GENERATE CONTROLLER
-	// - applies generate rules on resources based on generate requests created by webhook
+	// the generate requests of previous versions are processed as update requests
+	if err := generate.MigrateGenerateRequests(pclient); err != nil {
+		glog.Errorf("failed to migrate generate requests: %v\n", err)
+	}
+
+	// UPDATE REQUEST CONTROLLER
+	// - applies generate rules, and mutate rules on existing targets, based on update requests created by webhook
 	grc := generate.NewController(
 		pclient,
 		client,
 		pInformer.Kyverno().V1().ClusterPolicies(),
-		pInformer.Kyverno().V1().GenerateRequests(),
+		pInformer.Kyverno().V1().UpdateRequests(),
 		egen,
 		pvgen,
 		kubedynamicInformer,
 		statusSync.Listener,
+		generateDryRun,
 	)
-	// GENERATE REQUEST CLEANUP
-	// -- cleans up the generate requests that have not been processed(i.e. state = [Pending, Failed]) for more than defined timeout
+	// UPDATE REQUEST CLEANUP
+	// -- cleans up the update requests, and the generated resources, once the trigger resource is deleted
 	grcc := generatecleanup.NewController(
 		pclient,
 		client,
 		pInformer.Kyverno().V1().ClusterPolicies(),
-		pInformer.Kyverno().V1().GenerateRequests(),
+		pInformer.Kyverno().V1().UpdateRequests(),
 		kubedynamicInformer,
 	)
 
+	// CLEANUP POLICY CONTROLLER
+	// - deletes the resources matching the cleanup policies on their schedule
+	cpc := cleanuppolicy.NewController(
+		pclient,
+		client,
+		pInformer.Kyverno().V1().CleanupPolicies(),
+		configData,
+	)
+
 	// CONFIGURE CERTIFICATES
 	tlsPair, err := client.InitTLSPemPair(clientConfig, fqdncn)
 	if err != nil {
@@ -210,6 +255,26 @@ func main() {
 		glog.Fatalf("Failed registering Admission Webhooks: %v\n", err)
 	}
 
+	// RESULT CACHE
+	// - caches the validation results of byte-identical admission requests
+	// - purged on any policy change
+	var resultCache *resultcache.Cache
+	if resultCacheSize > 0 {
+		resultCache, err = resultcache.NewCache(resultCacheSize, pInformer.Kyverno().V1().ClusterPolicies(), pInformer.Kyverno().V1().PolicyExceptions())
+		if err != nil {
+			glog.Fatalf("Failed to create result cache: %v\n", err)
+		}
+	}
+
+	// IMAGE VERIFIER
+	// - reads the image signatures from the registries
+	// - fails the verification of all images in offline mode
+	imageVerifier := cosign.NewVerifier()
+	if offline {
+		glog.Info("offline mode enabled, image registries are not contacted")
+		imageVerifier = cosign.NewOfflineVerifier()
+	}
+
 	// Sync openAPI definitions of resources
 	openApiSync := openapi.NewCRDSync(client)
 
@@ -224,6 +289,7 @@ func main() {
 		client,
 		tlsPair,
 		pInformer.Kyverno().V1().ClusterPolicies(),
+		pInformer.Kyverno().V1().PolicyExceptions(),
 		kubeInformer.Rbac().V1().RoleBindings(),
 		kubeInformer.Rbac().V1().ClusterRoleBindings(),
 		egen,
@@ -234,6 +300,9 @@ func main() {
 		pvgen,
 		grgen,
 		rWebhookWatcher,
+		resultCache,
+		imageVerifier,
+		resourcecache.NewCache(client, kubedynamicInformer, pInformer.Kyverno().V1().ClusterPolicies(), stopCh),
 		cleanUp)
 	if err != nil {
 		glog.Fatalf("Unable to create webhook server: %v\n", err)
@@ -241,6 +310,7 @@ func main() {
 	// Start the components
 	pInformer.Start(stopCh)
 	kubeInformer.Start(stopCh)
+	cloneSourceInformer.Start(stopCh)
 	kubedynamicInformer.Start(stopCh)
 	go grgen.Run(1)
 	go rWebhookWatcher.Run(stopCh)
@@ -250,8 +320,10 @@ func main() {
 	go egen.Run(1, stopCh)
 	go grc.Run(1, stopCh)
 	go grcc.Run(1, stopCh)
+	go cpc.Run(stopCh)
 	go pvgen.Run(1, stopCh)
 	go statusSync.Run(1, stopCh)
+	go rolloutController.Run(stopCh)
 	go openApiSync.Run(1, stopCh)
 
 	// verifys if the admission control is enabled and active
@@ -286,6 +358,9 @@ func init() {
 
 	// Generate CSR with CN as FQDN due to https://github.com/nirmata/kyverno/issues/542
 	flag.BoolVar(&fqdncn, "fqdn-as-cn", false, "use FQDN as Common Name in CSR")
+	flag.IntVar(&resultCacheSize, "resultCacheSize", 0, "number of admission requests for which the results of validate-only policies are cached, set to 0 to disable the cache")
+	flag.BoolVar(&offline, "offline", false, "air-gapped mode, no calls are made outside of the cluster and rules requiring them fail")
+	flag.BoolVar(&generateDryRun, "generateDryRun", false, "submit all the resources of a generate policy in dry-run mode before any of them is persisted, doubles the API calls of generate requests")
 	config.LogDefaultFlags()
 	flag.Parse()
 }
@@ -0,0 +1,236 @@
+package admissionpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nirmata/kyverno/pkg/engine/anchor"
+	"github.com/nirmata/kyverno/pkg/engine/operator"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	identifier    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	numberPattern = regexp.MustCompile(`^(\d*(\.\d+)?)(.*)`)
+	// reserved words of CEL, the fields named after them are escaped by the API server as __word__
+	reserved = map[string]bool{
+		"as": true, "break": true, "const": true, "continue": true, "else": true, "false": true,
+		"for": true, "function": true, "if": true, "import": true, "in": true, "let": true,
+		"loop": true, "namespace": true, "null": true, "package": true, "return": true,
+		"true": true, "var": true, "void": true, "while": true,
+	}
+)
+
+// expressionBuilder translates validation patterns into CEL expressions
+type expressionBuilder struct {
+	// depth of the nested list macros, used to name their variables
+	depth int
+}
+
+// patternExpression returns the expression checking the value at path matches the pattern,
+// an error is returned if the pattern cannot be expressed in CEL
+func (b *expressionBuilder) patternExpression(path string, pattern interface{}) (string, error) {
+	switch typedPattern := pattern.(type) {
+	case map[string]interface{}:
+		return b.mapExpression(path, typedPattern)
+	case []interface{}:
+		if len(typedPattern) != 1 {
+			return "", fmt.Errorf("list patterns with %d elements are not supported", len(typedPattern))
+		}
+		variable := fmt.Sprintf("e%d", b.depth)
+		b.depth++
+		expression, err := b.patternExpression(variable, typedPattern[0])
+		b.depth--
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s.all(%s, %s)", path, variable, expression), nil
+	case bool:
+		return fmt.Sprintf("%s == %t", path, typedPattern), nil
+	case int:
+		return numberExpression(path, operator.Equal, strconv.Itoa(typedPattern)), nil
+	case int64:
+		return numberExpression(path, operator.Equal, strconv.FormatInt(typedPattern, 10)), nil
+	case float64:
+		return numberExpression(path, operator.Equal, formatNumber(typedPattern)), nil
+	case string:
+		return stringPatternExpression(path, typedPattern)
+	case nil:
+		return "", fmt.Errorf("null patterns are not supported")
+	default:
+		return "", fmt.Errorf("pattern %v of type %T is not supported", pattern, pattern)
+	}
+}
+
+func (b *expressionBuilder) mapExpression(path string, pattern map[string]interface{}) (string, error) {
+	keys := make([]string, 0, len(pattern))
+	for key := range pattern {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conditions []string
+	for _, key := range keys {
+		switch {
+		case anchor.IsNegationAnchor(key):
+			_, has := selectField(path, key[2:len(key)-1])
+			conditions = append(conditions, "!"+has)
+		case anchor.IsEqualityAnchor(key):
+			field, has := selectField(path, key[2:len(key)-1])
+			expression, err := b.patternExpression(field, pattern[key])
+			if err != nil {
+				return "", err
+			}
+			if expression != "true" {
+				conditions = append(conditions, fmt.Sprintf("(!%s || %s)", has, expression))
+			}
+		case anchor.IsConditionAnchor(key), anchor.IsExistenceAnchor(key), anchor.IsAddingAnchor(key):
+			return "", fmt.Errorf("anchor %s is not supported", key)
+		default:
+			field, has := selectField(path, key)
+			expression, err := b.patternExpression(field, pattern[key])
+			if err != nil {
+				return "", err
+			}
+			if expression == "true" {
+				conditions = append(conditions, has)
+			} else {
+				conditions = append(conditions, fmt.Sprintf("%s && %s", has, expression))
+			}
+		}
+	}
+	if len(conditions) == 0 {
+		return "true", nil
+	}
+	return strings.Join(conditions, " && "), nil
+}
+
+// selectField returns the expressions selecting the field of the value at path,
+// and testing the presence of the field. The keys that are not identifiers are
+// assumed to be map keys, e.g. labels, and are selected with the index operator
+func selectField(path, key string) (string, string) {
+	if identifier.MatchString(key) {
+		if reserved[key] {
+			key = "__" + key + "__"
+		}
+		field := path + "." + key
+		return field, "has(" + field + ")"
+	}
+	quoted := strconv.Quote(key)
+	return path + "[" + quoted + "]", quoted + " in " + path
+}
+
+// stringPatternExpression translates a string pattern, which holds alternatives separated by '|'
+func stringPatternExpression(path, pattern string) (string, error) {
+	if strings.Contains(pattern, "{{") || strings.Contains(pattern, "$(") {
+		return "", fmt.Errorf("variables and references in pattern %q are not supported", pattern)
+	}
+	statements := strings.Split(pattern, "|")
+	var expressions []string
+	for _, statement := range statements {
+		expression, err := statementExpression(path, strings.Trim(statement, " "))
+		if err != nil {
+			return "", err
+		}
+		if expression == "true" {
+			return "true", nil
+		}
+		expressions = append(expressions, expression)
+	}
+	if len(expressions) == 1 {
+		return expressions[0], nil
+	}
+	return "(" + strings.Join(expressions, " || ") + ")", nil
+}
+
+// statementExpression translates a single pattern statement, as evaluated by the engine:
+// numbers and quantities are compared with the operator, strings are matched with wildcards
+func statementExpression(path, statement string) (string, error) {
+	op := operator.GetOperatorFromStringPattern(statement)
+	value := statement[len(op):]
+	matches := numberPattern.FindStringSubmatch(value)
+	number, suffix := matches[1], matches[3]
+
+	if number != "" {
+		if suffix == "" {
+			return numberExpression(path, op, number), nil
+		}
+		if _, err := apiresource.ParseQuantity(value); err == nil {
+			return fmt.Sprintf("quantity(string(%s)).compareTo(quantity(%s)) %s 0", path, strconv.Quote(value), celOperator(op)), nil
+		}
+		// neither a number nor a quantity, the value is matched as a string
+	}
+
+	var expression string
+	switch op {
+	case operator.Equal, operator.NotEqual:
+		expression = wildcardExpression(path, value)
+	default:
+		return "", fmt.Errorf("operator %s is not applicable to the string %q", op, value)
+	}
+	if op == operator.NotEqual {
+		if expression == "true" {
+			return "false", nil
+		}
+		return "!(" + expression + ")", nil
+	}
+	return expression, nil
+}
+
+// numberExpression compares the value at path with the number. As in the engine, the value can be
+// a number or a string holding a number: strings are compared as quantities, numbers as doubles
+// since CEL does not compare integers with doubles
+func numberExpression(path string, op operator.Operator, number string) string {
+	literal := number
+	if !strings.Contains(literal, ".") {
+		literal += ".0"
+	}
+	return fmt.Sprintf("(type(%s) == string ? quantity(%s).compareTo(quantity(%s)) %s 0 : double(%s) %s %s)",
+		path, path, strconv.Quote(number), celOperator(op), path, celOperator(op), literal)
+}
+
+func wildcardExpression(path, pattern string) string {
+	switch {
+	case pattern == "*":
+		return "true"
+	case pattern == "?*":
+		return fmt.Sprintf("size(%s) > 0", path)
+	case !strings.ContainsAny(pattern, "*?"):
+		return fmt.Sprintf("%s == %s", path, strconv.Quote(pattern))
+	}
+	var regex strings.Builder
+	regex.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			regex.WriteString(".*")
+		case '?':
+			regex.WriteString(".")
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	regex.WriteString("$")
+	return fmt.Sprintf("%s.matches(%s)", path, strconv.Quote(regex.String()))
+}
+
+func celOperator(op operator.Operator) string {
+	switch op {
+	case operator.Equal:
+		return "=="
+	case operator.NotEqual:
+		return "!="
+	default:
+		return string(op)
+	}
+}
+
+func formatNumber(f float64) string {
+	if f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,243 @@
+package admissionpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/openapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceNameLabel is set by the API server on namespaces to their name
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// legacyGroups are the API groups without the k8s.io suffix
+var legacyGroups = map[string]bool{
+	"apps":        true,
+	"autoscaling": true,
+	"batch":       true,
+	"extensions":  true,
+	"policy":      true,
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// SkippedRule is a validate rule that cannot be expressed as a ValidatingAdmissionPolicy
+type SkippedRule struct {
+	Rule   string
+	Reason error
+}
+
+// Generate translates the validate rules of the policy into ValidatingAdmissionPolicies and their bindings,
+// one per rule. The rules that cannot be expressed in CEL are returned with the reason, they still have
+// to be enforced by Kyverno
+func Generate(policy kyverno.ClusterPolicy) ([]ValidatingAdmissionPolicy, []ValidatingAdmissionPolicyBinding, []SkippedRule) {
+	var policies []ValidatingAdmissionPolicy
+	var bindings []ValidatingAdmissionPolicyBinding
+	var skipped []SkippedRule
+	for _, rule := range policy.Spec.Rules {
+		if !rule.HasValidate() {
+			continue
+		}
+		admissionPolicy, err := generateRule(policy, rule)
+		if err != nil {
+			skipped = append(skipped, SkippedRule{Rule: rule.Name, Reason: err})
+			continue
+		}
+		policies = append(policies, *admissionPolicy)
+		bindings = append(bindings, generateBinding(policy, admissionPolicy.Name))
+	}
+	return policies, bindings, skipped
+}
+
+func generateRule(policy kyverno.ClusterPolicy, rule kyverno.Rule) (*ValidatingAdmissionPolicy, error) {
+	if rule.HasMutate() || rule.HasGenerate() || rule.HasVerifyImages() {
+		return nil, fmt.Errorf("rules with other actions than validate are not supported")
+	}
+	if len(rule.Conditions) != 0 {
+		return nil, fmt.Errorf("preconditions are not supported")
+	}
+	if rule.Validation.Count != nil {
+		return nil, fmt.Errorf("count rules are not supported")
+	}
+	matchConstraints, matchConditions, err := generateMatch(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var expression string
+	if rule.Validation.Pattern != nil {
+		builder := expressionBuilder{}
+		if expression, err = builder.patternExpression("object", rule.Validation.Pattern); err != nil {
+			return nil, err
+		}
+	} else {
+		var expressions []string
+		for _, pattern := range rule.Validation.AnyPattern {
+			builder := expressionBuilder{}
+			e, err := builder.patternExpression("object", pattern)
+			if err != nil {
+				return nil, err
+			}
+			expressions = append(expressions, "("+e+")")
+		}
+		expression = strings.Join(expressions, " || ")
+	}
+
+	message := rule.Validation.Message
+	if message == "" || strings.Contains(message, "{{") {
+		message = fmt.Sprintf("validation rule %s of policy %s failed", rule.Name, policy.Name)
+	}
+
+	return &ValidatingAdmissionPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: APIVersion,
+			Kind:       "ValidatingAdmissionPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: policyName(policy.Name, rule.Name),
+		},
+		Spec: ValidatingAdmissionPolicySpec{
+			FailurePolicy:    "Fail",
+			MatchConstraints: matchConstraints,
+			MatchConditions:  matchConditions,
+			Validations: []Validation{
+				{
+					Expression: expression,
+					Message:    message,
+				},
+			},
+		},
+	}, nil
+}
+
+// generateMatch translates the match and exclude blocks of the rule, only the namespaces can be excluded
+func generateMatch(rule kyverno.Rule) (MatchResources, []MatchCondition, error) {
+	var match MatchResources
+	var conditions []MatchCondition
+
+	if hasUserInfo(rule.MatchResources.UserInfo) || hasUserInfo(rule.ExcludeResources.UserInfo) {
+		return match, nil, fmt.Errorf("roles, clusterRoles and subjects are not supported")
+	}
+	exclude := rule.ExcludeResources.ResourceDescription
+	if len(exclude.Kinds) != 0 || exclude.Name != "" || exclude.Selector != nil {
+		return match, nil, fmt.Errorf("only namespaces can be excluded")
+	}
+	resources := rule.MatchResources.ResourceDescription
+	if len(resources.Kinds) == 0 {
+		return match, nil, fmt.Errorf("match.resources.kinds is required")
+	}
+
+	var resourceNames []string
+	if resources.Name != "" {
+		if strings.ContainsAny(resources.Name, "*?") {
+			conditions = append(conditions, MatchCondition{
+				Name:       "name",
+				Expression: wildcardExpression("object.metadata.name", resources.Name),
+			})
+		} else {
+			resourceNames = []string{resources.Name}
+		}
+	}
+	for _, kind := range resources.Kinds {
+		group, resource := resourceForKind(kind)
+		match.ResourceRules = append(match.ResourceRules, NamedRuleWithOperations{
+			ResourceNames: resourceNames,
+			Operations:    []string{"CREATE", "UPDATE"},
+			APIGroups:     []string{group},
+			APIVersions:   []string{"*"},
+			Resources:     []string{resource},
+		})
+	}
+
+	var namespaceRequirements []metav1.LabelSelectorRequirement
+	if len(resources.Namespaces) != 0 {
+		namespaceRequirements = append(namespaceRequirements, metav1.LabelSelectorRequirement{
+			Key:      namespaceNameLabel,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   resources.Namespaces,
+		})
+	}
+	if len(exclude.Namespaces) != 0 {
+		namespaceRequirements = append(namespaceRequirements, metav1.LabelSelectorRequirement{
+			Key:      namespaceNameLabel,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   exclude.Namespaces,
+		})
+	}
+	if len(namespaceRequirements) != 0 {
+		match.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: namespaceRequirements}
+	}
+	match.ObjectSelector = resources.Selector
+	return match, conditions, nil
+}
+
+func generateBinding(policy kyverno.ClusterPolicy, name string) ValidatingAdmissionPolicyBinding {
+	action := "Audit"
+	if policy.GetValidationFailureAction() == "enforce" {
+		action = "Deny"
+	}
+	return ValidatingAdmissionPolicyBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: APIVersion,
+			Kind:       "ValidatingAdmissionPolicyBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        name,
+			ValidationActions: []string{action},
+		},
+	}
+}
+
+func hasUserInfo(userInfo kyverno.UserInfo) bool {
+	return len(userInfo.Roles) != 0 || len(userInfo.ClusterRoles) != 0 || len(userInfo.Subjects) != 0
+}
+
+// resourceForKind returns the API group and the resource of the kind, using the OpenAPI
+// definitions of the built-in kinds. Any group is matched for the other kinds
+func resourceForKind(kind string) (string, string) {
+	resource := pluralize(strings.ToLower(kind))
+	definition := openapi.GetDefinitionNameFromKind(kind)
+	if definition == "" {
+		return "*", resource
+	}
+	// e.g. io.k8s.api.apps.v1.Deployment or io.k8s.apiextensions-apiserver.pkg.apis.apiextensions.v1.CustomResourceDefinition
+	parts := strings.Split(strings.TrimPrefix(definition, "io.k8s."), ".")
+	if len(parts) < 3 {
+		return "*", resource
+	}
+	group := parts[len(parts)-3]
+	switch {
+	case group == "core":
+		group = ""
+	case group == "rbac":
+		group = "rbac.authorization.k8s.io"
+	case !legacyGroups[group]:
+		group += ".k8s.io"
+	}
+	return group, resource
+}
+
+func pluralize(kind string) string {
+	switch {
+	case kind == "endpoints":
+		return kind
+	case strings.HasSuffix(kind, "s"), strings.HasSuffix(kind, "x"), strings.HasSuffix(kind, "ch"), strings.HasSuffix(kind, "sh"):
+		return kind + "es"
+	case len(kind) > 1 && strings.HasSuffix(kind, "y") && !strings.ContainsAny(kind[len(kind)-2:len(kind)-1], "aeiou"):
+		return kind[:len(kind)-1] + "ies"
+	default:
+		return kind + "s"
+	}
+}
+
+// policyName returns a valid resource name for the ValidatingAdmissionPolicy of the rule
+func policyName(policy, rule string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(policy+"-"+rule), "-")
+	return strings.Trim(name, "-.")
+}
@@ -0,0 +1,174 @@
+package admissionpolicy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_PatternExpression(t *testing.T) {
+	testCases := []struct {
+		pattern    string
+		expression string
+	}{
+		{
+			pattern:    `{"metadata": {"labels": {"app.kubernetes.io/name": "?*"}}}`,
+			expression: `has(object.metadata) && has(object.metadata.labels) && "app.kubernetes.io/name" in object.metadata.labels && size(object.metadata.labels["app.kubernetes.io/name"]) > 0`,
+		},
+		{
+			pattern:    `{"spec": {"containers": [{"image": "!*:latest"}]}}`,
+			expression: `has(object.spec) && has(object.spec.containers) && object.spec.containers.all(e0, has(e0.image) && !(e0.image.matches("^.*:latest$")))`,
+		},
+		{
+			pattern:    `{"spec": {"=(hostNetwork)": false, "X(hostPID)": null}}`,
+			expression: `has(object.spec) && (!has(object.spec.hostNetwork) || object.spec.hostNetwork == false) && !has(object.spec.hostPID)`,
+		},
+		{
+			pattern:    `{"spec": {"replicas": ">1 | 0"}}`,
+			expression: `has(object.spec) && has(object.spec.replicas) && ((type(object.spec.replicas) == string ? quantity(object.spec.replicas).compareTo(quantity("1")) > 0 : double(object.spec.replicas) > 1.0) || ` +
+				`(type(object.spec.replicas) == string ? quantity(object.spec.replicas).compareTo(quantity("0")) == 0 : double(object.spec.replicas) == 0.0))`,
+		},
+		{
+			// string fields holding numbers, e.g. annotations, are compared as quantities
+			pattern:    `{"metadata": {"annotations": {"max-surge": "<=2.5"}}}`,
+			expression: `has(object.metadata) && has(object.metadata.annotations) && "max-surge" in object.metadata.annotations && ` +
+				`(type(object.metadata.annotations["max-surge"]) == string ? quantity(object.metadata.annotations["max-surge"]).compareTo(quantity("2.5")) <= 0 : double(object.metadata.annotations["max-surge"]) <= 2.5)`,
+		},
+		{
+			pattern:    `{"spec": {"=(runAsUser)": 1000}}`,
+			expression: `has(object.spec) && (!has(object.spec.runAsUser) || (type(object.spec.runAsUser) == string ? quantity(object.spec.runAsUser).compareTo(quantity("1000")) == 0 : double(object.spec.runAsUser) == 1000.0))`,
+		},
+		{
+			pattern:    `{"spec": {"containers": [{"resources": {"limits": {"memory": "<=2Gi"}}}]}}`,
+			expression: `has(object.spec) && has(object.spec.containers) && object.spec.containers.all(e0, has(e0.resources) && has(e0.resources.limits) && has(e0.resources.limits.memory) && quantity(string(e0.resources.limits.memory)).compareTo(quantity("2Gi")) <= 0)`,
+		},
+		{
+			pattern:    `{"metadata": {"namespace": "!default"}}`,
+			expression: `has(object.metadata) && has(object.metadata.__namespace__) && !(object.metadata.__namespace__ == "default")`,
+		},
+		{
+			pattern:    `{"metadata": {"name": "*"}, "spec": {"type": "ClusterIP"}}`,
+			expression: `has(object.metadata) && has(object.metadata.name) && has(object.spec) && has(object.spec.type) && object.spec.type == "ClusterIP"`,
+		},
+	}
+	for _, tc := range testCases {
+		var pattern interface{}
+		assert.NilError(t, json.Unmarshal([]byte(tc.pattern), &pattern))
+		builder := expressionBuilder{}
+		expression, err := builder.patternExpression("object", pattern)
+		assert.NilError(t, err)
+		assert.Equal(t, expression, tc.expression)
+	}
+
+	unsupported := []string{
+		`{"metadata": {"(name)": "test"}}`,
+		`{"metadata": {"name": "{{request.userInfo.username}}"}}`,
+		`{"spec": {"containers": [{"name": "a"}, {"name": "b"}]}}`,
+		`{"spec": {"type": ">ClusterIP"}}`,
+	}
+	for _, p := range unsupported {
+		var pattern interface{}
+		assert.NilError(t, json.Unmarshal([]byte(p), &pattern))
+		builder := expressionBuilder{}
+		_, err := builder.patternExpression("object", pattern)
+		assert.Assert(t, err != nil, p)
+	}
+}
+
+func Test_Generate(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {
+			"name": "disallow-latest-tag"
+		},
+		"spec": {
+			"validationFailureAction": "enforce",
+			"rules": [
+				{
+					"name": "validate-image-tag",
+					"match": {
+						"resources": {
+							"kinds": ["Pod", "Deployment"]
+						}
+					},
+					"exclude": {
+						"resources": {
+							"namespaces": ["kube-system"]
+						}
+					},
+					"validate": {
+						"message": "Using a mutable image tag e.g. 'latest' is not allowed",
+						"anyPattern": [
+							{"spec": {"containers": [{"image": "!*:latest"}]}},
+							{"spec": {"template": {"spec": {"containers": [{"image": "!*:latest"}]}}}}
+						]
+					}
+				},
+				{
+					"name": "validate-owner",
+					"match": {
+						"resources": {
+							"kinds": ["Pod"]
+						},
+						"clusterRoles": ["cluster-admin"]
+					},
+					"validate": {
+						"pattern": {"metadata": {"labels": {"owner": "?*"}}}
+					}
+				}
+			]
+		}
+	}`)
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+
+	policies, bindings, skipped := Generate(policy)
+	assert.Equal(t, len(policies), 1)
+	assert.Equal(t, len(bindings), 1)
+	assert.Equal(t, len(skipped), 1)
+	assert.Equal(t, skipped[0].Rule, "validate-owner")
+
+	admissionPolicy := policies[0]
+	assert.Equal(t, admissionPolicy.Name, "disallow-latest-tag-validate-image-tag")
+	assert.DeepEqual(t, admissionPolicy.Spec.MatchConstraints.ResourceRules, []NamedRuleWithOperations{
+		{Operations: []string{"CREATE", "UPDATE"}, APIGroups: []string{""}, APIVersions: []string{"*"}, Resources: []string{"pods"}},
+		{Operations: []string{"CREATE", "UPDATE"}, APIGroups: []string{"apps"}, APIVersions: []string{"*"}, Resources: []string{"deployments"}},
+	})
+	assert.DeepEqual(t, admissionPolicy.Spec.MatchConstraints.NamespaceSelector, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: namespaceNameLabel, Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kube-system"}},
+		},
+	})
+	assert.Equal(t, admissionPolicy.Spec.Validations[0].Expression,
+		`(has(object.spec) && has(object.spec.containers) && object.spec.containers.all(e0, has(e0.image) && !(e0.image.matches("^.*:latest$")))) || `+
+			`(has(object.spec) && has(object.spec.template) && has(object.spec.template.spec) && has(object.spec.template.spec.containers) && `+
+			`object.spec.template.spec.containers.all(e0, has(e0.image) && !(e0.image.matches("^.*:latest$"))))`)
+	assert.Equal(t, admissionPolicy.Spec.Validations[0].Message, "Using a mutable image tag e.g. 'latest' is not allowed")
+
+	assert.Equal(t, bindings[0].Spec.PolicyName, admissionPolicy.Name)
+	assert.DeepEqual(t, bindings[0].Spec.ValidationActions, []string{"Deny"})
+}
+
+func Test_ResourceForKind(t *testing.T) {
+	testCases := []struct {
+		kind, group, resource string
+	}{
+		{"Pod", "", "pods"},
+		{"NetworkPolicy", "networking.k8s.io", "networkpolicies"},
+		{"ClusterRole", "rbac.authorization.k8s.io", "clusterroles"},
+		{"CronJob", "batch", "cronjobs"},
+		{"StorageClass", "storage.k8s.io", "storageclasses"},
+		{"Endpoints", "", "endpoints"},
+		{"ClusterPolicy", "*", "clusterpolicies"},
+	}
+	for _, tc := range testCases {
+		group, resource := resourceForKind(tc.kind)
+		assert.Equal(t, resource, tc.resource, tc.kind)
+		assert.Equal(t, group, tc.group, tc.kind)
+	}
+}
@@ -0,0 +1,68 @@
+package admissionpolicy
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types below mirror the admissionregistration.k8s.io/v1 ValidatingAdmissionPolicy API,
+// which is not available in the vendored client-go version. Only the fields set by the
+// generator are declared
+
+// APIVersion is the API version of the generated resources
+const APIVersion = "admissionregistration.k8s.io/v1"
+
+// ValidatingAdmissionPolicy describes CEL validations evaluated by the API server
+type ValidatingAdmissionPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ValidatingAdmissionPolicySpec `json:"spec"`
+}
+
+// ValidatingAdmissionPolicySpec is the specification of a ValidatingAdmissionPolicy
+type ValidatingAdmissionPolicySpec struct {
+	FailurePolicy    string           `json:"failurePolicy,omitempty"`
+	MatchConstraints MatchResources   `json:"matchConstraints"`
+	MatchConditions  []MatchCondition `json:"matchConditions,omitempty"`
+	Validations      []Validation     `json:"validations"`
+}
+
+// MatchResources selects the requests the policy is evaluated on
+type MatchResources struct {
+	NamespaceSelector *metav1.LabelSelector     `json:"namespaceSelector,omitempty"`
+	ObjectSelector    *metav1.LabelSelector     `json:"objectSelector,omitempty"`
+	ResourceRules     []NamedRuleWithOperations `json:"resourceRules"`
+}
+
+// NamedRuleWithOperations selects the operations on resources
+type NamedRuleWithOperations struct {
+	ResourceNames []string `json:"resourceNames,omitempty"`
+	Operations    []string `json:"operations"`
+	APIGroups     []string `json:"apiGroups"`
+	APIVersions   []string `json:"apiVersions"`
+	Resources     []string `json:"resources"`
+}
+
+// MatchCondition is a CEL expression the request must satisfy to be validated
+type MatchCondition struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// Validation is a CEL expression the request must satisfy to be admitted
+type Validation struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+}
+
+// ValidatingAdmissionPolicyBinding enables a ValidatingAdmissionPolicy
+type ValidatingAdmissionPolicyBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ValidatingAdmissionPolicyBindingSpec `json:"spec"`
+}
+
+// ValidatingAdmissionPolicyBindingSpec is the specification of a ValidatingAdmissionPolicyBinding
+type ValidatingAdmissionPolicyBindingSpec struct {
+	PolicyName        string   `json:"policyName"`
+	ValidationActions []string `json:"validationActions"`
+}
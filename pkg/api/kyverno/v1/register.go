@@ -40,6 +40,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PolicyViolationList{},
 		&GenerateRequest{},
 		&GenerateRequestList{},
+		&UpdateRequest{},
+		&UpdateRequestList{},
+		&CleanupPolicy{},
+		&CleanupPolicyList{},
+		&PolicyException{},
+		&PolicyExceptionList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
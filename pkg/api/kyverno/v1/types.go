@@ -10,6 +10,7 @@ import (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 //GenerateRequest is a request to process generate rule
+// Deprecated: generate rules are processed through UpdateRequests
 type GenerateRequest struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -69,6 +70,140 @@ type GenerateRequestList struct {
 	Items           []GenerateRequest `json:"items"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// UpdateRequest is a request to asynchronously apply the generate rules, or the mutate rules
+// with targets, of a policy for a trigger resource
+type UpdateRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              UpdateRequestSpec   `json:"spec"`
+	Status            UpdateRequestStatus `json:"status"`
+}
+
+// RequestType is the type of the rules processed by an UpdateRequest
+type RequestType string
+
+const (
+	// Generate requests create the resources of the generate rules
+	Generate RequestType = "generate"
+	// Mutate requests mutate the existing target resources of the mutate rules
+	Mutate RequestType = "mutate"
+)
+
+// UpdateRequestSpec stores the request specification
+type UpdateRequestSpec struct {
+	Type     RequestType          `json:"requestType"`
+	Policy   string               `json:"policy"`
+	Resource ResourceSpec         `json:"resource"`
+	Context  UpdateRequestContext `json:"context"`
+}
+
+// UpdateRequestContext stores the context to be shared
+type UpdateRequestContext struct {
+	UserRequestInfo RequestInfo `json:"userInfo,omitempty"`
+}
+
+// UpdateRequestStatus stores the status of an update request, the states are shared with generate requests
+type UpdateRequestStatus struct {
+	State   GenerateRequestState `json:"state"`
+	Message string               `json:"message,omitempty"`
+	// GeneratedResources are the resources created by generate requests, they are
+	// deleted with the request once the trigger resource is deleted
+	GeneratedResources []ResourceSpec `json:"generatedResources,omitempty"`
+	// MutatedResources are the target resources updated by mutate requests
+	MutatedResources []ResourceSpec `json:"mutatedResources,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// UpdateRequestList stores the list of update requests
+type UpdateRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []UpdateRequest `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CleanupPolicy deletes the resources matching its description on a schedule, or once they are older than a TTL
+type CleanupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CleanupPolicySpec   `json:"spec"`
+	Status            CleanupPolicyStatus `json:"status,omitempty"`
+}
+
+// CleanupPolicySpec describes the resources to delete and when to delete them
+type CleanupPolicySpec struct {
+	MatchResources   MatchResources   `json:"match"`
+	ExcludeResources ExcludeResources `json:"exclude,omitempty"`
+	// Conditions are evaluated on each matched resource, available as request.object
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Schedule is a cron expression (minute hour day-of-month month day-of-week) at which the policy is executed,
+	// the policy is executed every few minutes if not set
+	Schedule string `json:"schedule,omitempty"`
+	// TTL is the minimum age of the resources to delete, e.g. 168h
+	TTL string `json:"ttl,omitempty"`
+	// AllowClusterScopedKinds allows to match cluster-wide kinds such as namespaces
+	AllowClusterScopedKinds bool `json:"allowClusterScopedKinds,omitempty"`
+}
+
+// CleanupPolicyStatus stores the result of the last execution of the policy
+type CleanupPolicyStatus struct {
+	LastExecutionTime metav1.Time `json:"lastExecutionTime,omitempty"`
+	// number of resources deleted in the last execution
+	DeletedCount int `json:"deletedCount,omitempty"`
+	// number of resources that failed to be deleted in the last execution
+	FailedCount int    `json:"failedCount,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CleanupPolicyList stores the list of cleanup policies
+type CleanupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CleanupPolicy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PolicyException exempts the resources it matches from the listed policy rules
+type PolicyException struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              PolicyExceptionSpec `json:"spec"`
+}
+
+// PolicyExceptionSpec describes the exempted rules and resources
+type PolicyExceptionSpec struct {
+	Exceptions []Exception `json:"exceptions"`
+	// MatchResources selects the exempted resources, the resources must be in the namespace
+	// of the exception, unless the exception is in the Kyverno namespace
+	MatchResources MatchResources `json:"match"`
+}
+
+// Exception lists the exempted rules of a policy, wildcards are supported in the rule names
+type Exception struct {
+	PolicyName string   `json:"policyName"`
+	RuleNames  []string `json:"ruleNames"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PolicyExceptionList stores the list of policy exceptions
+type PolicyExceptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []PolicyException `json:"items"`
+}
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -126,21 +261,34 @@ type Policy struct {
 
 // Spec describes policy behavior by its rules
 type Spec struct {
-	Rules                   []Rule `json:"rules"`
-	ValidationFailureAction string `json:"validationFailureAction"`
-	Background              *bool  `json:"background"`
+	Rules                   []Rule           `json:"rules"`
+	ValidationFailureAction string           `json:"validationFailureAction"`
+	Background              *bool            `json:"background"`
+	RolloutStrategy         *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+// RolloutStrategy audits an enforce policy during an observation window,
+// the policy is promoted to enforce if few rule applications failed
+type RolloutStrategy struct {
+	// ObservationWindow is the duration the policy is audited, e.g. 168h
+	ObservationWindow string `json:"observationWindow"`
+	// MaxFailurePercentage is the maximum percentage of failed rule applications
+	// during the observation window for the policy to be promoted
+	MaxFailurePercentage int `json:"maxFailurePercentage"`
 }
 
 // Rule is set of mutation, validation and generation actions
 // for the single resource description
 type Rule struct {
-	Name             string           `json:"name"`
-	MatchResources   MatchResources   `json:"match"`
-	ExcludeResources ExcludeResources `json:"exclude,omitempty"`
-	Conditions       []Condition      `json:"preconditions,omitempty"`
-	Mutation         Mutation         `json:"mutate,omitempty"`
-	Validation       Validation       `json:"validate,omitempty"`
-	Generation       Generation       `json:"generate,omitempty"`
+	Name             string               `json:"name"`
+	MatchResources   MatchResources       `json:"match"`
+	ExcludeResources ExcludeResources     `json:"exclude,omitempty"`
+	Conditions       []Condition          `json:"preconditions,omitempty"`
+	Mutation         Mutation             `json:"mutate,omitempty"`
+	Validation       Validation           `json:"validate,omitempty"`
+	Generation       Generation           `json:"generate,omitempty"`
+	VerifyImages     []ImageVerification  `json:"verifyImages,omitempty"`
+	VerifyManifests  ManifestVerification `json:"verifyManifests,omitempty"`
 }
 
 //Condition defines the evaluation condition
@@ -195,6 +343,9 @@ type ResourceDescription struct {
 type Mutation struct {
 	Overlay interface{} `json:"overlay,omitempty"`
 	Patches []Patch     `json:"patches,omitempty"`
+	// Targets are existing resources mutated in the background when the matched
+	// resource is admitted, instead of the matched resource itself
+	Targets []ResourceSpec `json:"targets,omitempty"`
 }
 
 // +k8s:deepcopy-gen=false
@@ -208,16 +359,84 @@ type Patch struct {
 
 // Validation describes the way how Validating Webhook will check the resource on creation
 type Validation struct {
-	Message    string        `json:"message,omitempty"`
-	Pattern    interface{}   `json:"pattern,omitempty"`
-	AnyPattern []interface{} `json:"anyPattern,omitempty"`
+	Message    string         `json:"message,omitempty"`
+	Pattern    interface{}    `json:"pattern,omitempty"`
+	AnyPattern []interface{}  `json:"anyPattern,omitempty"`
+	Count      *ResourceCount `json:"count,omitempty"`
+}
+
+// ResourceCount limits the number of resources of the kind of the admitted resource,
+// the creation of a resource beyond the limit is denied
+type ResourceCount struct {
+	// Limit is the maximum number of resources
+	Limit int `json:"limit"`
+	// Scope is Namespace to count the resources in the namespace of the admitted resource,
+	// or Cluster to count the resources of all namespaces. Defaults to Namespace
+	Scope CountScope `json:"scope,omitempty"`
+	// Pattern selects the counted resources, e.g. only the services of type LoadBalancer,
+	// the admitted resource is only limited if it matches the pattern
+	Pattern interface{} `json:"pattern,omitempty"`
 }
 
+// CountScope is the scope in which resources are counted
+type CountScope string
+
+const (
+	// NamespaceScope counts the resources in the namespace of the admitted resource
+	NamespaceScope CountScope = "Namespace"
+	// ClusterScope counts the resources of all namespaces
+	ClusterScope CountScope = "Cluster"
+)
+
 // Generation describes which resources will be created when other resource is created
 type Generation struct {
 	ResourceSpec
 	Data  interface{} `json:"data,omitempty"`
 	Clone CloneFrom   `json:"clone,omitempty"`
+	// ServiceAccount is impersonated to create the resource, so that the resource is authorized
+	// against the permissions of the service account instead of the permissions of kyverno
+	ServiceAccount ServiceAccountReference `json:"serviceAccount,omitempty"`
+}
+
+// ServiceAccountReference identifies a service account
+type ServiceAccountReference struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// ImageVerification checks the cosign signatures of the container images matching the pattern,
+// the image tags are replaced with the verified digests. The images are signed with a key,
+// or keyless with a certificate issued to the subject
+type ImageVerification struct {
+	// Image is the image name pattern, wildcards '*' and '?' are supported
+	Image string `json:"image"`
+	// Key is the PEM encoded public key the images are signed with
+	Key string `json:"key,omitempty"`
+	// Roots are the PEM encoded root certificates of keyless signatures, e.g. the Fulcio root certificate
+	Roots string `json:"roots,omitempty"`
+	// Subject is the email or URI of the keyless signer in the certificate, wildcards '*' and '?' are supported
+	Subject string `json:"subject,omitempty"`
+	// Issuer is the OIDC issuer that authenticated the keyless signer
+	Issuer string `json:"issuer,omitempty"`
+	// RekorKey is the PEM encoded public key of the Rekor transparency log, the signatures must carry
+	// an entry of the log signed with this key. Required for keyless signatures
+	RekorKey string `json:"rekorKey,omitempty"`
+	// Attestations are the predicate types of the in-toto attestations the images must carry,
+	// signed with the key or by the keyless signer, e.g. https://slsa.dev/provenance/v0.2
+	Attestations []string `json:"attestations,omitempty"`
+}
+
+// ManifestVerification checks that the admitted resource carries a valid signature of its manifest,
+// stored in its annotations, from one of the allowed signers
+type ManifestVerification struct {
+	// Keys are the public keys of the allowed signers, PEM encoded ECDSA keys for sigstore
+	// signatures or armored GPG public keys
+	Keys []string `json:"keys"`
+	// AnnotationDomain is the domain of the signature annotations, defaults to cosign.sigstore.dev
+	AnnotationDomain string `json:"annotationDomain,omitempty"`
+	// IgnoreFields are the paths of the fields that may differ from the signed manifest,
+	// e.g. spec.replicas, the indices of list elements are omitted
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
 }
 
 // CloneFrom - location of the resource
@@ -243,10 +462,39 @@ type PolicyStatus struct {
 	ResourcesMutatedCount int `json:"resourcesMutatedCount,omitempty"`
 	// Count of resources that were successfully generated, across all rules
 	ResourcesGeneratedCount int `json:"resourcesGeneratedCount,omitempty"`
+	// Progress of the rollout strategy
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
 
 	Rules []RuleStats `json:"ruleStatus,omitempty"`
 }
 
+// RolloutStatus records the observation window and the promotion decision of a rollout strategy
+type RolloutStatus struct {
+	Phase RolloutPhase `json:"phase"`
+	// Generation of the policy that is rolled out, the rollout restarts when the policy changes
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Start of the observation window
+	StartTime metav1.Time `json:"startTime"`
+	// Applied and failed counts of the validation rules at the start of the observation window
+	StartRulesAppliedCount int `json:"startRulesAppliedCount"`
+	StartRulesFailedCount  int `json:"startRulesFailedCount"`
+	// Time of the promotion decision
+	DecisionTime *metav1.Time `json:"decisionTime,omitempty"`
+	Message      string       `json:"message,omitempty"`
+}
+
+// RolloutPhase is the phase of a rollout
+type RolloutPhase string
+
+const (
+	// RolloutObserving means the policy is audited during the observation window
+	RolloutObserving RolloutPhase = "Observing"
+	// RolloutEnforced means the policy was promoted to enforce
+	RolloutEnforced RolloutPhase = "Enforced"
+	// RolloutRejected means the policy failed too often and stays in audit
+	RolloutRejected RolloutPhase = "Rejected"
+)
+
 //RuleStats provides status per rule
 type RuleStats struct {
 	// Rule name
@@ -5,18 +5,36 @@ import "reflect"
 //HasMutateOrValidateOrGenerate checks for rule types
 func (p ClusterPolicy) HasMutateOrValidateOrGenerate() bool {
 	for _, rule := range p.Spec.Rules {
-		if rule.HasMutate() || rule.HasValidate() || rule.HasGenerate() {
+		if rule.HasMutate() || rule.HasValidate() || rule.HasGenerate() || rule.HasVerifyImages() || rule.HasVerifyManifests() {
 			return true
 		}
 	}
 	return false
 }
 
+//GetValidationFailureAction returns the action applied on validation failures,
+//policies with a rollout strategy are audited until their current generation is promoted to enforce
+func (p ClusterPolicy) GetValidationFailureAction() string {
+	if p.Spec.RolloutStrategy == nil || p.Spec.ValidationFailureAction != "enforce" {
+		return p.Spec.ValidationFailureAction
+	}
+	rollout := p.Status.Rollout
+	if rollout == nil || rollout.Phase != RolloutEnforced || rollout.ObservedGeneration != p.Generation {
+		return "audit"
+	}
+	return p.Spec.ValidationFailureAction
+}
+
 //HasMutate checks for mutate rule
 func (r Rule) HasMutate() bool {
 	return !reflect.DeepEqual(r.Mutation, Mutation{})
 }
 
+//HasMutateExisting checks for mutate rule with targets
+func (r Rule) HasMutateExisting() bool {
+	return len(r.Mutation.Targets) != 0
+}
+
 //HasValidate checks for validate rule
 func (r Rule) HasValidate() bool {
 	return !reflect.DeepEqual(r.Validation, Validation{})
@@ -27,6 +45,16 @@ func (r Rule) HasGenerate() bool {
 	return !reflect.DeepEqual(r.Generation, Generation{})
 }
 
+//HasVerifyImages checks for verifyImages rule
+func (r Rule) HasVerifyImages() bool {
+	return len(r.VerifyImages) != 0
+}
+
+//HasVerifyManifests checks for verifyManifests rule
+func (r Rule) HasVerifyManifests() bool {
+	return !reflect.DeepEqual(r.VerifyManifests, ManifestVerification{})
+}
+
 // DeepCopyInto is declared because k8s:deepcopy-gen is
 // not able to generate this method for interface{} member
 func (in *Mutation) DeepCopyInto(out *Mutation) {
@@ -51,6 +79,14 @@ func (in *Validation) DeepCopyInto(out *Validation) {
 	}
 }
 
+// DeepCopyInto is declared because k8s:deepcopy-gen is
+// not able to generate this method for interface{} member
+func (in *ResourceCount) DeepCopyInto(out *ResourceCount) {
+	if out != nil {
+		*out = *in
+	}
+}
+
 // DeepCopyInto is declared because k8s:deepcopy-gen is
 // not able to generate this method for interface{} member
 func (gen *Generation) DeepCopyInto(out *Generation) {
@@ -1,6 +1,7 @@
 package checker
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -53,7 +54,7 @@ func (vc StatusControl) setStatus(status string) error {
 	glog.Infof("setting deployment %s in ns %s annotation %s to %s", deployName, deployNamespace, annWebhookStats, status)
 	var ann map[string]string
 	var err error
-	deploy, err := vc.client.GetResource("Deployment", deployNamespace, deployName)
+	deploy, err := vc.client.GetResource(context.TODO(), "Deployment", deployNamespace, deployName)
 	if err != nil {
 		glog.V(4).Infof("failed to get deployment %s in namespace %s: %v", deployName, deployNamespace, err)
 		return err
@@ -75,7 +76,7 @@ func (vc StatusControl) setStatus(status string) error {
 	ann[annWebhookStats] = status
 	deploy.SetAnnotations(ann)
 	// update counter
-	_, err = vc.client.UpdateResource("Deployment", deployNamespace, deploy, false)
+	_, err = vc.client.UpdateResource(context.TODO(), "Deployment", deployNamespace, deploy, false)
 	if err != nil {
 		glog.V(4).Infof("failed to update annotation %s for deployment %s in namespace %s: %v", annWebhookStats, deployName, deployNamespace, err)
 		return err
@@ -100,7 +101,7 @@ func (vc StatusControl) IncrementAnnotation() error {
 	glog.Infof("setting deployment %s in ns %s annotation %s", deployName, deployNamespace, annCounter)
 	var ann map[string]string
 	var err error
-	deploy, err := vc.client.GetResource("Deployment", deployNamespace, deployName)
+	deploy, err := vc.client.GetResource(context.TODO(), "Deployment", deployNamespace, deployName)
 	if err != nil {
 		glog.V(4).Infof("failed to get deployment %s in namespace %s: %v", deployName, deployNamespace, err)
 		return err
@@ -121,7 +122,7 @@ func (vc StatusControl) IncrementAnnotation() error {
 	glog.Infof("incrementing annotation %s counter to %d", annCounter, counter)
 	deploy.SetAnnotations(ann)
 	// update counter
-	_, err = vc.client.UpdateResource("Deployment", deployNamespace, deploy, false)
+	_, err = vc.client.UpdateResource(context.TODO(), "Deployment", deployNamespace, deploy, false)
 	if err != nil {
 		glog.V(4).Infof("failed to update annotation %s for deployment %s in namespace %s: %v", annCounter, deployName, deployNamespace, err)
 		return err
@@ -0,0 +1,170 @@
+package cleanuppolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/config"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine"
+	enginecontext "github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultInterval is the interval at which policies without schedule are executed
+const DefaultInterval = 10 * time.Minute
+
+// Validate checks the policy can be executed, the scope of the kinds is read from the discovery.
+// Cluster-wide kinds can only be matched if the policy allows it, deleting them removes
+// the resources they contain or breaks the cluster
+func Validate(policy kyverno.CleanupPolicy, discovery dclient.IDiscovery) error {
+	spec := policy.Spec
+	if len(spec.MatchResources.Kinds) == 0 {
+		return fmt.Errorf("match.resources.kinds cannot be empty")
+	}
+	// there is no admission request to match the user information against
+	if len(spec.MatchResources.Roles) != 0 || len(spec.MatchResources.ClusterRoles) != 0 || len(spec.MatchResources.Subjects) != 0 ||
+		len(spec.ExcludeResources.Roles) != 0 || len(spec.ExcludeResources.ClusterRoles) != 0 || len(spec.ExcludeResources.Subjects) != 0 {
+		return fmt.Errorf("userInfo is not allowed in match or exclude of cleanup policies")
+	}
+	if !spec.AllowClusterScopedKinds {
+		for _, kind := range spec.MatchResources.Kinds {
+			namespaced, err := discovery.IsNamespaced(kind)
+			if err != nil {
+				return fmt.Errorf("failed to discover the scope of kind %s: %v", kind, err)
+			}
+			if !namespaced {
+				return fmt.Errorf("kind %s is cluster-wide, set allowClusterScopedKinds to match it", kind)
+			}
+		}
+	}
+	// without both, every matching resource would be deleted every few minutes
+	if spec.Schedule == "" && spec.TTL == "" {
+		return fmt.Errorf("either schedule or ttl is required")
+	}
+	if spec.Schedule != "" {
+		if _, err := ParseSchedule(spec.Schedule); err != nil {
+			return err
+		}
+	}
+	if spec.TTL != "" {
+		ttl, err := time.ParseDuration(spec.TTL)
+		if err != nil {
+			return fmt.Errorf("invalid ttl %q: %v", spec.TTL, err)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("ttl must be positive, got %q", spec.TTL)
+		}
+	}
+	return nil
+}
+
+// isDue returns true if the policy has to be executed at now
+func isDue(policy kyverno.CleanupPolicy, now time.Time) bool {
+	last := policy.Status.LastExecutionTime.Time
+	if policy.Spec.Schedule == "" {
+		return last.IsZero() || now.Sub(last) >= DefaultInterval
+	}
+	schedule, err := ParseSchedule(policy.Spec.Schedule)
+	if err != nil {
+		return false
+	}
+	if last.IsZero() {
+		last = policy.CreationTimestamp.Time
+	}
+	next := schedule.Next(last)
+	return !next.IsZero() && !next.After(now)
+}
+
+// cleanup deletes the resources matching the policy, returns the number of deleted resources
+// and the number of resources that failed to be deleted. The calls are cancelled when the context is done
+func cleanup(ctx context.Context, client *dclient.Client, configHandler config.Interface, policy kyverno.CleanupPolicy, now time.Time) (int, int, error) {
+	var ttl time.Duration
+	if policy.Spec.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(policy.Spec.TTL); err != nil {
+			return 0, 0, err
+		}
+	}
+	// the match and exclude blocks are evaluated with the engine, as for policy rules
+	rule := kyverno.Rule{
+		Name:             policy.Name,
+		MatchResources:   policy.Spec.MatchResources,
+		ExcludeResources: policy.Spec.ExcludeResources,
+	}
+	namespaces := policy.Spec.MatchResources.Namespaces
+	if len(namespaces) == 0 {
+		// all namespaces, or cluster-wide resources
+		namespaces = []string{""}
+	}
+
+	var deleted, failed int
+	for _, kind := range policy.Spec.MatchResources.Kinds {
+		for _, namespace := range namespaces {
+			options := dclient.ListOptions{LabelSelector: policy.Spec.MatchResources.Selector}
+			err := client.ListResourceInPages(ctx, kind, namespace, options, func(list *unstructured.UnstructuredList) error {
+				for _, resource := range list.Items {
+					if !matches(configHandler, policy, rule, resource, ttl, now) {
+						continue
+					}
+					err := client.DeleteResource(ctx, resource.GetKind(), resource.GetNamespace(), resource.GetName(), false)
+					if err != nil {
+						if _, ok := err.(*dclient.NotFound); ok {
+							continue
+						}
+						glog.Errorf("cleanup policy %s: failed to delete %s/%s/%s: %v", policy.Name, resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+						failed++
+						continue
+					}
+					glog.V(2).Infof("cleanup policy %s: deleted %s/%s/%s", policy.Name, resource.GetKind(), resource.GetNamespace(), resource.GetName())
+					deleted++
+				}
+				return nil
+			})
+			if err != nil {
+				return deleted, failed, fmt.Errorf("failed to list %s in namespace %q: %v", kind, namespace, err)
+			}
+		}
+	}
+	return deleted, failed, nil
+}
+
+// matches returns true if the resource is to be deleted by the policy
+func matches(configHandler config.Interface, policy kyverno.CleanupPolicy, rule kyverno.Rule, resource unstructured.Unstructured, ttl time.Duration, now time.Time) bool {
+	if resource.GetDeletionTimestamp() != nil {
+		return false
+	}
+	if configHandler != nil && configHandler.ToFilter(resource.GetKind(), resource.GetNamespace(), resource.GetName()) {
+		return false
+	}
+	if ttl != 0 && now.Sub(resource.GetCreationTimestamp().Time) < ttl {
+		return false
+	}
+	if err := engine.MatchesResourceDescription(resource, rule, kyverno.RequestInfo{}); err != nil {
+		glog.V(4).Infof("cleanup policy %s: resource %s/%s/%s does not match: %v", policy.Name, resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+		return false
+	}
+	if len(policy.Spec.Conditions) == 0 {
+		return true
+	}
+	raw, err := resource.MarshalJSON()
+	if err != nil {
+		glog.Errorf("cleanup policy %s: failed to marshal resource %s/%s/%s: %v", policy.Name, resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+		return false
+	}
+	ctx := enginecontext.NewContext()
+	if err := ctx.AddResource(raw); err != nil {
+		glog.Errorf("cleanup policy %s: failed to load resource in context: %v", policy.Name, err)
+		return false
+	}
+	// operate on a copy of the conditions, as the variables are substituted
+	conditions := make([]kyverno.Condition, len(policy.Spec.Conditions))
+	for i := range policy.Spec.Conditions {
+		policy.Spec.Conditions[i].DeepCopyInto(&conditions[i])
+	}
+	return variables.EvaluateConditions(ctx, conditions)
+}
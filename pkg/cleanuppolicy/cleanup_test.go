@@ -0,0 +1,149 @@
+package cleanuppolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newJob(namespace, name string, created time.Time, succeeded int64) *unstructured.Unstructured {
+	job := &unstructured.Unstructured{}
+	job.SetAPIVersion("batch/v1")
+	job.SetKind("Job")
+	job.SetNamespace(namespace)
+	job.SetName(name)
+	job.SetCreationTimestamp(metav1.NewTime(created))
+	unstructured.SetNestedField(job.Object, succeeded, "status", "succeeded")
+	return job
+}
+
+func TestValidate(t *testing.T) {
+	discovery := dclient.NewFakeDiscoveryClient([]schema.GroupVersionResource{
+		{Group: "batch", Version: "v1", Resource: "jobs"},
+	})
+	valid := kyverno.CleanupPolicy{Spec: kyverno.CleanupPolicySpec{
+		MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Job"}}},
+		Schedule:       "@daily",
+		TTL:            "168h",
+	}}
+	if err := Validate(valid, discovery); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	noKinds := *valid.DeepCopy()
+	noKinds.Spec.MatchResources.Kinds = nil
+	badSchedule := *valid.DeepCopy()
+	badSchedule.Spec.Schedule = "every day"
+	badTTL := *valid.DeepCopy()
+	badTTL.Spec.TTL = "7d"
+	userInfo := *valid.DeepCopy()
+	userInfo.Spec.MatchResources.Roles = []string{"admin"}
+	noScheduleNorTTL := *valid.DeepCopy()
+	noScheduleNorTTL.Spec.Schedule = ""
+	noScheduleNorTTL.Spec.TTL = ""
+	namespaces := *valid.DeepCopy()
+	namespaces.Spec.MatchResources.Kinds = []string{"Job", "Namespace"}
+	unknownKind := *valid.DeepCopy()
+	unknownKind.Spec.MatchResources.Kinds = []string{"Unknown"}
+	for _, policy := range []kyverno.CleanupPolicy{noKinds, badSchedule, badTTL, userInfo, noScheduleNorTTL, namespaces, unknownKind} {
+		if err := Validate(policy, discovery); err == nil {
+			t.Errorf("expected policy %+v to be invalid", policy.Spec)
+		}
+	}
+
+	onlyTTL := *valid.DeepCopy()
+	onlyTTL.Spec.Schedule = ""
+	namespaces.Spec.AllowClusterScopedKinds = true
+	for _, policy := range []kyverno.CleanupPolicy{onlyTTL, namespaces} {
+		if err := Validate(policy, discovery); err != nil {
+			t.Errorf("expected policy %+v to be valid: %v", policy.Spec, err)
+		}
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	now := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+	policy := kyverno.CleanupPolicy{}
+	policy.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+
+	// without schedule, executed right away and then every DefaultInterval
+	if !isDue(policy, now) {
+		t.Errorf("expected the policy without schedule to be due")
+	}
+	policy.Status.LastExecutionTime = metav1.NewTime(now.Add(-time.Minute))
+	if isDue(policy, now) {
+		t.Errorf("expected the policy executed a minute ago not to be due")
+	}
+
+	policy.Spec.Schedule = "0 * * * *"
+	policy.Status.LastExecutionTime = metav1.NewTime(now.Add(-30 * time.Minute))
+	if !isDue(policy, now) {
+		t.Errorf("expected the hourly policy to be due")
+	}
+	policy.Status.LastExecutionTime = metav1.NewTime(now)
+	if isDue(policy, now.Add(59*time.Minute)) {
+		t.Errorf("expected the hourly policy not to be due before the next hour")
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	now := time.Now()
+	week := 7 * 24 * time.Hour
+	objects := []runtime.Object{
+		newJob("default", "old-completed", now.Add(-2*week), 1),
+		newJob("default", "old-running", now.Add(-2*week), 0),
+		newJob("default", "new-completed", now.Add(-time.Hour), 1),
+		newJob("prod", "old-completed", now.Add(-2*week), 1),
+	}
+	client, err := dclient.NewMockClient(runtime.NewScheme(), objects...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetDiscovery(dclient.NewFakeDiscoveryClient([]schema.GroupVersionResource{
+		{Group: "batch", Version: "v1", Resource: "jobs"},
+	}))
+
+	policy := kyverno.CleanupPolicy{Spec: kyverno.CleanupPolicySpec{
+		MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Job"}}},
+		ExcludeResources: kyverno.ExcludeResources{ResourceDescription: kyverno.ResourceDescription{
+			Namespaces: []string{"prod"},
+		}},
+		Conditions: []kyverno.Condition{{
+			Key:      "{{request.object.status.succeeded}}",
+			Operator: kyverno.Equal,
+			Value:    1,
+		}},
+		TTL: "168h",
+	}}
+	policy.Name = "completed-jobs"
+
+	// the calls are cancelled with the context
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, _, err := cleanup(ctx, client, nil, policy, now); err == nil {
+		t.Errorf("expected the cleanup with a cancelled context to fail")
+	}
+
+	deleted, failed, err := cleanup(context.TODO(), client, nil, policy, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 || failed != 0 {
+		t.Errorf("expected 1 deleted and 0 failed resources, got %d and %d", deleted, failed)
+	}
+	if _, err := client.GetResource(context.TODO(), "Job", "default", "old-completed"); err == nil {
+		t.Errorf("expected default/old-completed to be deleted")
+	}
+	for _, job := range [][]string{{"default", "old-running"}, {"default", "new-completed"}, {"prod", "old-completed"}} {
+		if _, err := client.GetResource(context.TODO(), "Job", job[0], job[1]); err != nil {
+			t.Errorf("expected %s/%s not to be deleted: %v", job[0], job[1], err)
+		}
+	}
+}
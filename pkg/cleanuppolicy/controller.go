@@ -0,0 +1,139 @@
+package cleanuppolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernoclient "github.com/nirmata/kyverno/pkg/client/clientset/versioned"
+	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
+	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/config"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+// checkInterval is the interval at which the policies are checked for execution,
+// the schedules have a precision of a minute
+const checkInterval = time.Minute
+
+//Controller executes the cleanup policies when they are due
+type Controller struct {
+	// dynamic client to list and delete the resources
+	client *dclient.Client
+	// typed client for kyverno CRDs
+	kyvernoClient *kyvernoclient.Clientset
+	// cpLister can list/get cleanup policies from the shared informer's store
+	cpLister kyvernolister.CleanupPolicyLister
+	// cpSynced returns true if the cleanup policy store has been synced at least once
+	cpSynced cache.InformerSynced
+	// resources filtered by the configuration are never deleted
+	configHandler config.Interface
+}
+
+//NewController returns a new controller instance to execute cleanup policies
+func NewController(
+	kyvernoClient *kyvernoclient.Clientset,
+	client *dclient.Client,
+	cpInformer kyvernoinformer.CleanupPolicyInformer,
+	configHandler config.Interface,
+) *Controller {
+	return &Controller{
+		client:        client,
+		kyvernoClient: kyvernoClient,
+		cpLister:      cpInformer.Lister(),
+		cpSynced:      cpInformer.Informer().HasSynced,
+		configHandler: configHandler,
+	}
+}
+
+//Run checks the cleanup policies every minute until stopCh is closed
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	glog.Info("Starting cleanup-policy controller")
+	defer glog.Info("Shutting down cleanup-policy controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cpSynced) {
+		glog.Error("cleanup-policy controller: failed to sync informer cache")
+		return
+	}
+	// the cleanup in flight is cancelled when the controller is stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	wait.Until(func() { c.sync(ctx) }, checkInterval, stopCh)
+}
+
+// sync executes the policies that are due
+func (c *Controller) sync(ctx context.Context) {
+	policies, err := c.cpLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("failed to list cleanup policies: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, policy := range policies {
+		if err := Validate(*policy, c.client.DiscoveryClient); err != nil {
+			c.setInvalid(policy, err)
+			continue
+		}
+		if !isDue(*policy, now) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		c.execute(ctx, policy, now)
+	}
+}
+
+func (c *Controller) execute(ctx context.Context, policy *kyverno.CleanupPolicy, now time.Time) {
+	glog.V(4).Infof("executing cleanup policy %s", policy.Name)
+	deleted, failed, err := cleanup(ctx, c.client, c.configHandler, *policy, now)
+	if ctx.Err() != nil {
+		// the controller is stopped, the policy is executed again on the next start
+		glog.V(4).Infof("cleanup policy %s interrupted: %v", policy.Name, ctx.Err())
+		return
+	}
+	status := kyverno.CleanupPolicyStatus{
+		LastExecutionTime: metav1.NewTime(now),
+		DeletedCount:      deleted,
+		FailedCount:       failed,
+	}
+	if err != nil {
+		glog.Errorf("failed to execute cleanup policy %s: %v", policy.Name, err)
+		status.Message = err.Error()
+	}
+	c.updateStatus(policy, status)
+}
+
+// setInvalid reports the validation error in the status, the policy is not executed
+func (c *Controller) setInvalid(policy *kyverno.CleanupPolicy, err error) {
+	message := fmt.Sprintf("invalid policy: %v", err)
+	if policy.Status.Message == message {
+		return
+	}
+	glog.Errorf("cleanup policy %s: %s", policy.Name, message)
+	status := *policy.Status.DeepCopy()
+	status.Message = message
+	c.updateStatus(policy, status)
+}
+
+func (c *Controller) updateStatus(policy *kyverno.CleanupPolicy, status kyverno.CleanupPolicyStatus) {
+	// the lister cache must not be mutated
+	newPolicy := policy.DeepCopy()
+	newPolicy.Status = status
+	if _, err := c.kyvernoClient.KyvernoV1().CleanupPolicies().UpdateStatus(newPolicy); err != nil {
+		glog.Errorf("failed to update the status of cleanup policy %s: %v", policy.Name, err)
+	}
+}
@@ -0,0 +1,50 @@
+package cleanuppolicy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	valid := []string{"* * * * *", "*/15 8-18 * * 1-5", "0 0 1,15 * *", "5/10 * * * 7", "@daily", "@hourly"}
+	for _, spec := range valid {
+		if _, err := ParseSchedule(spec); err != nil {
+			t.Errorf("failed to parse %q: %v", spec, err)
+		}
+	}
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"}
+	for _, spec := range invalid {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2020, time.March, 31, 10, 7, 30, 0, time.UTC) // Tuesday
+	testcases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2020, time.March, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, time.March, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2020, time.March, 31, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 0", time.Date(2020, time.April, 5, 2, 30, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{"0 0 15 * 5", time.Date(2020, time.April, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tc := range testcases {
+		schedule, err := ParseSchedule(tc.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next := schedule.Next(from); !next.Equal(tc.expected) {
+			t.Errorf("schedule %q: expected %v, got %v", tc.spec, tc.expected, next)
+		}
+	}
+}
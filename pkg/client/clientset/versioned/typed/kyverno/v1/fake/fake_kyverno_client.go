@@ -28,6 +28,10 @@ type FakeKyvernoV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeKyvernoV1) CleanupPolicies() v1.CleanupPolicyInterface {
+	return &FakeCleanupPolicies{c}
+}
+
 func (c *FakeKyvernoV1) ClusterPolicies() v1.ClusterPolicyInterface {
 	return &FakeClusterPolicies{c}
 }
@@ -40,10 +44,18 @@ func (c *FakeKyvernoV1) GenerateRequests(namespace string) v1.GenerateRequestInt
 	return &FakeGenerateRequests{c, namespace}
 }
 
+func (c *FakeKyvernoV1) PolicyExceptions(namespace string) v1.PolicyExceptionInterface {
+	return &FakePolicyExceptions{c, namespace}
+}
+
 func (c *FakeKyvernoV1) PolicyViolations(namespace string) v1.PolicyViolationInterface {
 	return &FakePolicyViolations{c, namespace}
 }
 
+func (c *FakeKyvernoV1) UpdateRequests(namespace string) v1.UpdateRequestInterface {
+	return &FakeUpdateRequests{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeKyvernoV1) RESTClient() rest.Interface {
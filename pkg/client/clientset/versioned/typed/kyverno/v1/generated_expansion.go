@@ -18,10 +18,16 @@ limitations under the License.
 
 package v1
 
+type CleanupPolicyExpansion interface{}
+
 type ClusterPolicyExpansion interface{}
 
 type ClusterPolicyViolationExpansion interface{}
 
 type GenerateRequestExpansion interface{}
 
+type PolicyExceptionExpansion interface{}
+
 type PolicyViolationExpansion interface{}
+
+type UpdateRequestExpansion interface{}
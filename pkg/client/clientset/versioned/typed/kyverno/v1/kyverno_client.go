@@ -27,10 +27,13 @@ import (
 
 type KyvernoV1Interface interface {
 	RESTClient() rest.Interface
+	CleanupPoliciesGetter
 	ClusterPoliciesGetter
 	ClusterPolicyViolationsGetter
 	GenerateRequestsGetter
+	PolicyExceptionsGetter
 	PolicyViolationsGetter
+	UpdateRequestsGetter
 }
 
 // KyvernoV1Client is used to interact with features provided by the kyverno.io group.
@@ -38,6 +41,10 @@ type KyvernoV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *KyvernoV1Client) CleanupPolicies() CleanupPolicyInterface {
+	return newCleanupPolicies(c)
+}
+
 func (c *KyvernoV1Client) ClusterPolicies() ClusterPolicyInterface {
 	return newClusterPolicies(c)
 }
@@ -50,10 +57,18 @@ func (c *KyvernoV1Client) GenerateRequests(namespace string) GenerateRequestInte
 	return newGenerateRequests(c, namespace)
 }
 
+func (c *KyvernoV1Client) PolicyExceptions(namespace string) PolicyExceptionInterface {
+	return newPolicyExceptions(c, namespace)
+}
+
 func (c *KyvernoV1Client) PolicyViolations(namespace string) PolicyViolationInterface {
 	return newPolicyViolations(c, namespace)
 }
 
+func (c *KyvernoV1Client) UpdateRequests(namespace string) UpdateRequestInterface {
+	return newUpdateRequests(c, namespace)
+}
+
 // NewForConfig creates a new KyvernoV1Client for the given config.
 func NewForConfig(c *rest.Config) (*KyvernoV1Client, error) {
 	config := *c
@@ -26,6 +26,10 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// CleanupPolicyListerExpansion allows custom methods to be added to
+// CleanupPolicyLister.
+type CleanupPolicyListerExpansion interface{}
+
 // ClusterPolicyListerExpansion allows custom methods to be added to
 // ClusterPolicyLister.
 type ClusterPolicyListerExpansion interface {
@@ -41,6 +45,14 @@ type ClusterPolicyViolationListerExpansion interface {
 	ListResources(selector labels.Selector) (ret []*kyvernov1.ClusterPolicyViolation, err error)
 }
 
+// PolicyExceptionListerExpansion allows custom methods to be added to
+// PolicyExceptionLister.
+type PolicyExceptionListerExpansion interface{}
+
+// PolicyExceptionNamespaceListerExpansion allows custom methods to be added to
+// PolicyExceptionNamespaceLister.
+type PolicyExceptionNamespaceListerExpansion interface{}
+
 // PolicyViolationListerExpansion allows custom methods to be added to
 // PolicyViolationLister.
 type PolicyViolationListerExpansion interface{}
@@ -190,3 +202,46 @@ func (s generateRequestNamespaceLister) GetGenerateRequestsForClusterPolicy(poli
 	}
 	return list, err
 }
+
+// UpdateRequestListerExpansion allows custom methods to be added to
+// UpdateRequestLister.
+type UpdateRequestListerExpansion interface {
+}
+
+// UpdateRequestNamespaceListerExpansion allows custom methods to be added to
+// UpdateRequestNamespaceLister.
+type UpdateRequestNamespaceListerExpansion interface {
+	GetUpdateRequestsForClusterPolicy(policy string) ([]*kyvernov1.UpdateRequest, error)
+	GetUpdateRequestsForResource(kind, namespace, name string) ([]*kyvernov1.UpdateRequest, error)
+}
+
+func (s updateRequestNamespaceLister) GetUpdateRequestsForResource(kind, namespace, name string) ([]*kyvernov1.UpdateRequest, error) {
+	var list []*kyvernov1.UpdateRequest
+	urs, err := s.List(labels.NewSelector())
+	if err != nil {
+		return nil, err
+	}
+	for idx, ur := range urs {
+		if ur.Spec.Resource.Kind == kind &&
+			ur.Spec.Resource.Namespace == namespace &&
+			ur.Spec.Resource.Name == name {
+			list = append(list, urs[idx])
+
+		}
+	}
+	return list, err
+}
+
+func (s updateRequestNamespaceLister) GetUpdateRequestsForClusterPolicy(policy string) ([]*kyvernov1.UpdateRequest, error) {
+	var list []*kyvernov1.UpdateRequest
+	urs, err := s.List(labels.NewSelector())
+	if err != nil {
+		return nil, err
+	}
+	for idx, ur := range urs {
+		if ur.Spec.Policy == policy {
+			list = append(list, urs[idx])
+		}
+	}
+	return list, err
+}
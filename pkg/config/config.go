@@ -4,6 +4,8 @@ import (
 	"flag"
 
 	"github.com/golang/glog"
+	// register the oidc auth provider, exec credential plugins are supported by the rest client
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	rest "k8s.io/client-go/rest"
 	clientcmd "k8s.io/client-go/tools/clientcmd"
 )
@@ -92,6 +94,8 @@ func LogDefaultFlags() {
 }
 
 //CreateClientConfig creates client config
+// the in-cluster configuration is used if no kubeconfig is given, otherwise users of the kubeconfig
+// can authenticate with client certificates, tokens, the oidc auth provider or exec credential plugins
 func CreateClientConfig(kubeconfig string) (*rest.Config, error) {
 	if kubeconfig == "" {
 		glog.Info("Using in-cluster configuration")
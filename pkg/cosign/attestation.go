@@ -0,0 +1,118 @@
+package cosign
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// envelope is the DSSE envelope of an attestation, stored as the content of the attestation layer
+type envelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+	Signatures  []struct {
+		KeyID string `json:"keyid"`
+		Sig   string `json:"sig"`
+	} `json:"signatures"`
+}
+
+// statement is the in-toto statement signed in the envelope
+type statement struct {
+	PredicateType string `json:"predicateType"`
+	Subject       []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+}
+
+// verifyAttestations checks that the image carries a valid attestation of each predicate type
+func (v *verifier) verifyAttestations(ctx context.Context, ref Reference, digest string, auth *authority, predicateTypes []string) error {
+	attestations, err := v.registry.getManifest(ctx, ref, attestationTag(digest))
+	if err != nil {
+		if _, ok := err.(*notFoundError); ok {
+			return fmt.Errorf("no attestations")
+		}
+		return fmt.Errorf("failed to fetch the attestations: %v", err)
+	}
+
+	verified := map[string]bool{}
+	for _, layer := range attestations.Layers {
+		predicateType, err := v.verifyAttestation(ctx, ref, layer, digest, auth)
+		if err != nil {
+			glog.V(4).Infof("attestation %s of image %s is not valid: %v", layer.Digest, ref.Repository, err)
+			continue
+		}
+		verified[predicateType] = true
+	}
+
+	var missing []string
+	for _, predicateType := range predicateTypes {
+		if !verified[predicateType] {
+			missing = append(missing, predicateType)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("no valid attestation found for predicate types %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// verifyAttestation verifies the signatures of the envelope in the layer, and the digest of the subject
+// of the statement, it returns the predicate type of the statement
+func (v *verifier) verifyAttestation(ctx context.Context, ref Reference, layer descriptor, digest string, auth *authority) (string, error) {
+	content, err := v.registry.getBlob(ctx, ref, layer.Digest)
+	if err != nil {
+		return "", err
+	}
+	var env envelope
+	if err := json.Unmarshal(content, &env); err != nil {
+		return "", fmt.Errorf("failed to parse envelope: %v", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode payload: %v", err)
+	}
+
+	// the Rekor entry of an attestation records the hash of the payload, not of the envelope
+	signed := pae(env.PayloadType, payload)
+	valid := false
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if err := auth.verify(signed, sig, layer.Annotations, hashOf(payload)); err != nil {
+			glog.V(4).Infof("signature %s of attestation %s is not valid: %v", s.KeyID, layer.Digest, err)
+			continue
+		}
+		valid = true
+		break
+	}
+	if !valid {
+		return "", fmt.Errorf("no valid signature found")
+	}
+
+	var st statement
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return "", fmt.Errorf("failed to parse statement: %v", err)
+	}
+	hex := strings.TrimPrefix(digest, "sha256:")
+	for _, subject := range st.Subject {
+		if subject.Digest["sha256"] == hex {
+			return st.PredicateType, nil
+		}
+	}
+	return "", fmt.Errorf("statement is not about digest %s", digest)
+}
+
+// pae returns the pre-authentication encoding of the payload, the content signed in DSSE envelopes
+func pae(payloadType string, payload []byte) []byte {
+	return []byte("DSSEv1 " + strconv.Itoa(len(payloadType)) + " " + payloadType + " " + strconv.Itoa(len(payload)) + " " + string(payload))
+}
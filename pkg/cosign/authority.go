@@ -0,0 +1,184 @@
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/wildcard"
+)
+
+const (
+	// certificateAnnotation stores the PEM encoded certificate of keyless signatures
+	certificateAnnotation = "dev.sigstore.cosign/certificate"
+	// chainAnnotation stores the PEM encoded intermediate certificates of keyless signatures
+	chainAnnotation = "dev.sigstore.cosign/chain"
+	// bundleAnnotation stores the Rekor entry of the signature
+	bundleAnnotation = "dev.sigstore.cosign/bundle"
+)
+
+// oidcIssuerOID is the extension of Fulcio certificates holding the OIDC issuer of the signer
+var oidcIssuerOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+
+// Options are the signers of the image, a key or a keyless signer, and the attestations the image must carry
+type Options struct {
+	// Key is the PEM encoded public key of the signer
+	Key string
+	// Roots are the PEM encoded root certificates of keyless signatures
+	Roots string
+	// Subject is the email or URI of the keyless signer, wildcards '*' and '?' are supported
+	Subject string
+	// Issuer is the OIDC issuer of the keyless signer
+	Issuer string
+	// RekorKey is the PEM encoded public key of the transparency log, required for keyless signatures
+	RekorKey string
+	// Attestations are the predicate types of the attestations signed by the signer
+	Attestations []string
+}
+
+// ValidateOptions returns an error if the options do not describe exactly one signer
+func ValidateOptions(opts Options) error {
+	_, err := newAuthority(opts)
+	return err
+}
+
+// authority checks that signatures are created by the key, or by the keyless signer
+type authority struct {
+	key      *ecdsa.PublicKey
+	roots    *x509.CertPool
+	subject  string
+	issuer   string
+	rekorKey *ecdsa.PublicKey
+}
+
+func newAuthority(opts Options) (*authority, error) {
+	if (opts.Key == "") == (opts.Roots == "") {
+		return nil, fmt.Errorf("either a key or the roots of keyless signatures are required")
+	}
+	a := &authority{subject: opts.Subject, issuer: opts.Issuer}
+	var err error
+	if opts.RekorKey != "" {
+		if a.rekorKey, err = parsePublicKey(opts.RekorKey); err != nil {
+			return nil, fmt.Errorf("invalid rekor key: %v", err)
+		}
+	}
+	if opts.Key != "" {
+		if opts.Subject != "" || opts.Issuer != "" {
+			return nil, fmt.Errorf("the subject and the issuer are only checked for keyless signatures")
+		}
+		a.key, err = parsePublicKey(opts.Key)
+		return a, err
+	}
+	if opts.Subject == "" {
+		return nil, fmt.Errorf("the subject of keyless signatures is required")
+	}
+	// the signing certificates are short-lived, they are checked at the time of the transparency log entry
+	if a.rekorKey == nil {
+		return nil, fmt.Errorf("the rekor key is required for keyless signatures")
+	}
+	a.roots = x509.NewCertPool()
+	if !a.roots.AppendCertsFromPEM([]byte(opts.Roots)) {
+		return nil, fmt.Errorf("failed to parse the PEM encoded root certificates")
+	}
+	return a, nil
+}
+
+// verify checks the signature of the content, the certificate and the Rekor entry of keyless signatures
+// are read from the annotations. The Rekor entry must record the entryHash, the hex encoded SHA-256 hash
+// of the signed artifact
+func (a *authority) verify(content []byte, sig []byte, annotations map[string]string, entryHash string) error {
+	var integratedTime time.Time
+	if a.rekorKey != nil {
+		bundle, ok := annotations[bundleAnnotation]
+		if !ok {
+			return fmt.Errorf("missing rekor bundle annotation")
+		}
+		var err error
+		if integratedTime, err = verifyBundle(a.rekorKey, []byte(bundle), entryHash); err != nil {
+			return fmt.Errorf("invalid rekor bundle: %v", err)
+		}
+	}
+	if a.key != nil {
+		return verifySignature(a.key, content, sig)
+	}
+
+	cert, err := parseCertificate(annotations[certificateAnnotation])
+	if err != nil {
+		return err
+	}
+	intermediates := x509.NewCertPool()
+	if chain := annotations[chainAnnotation]; chain != "" {
+		intermediates.AppendCertsFromPEM([]byte(chain))
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         a.roots,
+		Intermediates: intermediates,
+		CurrentTime:   integratedTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return fmt.Errorf("invalid certificate: %v", err)
+	}
+	if err := a.checkIdentity(cert); err != nil {
+		return err
+	}
+	pubKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported certificate key type %T, only ECDSA keys are supported", cert.PublicKey)
+	}
+	return verifySignature(pubKey, content, sig)
+}
+
+// checkIdentity checks that the certificate is issued to the subject by the OIDC issuer
+func (a *authority) checkIdentity(cert *x509.Certificate) error {
+	var identities []string
+	identities = append(identities, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	matched := false
+	for _, identity := range identities {
+		if wildcard.Match(a.subject, identity) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return fmt.Errorf("certificate is issued to %s, not to %s", strings.Join(identities, ", "), a.subject)
+	}
+	if a.issuer == "" {
+		return nil
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidcIssuerOID) {
+			if string(ext.Value) != a.issuer {
+				return fmt.Errorf("certificate is issued by %s, not by %s", string(ext.Value), a.issuer)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate has no OIDC issuer")
+}
+
+func parseCertificate(certificate string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return nil, fmt.Errorf("missing certificate annotation")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// hashOf returns the hex encoded SHA-256 hash of the content
+func hashOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
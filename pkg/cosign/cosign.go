@@ -0,0 +1,247 @@
+// Package cosign verifies cosign signatures and attestations of container images.
+// Signatures created with a key pair and keyless signatures (Fulcio certificates recorded
+// in the Rekor transparency log) are supported, the Rekor entries are verified offline
+// from the bundles stored with the signatures.
+package cosign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// signatureAnnotation stores the base64 encoded signature of the payload in the signature layer
+const signatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// Verifier verifies image signatures
+type Verifier interface {
+	// Verify checks that the image is signed by the signer of the options, and carries the attestations
+	// of the options, and returns the digest of the verified image. The registry calls are bounded by the context
+	Verify(ctx context.Context, image string, opts Options) (string, error)
+}
+
+const (
+	// number of cached digests and verifications
+	cacheSize = 1000
+	// tags can be moved to another image and signatures can be removed, the cached
+	// digests and verifications expire after cacheTTL
+	cacheTTL = 5 * time.Minute
+)
+
+// NewVerifier returns a verifier reading the signatures from the image registries
+func NewVerifier() Verifier {
+	return newVerifier(newRegistryClient(&http.Client{Timeout: 10 * time.Second}, "https"))
+}
+
+// NewOfflineVerifier returns a verifier for air-gapped clusters, the image registries are never contacted
+// and the verification of every image fails
+func NewOfflineVerifier() Verifier {
+	return offlineVerifier{}
+}
+
+type offlineVerifier struct{}
+
+func (offlineVerifier) Verify(ctx context.Context, image string, opts Options) (string, error) {
+	return "", fmt.Errorf("the signatures of %s cannot be fetched from the image registry, registry lookups are disabled in offline mode", image)
+}
+
+type verifier struct {
+	registry *registryClient
+	// digests of the tagged images
+	digests *lru.Cache
+	// verified digests, by digest and options
+	verified *lru.Cache
+}
+
+// cacheEntry is a cached value and its expiration time
+type cacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+func newVerifier(registry *registryClient) *verifier {
+	// the size is positive, lru.New cannot fail
+	digests, _ := lru.New(cacheSize)
+	verified, _ := lru.New(cacheSize)
+	return &verifier{
+		registry: registry,
+		digests:  digests,
+		verified: verified,
+	}
+}
+
+// payload is the simple signing payload signed by cosign
+type payload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+func (v *verifier) Verify(ctx context.Context, image string, opts Options) (string, error) {
+	auth, err := newAuthority(opts)
+	if err != nil {
+		return "", err
+	}
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	digest, err := v.resolveDigest(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the digest of image %s: %v", image, err)
+	}
+	// the options are plain strings, they cannot fail to marshal
+	rawOpts, _ := json.Marshal(opts)
+	verifiedKey := digest + "/" + digestOf(rawOpts)
+	if _, ok := getCached(v.verified, verifiedKey); ok {
+		return digest, nil
+	}
+	if err := v.verifySignatures(ctx, ref, digest, auth); err != nil {
+		return "", fmt.Errorf("image %s: %v", image, err)
+	}
+	if len(opts.Attestations) > 0 {
+		if err := v.verifyAttestations(ctx, ref, digest, auth, opts.Attestations); err != nil {
+			return "", fmt.Errorf("image %s: %v", image, err)
+		}
+	}
+	addCached(v.verified, verifiedKey, digest)
+	return digest, nil
+}
+
+// verifySignatures checks that one of the signatures of the digest is valid
+func (v *verifier) verifySignatures(ctx context.Context, ref Reference, digest string, auth *authority) error {
+	signatures, err := v.registry.getManifest(ctx, ref, signatureTag(digest))
+	if err != nil {
+		if _, ok := err.(*notFoundError); ok {
+			return fmt.Errorf("not signed")
+		}
+		return fmt.Errorf("failed to fetch the signatures: %v", err)
+	}
+	for _, layer := range signatures.Layers {
+		if err := v.verifyLayer(ctx, ref, layer, digest, auth); err != nil {
+			glog.V(4).Infof("signature %s of image %s is not valid: %v", layer.Digest, ref.Repository, err)
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("no valid signature found")
+}
+
+// resolveDigest returns the digest of the image, the digests of tags are cached
+func (v *verifier) resolveDigest(ctx context.Context, ref Reference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	tag := ref.Registry + "/" + ref.Repository + ":" + ref.Tag
+	if digest, ok := getCached(v.digests, tag); ok {
+		return digest, nil
+	}
+	digest, err := v.registry.resolveDigest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	addCached(v.digests, tag, digest)
+	return digest, nil
+}
+
+func getCached(cache *lru.Cache, key string) (string, bool) {
+	value, ok := cache.Get(key)
+	if !ok {
+		return "", false
+	}
+	entry := value.(cacheEntry)
+	if time.Now().After(entry.expires) {
+		cache.Remove(key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+func addCached(cache *lru.Cache, key string, value string) {
+	cache.Add(key, cacheEntry{value: value, expires: time.Now().Add(cacheTTL)})
+}
+
+// verifyLayer verifies the signature of the payload in the layer, and the digest signed in the payload
+func (v *verifier) verifyLayer(ctx context.Context, ref Reference, layer descriptor, digest string, auth *authority) error {
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[signatureAnnotation])
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("missing signature annotation")
+	}
+	content, err := v.registry.getBlob(ctx, ref, layer.Digest)
+	if err != nil {
+		return err
+	}
+	if err := auth.verify(content, sig, layer.Annotations, hashOf(content)); err != nil {
+		return err
+	}
+	var p payload
+	if err := json.Unmarshal(content, &p); err != nil {
+		return fmt.Errorf("failed to parse payload: %v", err)
+	}
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("payload is signed for digest %s", p.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+// ValidateKey returns an error if the key is not a PEM encoded ECDSA public key
+func ValidateKey(key string) error {
+	_, err := parsePublicKey(key)
+	return err
+}
+
+// VerifyBlob checks that the signature of the content was created with the
+// PEM encoded public key, as done by cosign sign-blob
+func VerifyBlob(key string, content []byte, signature []byte) error {
+	pubKey, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	return verifySignature(pubKey, content, signature)
+}
+
+func parsePublicKey(key string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	ecdsaPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, only ECDSA keys are supported", pub)
+	}
+	return ecdsaPub, nil
+}
+
+// verifySignature verifies the ASN.1 encoded ECDSA signature of the SHA-256 hash of the content
+func verifySignature(pubKey *ecdsa.PublicKey, content []byte, sig []byte) error {
+	var esig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(sig, &esig); err != nil {
+		return fmt.Errorf("failed to parse signature: %v", err)
+	}
+	hash := sha256.Sum256(content)
+	if !ecdsa.Verify(pubKey, hash[:], esig.R, esig.S) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,447 @@
+package cosign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReference(t *testing.T) {
+	testcases := []struct {
+		image    string
+		expected Reference
+	}{
+		{"nginx", Reference{Registry: "index.docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"nginx:1.17", Reference{Registry: "index.docker.io", Repository: "library/nginx", Tag: "1.17"}},
+		{"ghcr.io/org/app:v1", Reference{Registry: "ghcr.io", Repository: "org/app", Tag: "v1"}},
+		{"localhost:5000/app@sha256:abc", Reference{Registry: "localhost:5000", Repository: "app", Digest: "sha256:abc"}},
+		{"org/app:v1@sha256:abc", Reference{Registry: "index.docker.io", Repository: "org/app", Tag: "v1", Digest: "sha256:abc"}},
+	}
+	for _, tc := range testcases {
+		ref, err := ParseReference(tc.image)
+		if err != nil {
+			t.Errorf("failed to parse %s: %v", tc.image, err)
+			continue
+		}
+		if ref != tc.expected {
+			t.Errorf("image %s: expected %v, got %v", tc.image, tc.expected, ref)
+		}
+	}
+}
+
+// fakeRegistry serves an image and its cosign signature
+type fakeRegistry struct {
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	digest    string
+	requests  int
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests++
+	if i := strings.Index(req.URL.Path, "/manifests/"); i != -1 {
+		reference := req.URL.Path[i+len("/manifests/"):]
+		if reference == "v1" {
+			w.Header().Set("Docker-Content-Digest", r.digest)
+		}
+		if content, ok := r.manifests[reference]; ok {
+			w.Write(content)
+			return
+		}
+	}
+	if i := strings.Index(req.URL.Path, "/blobs/"); i != -1 {
+		if content, ok := r.blobs[req.URL.Path[i+len("/blobs/"):]]; ok {
+			w.Write(content)
+			return
+		}
+	}
+	http.NotFound(w, req)
+}
+
+func newSignedRegistry(t *testing.T, key *ecdsa.PrivateKey) *fakeRegistry {
+	imageManifest := []byte(`{"schemaVersion":2,"layers":[]}`)
+	digest := digestOf(imageManifest)
+	r := &fakeRegistry{
+		digest:    digest,
+		manifests: map[string][]byte{"v1": imageManifest},
+		blobs:     map[string][]byte{},
+	}
+	payload := signedPayload(digest)
+	r.setLayers(t, signatureTag(digest), r.addBlob(payload, map[string]string{
+		signatureAnnotation: base64.StdEncoding.EncodeToString(sign(t, key, payload)),
+	}))
+	return r
+}
+
+// signedPayload returns the simple signing payload of the digest
+func signedPayload(digest string) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+}
+
+// addBlob stores the content and returns its layer
+func (r *fakeRegistry) addBlob(content []byte, annotations map[string]string) descriptor {
+	r.blobs[digestOf(content)] = content
+	return descriptor{
+		MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+		Digest:      digestOf(content),
+		Annotations: annotations,
+	}
+}
+
+// setLayers stores the manifest of the tag
+func (r *fakeRegistry) setLayers(t *testing.T, tag string, layers ...descriptor) {
+	content, err := json.Marshal(manifest{Layers: layers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.manifests[tag] = content
+}
+
+// sign returns the ASN.1 encoded ECDSA signature of the SHA-256 hash of the content
+func sign(t *testing.T, key *ecdsa.PrivateKey, content []byte) []byte {
+	hash := sha256.Sum256(content)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := newSignedRegistry(t, key)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	v := newVerifier(newRegistryClient(server.Client(), "http"))
+
+	digest, err := v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, key)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != registry.digest {
+		t.Errorf("expected digest %s, got %s", registry.digest, digest)
+	}
+
+	// the digest and the verification are cached
+	requests := registry.requests
+	if _, err := v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, key)}); err != nil {
+		t.Fatal(err)
+	}
+	if registry.requests != requests {
+		t.Errorf("expected the verification to be cached, got %d registry requests", registry.requests-requests)
+	}
+
+	if _, err := v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, otherKey)}); err == nil {
+		t.Errorf("expected the verification with another key to fail")
+	}
+
+	// unsigned image
+	delete(registry.manifests, signatureTag(registry.digest))
+	v = newVerifier(newRegistryClient(server.Client(), "http"))
+	if _, err := v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, key)}); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("expected unsigned image error, got %v", err)
+	}
+
+	// the registry calls are bounded by the context
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	v = newVerifier(newRegistryClient(server.Client(), "http"))
+	if _, err := v.Verify(ctx, host+"/app:v1", Options{Key: publicKeyPEM(t, key)}); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("expected context canceled error, got %v", err)
+	}
+}
+
+func TestOfflineVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewOfflineVerifier().Verify(context.TODO(), "ghcr.io/myorg/app:v1", Options{Key: publicKeyPEM(t, key)})
+	if err == nil || !strings.Contains(err.Error(), "offline mode") {
+		t.Errorf("expected offline mode error, got %v", err)
+	}
+}
+
+// newBundle returns the Rekor bundle of the artifact hash, signed with the rekor key
+func newBundle(t *testing.T, rekorKey *ecdsa.PrivateKey, kind string, hash string, integratedTime time.Time) string {
+	spec := map[string]interface{}{
+		"data": map[string]interface{}{"hash": map[string]string{"algorithm": "sha256", "value": hash}},
+	}
+	if kind == "intoto" {
+		spec = map[string]interface{}{
+			"content": map[string]interface{}{"payloadHash": map[string]string{"algorithm": "sha256", "value": hash}},
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"apiVersion": "0.0.1", "kind": kind, "spec": spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := bundlePayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: integratedTime.Unix(),
+		LogID:          "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d",
+		LogIndex:       42,
+	}
+	signed, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(bundle{SignedEntryTimestamp: sign(t, rekorKey, signed), Payload: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+// newSigner returns a root certificate and an expired signing certificate issued by the root
+// to the email, with the OIDC issuer extension
+func newSigner(t *testing.T, email, issuer string) (root string, cert string, key *ecdsa.PrivateKey) {
+	caKey := newKey(t)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fulcio"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key = newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       time.Now().Add(-2 * time.Hour),
+		NotAfter:        time.Now().Add(-time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses:  []string{email},
+		ExtraExtensions: []pkix.Extension{{Id: oidcIssuerOID, Value: []byte(issuer)}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}))
+	cert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return root, cert, key
+}
+
+func TestVerifyKeyless(t *testing.T) {
+	root, cert, key := newSigner(t, "alice@example.com", "https://accounts.example.com")
+	rekorKey := newKey(t)
+	registry := newSignedRegistry(t, newKey(t))
+	payload := signedPayload(registry.digest)
+	// the signing certificate is expired, it was valid when the signature was added to the log
+	registry.setLayers(t, signatureTag(registry.digest), registry.addBlob(payload, map[string]string{
+		signatureAnnotation:   base64.StdEncoding.EncodeToString(sign(t, key, payload)),
+		certificateAnnotation: cert,
+		bundleAnnotation:      newBundle(t, rekorKey, "hashedrekord", hashOf(payload), time.Now().Add(-90*time.Minute)),
+	}))
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	opts := Options{
+		Roots:    root,
+		Subject:  "*@example.com",
+		Issuer:   "https://accounts.example.com",
+		RekorKey: publicKeyPEM(t, rekorKey),
+	}
+	v := newVerifier(newRegistryClient(server.Client(), "http"))
+	digest, err := v.Verify(context.TODO(), host+"/app:v1", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != registry.digest {
+		t.Errorf("expected digest %s, got %s", registry.digest, digest)
+	}
+
+	otherRoot, _, _ := newSigner(t, "alice@example.com", "https://accounts.example.com")
+	invalid := map[string]Options{
+		"subject":   {Roots: root, Subject: "bob@example.com", Issuer: opts.Issuer, RekorKey: opts.RekorKey},
+		"issuer":    {Roots: root, Subject: opts.Subject, Issuer: "https://token.example.com", RekorKey: opts.RekorKey},
+		"root":      {Roots: otherRoot, Subject: opts.Subject, Issuer: opts.Issuer, RekorKey: opts.RekorKey},
+		"rekor key": {Roots: root, Subject: opts.Subject, Issuer: opts.Issuer, RekorKey: publicKeyPEM(t, newKey(t))},
+	}
+	for name, opts := range invalid {
+		v := newVerifier(newRegistryClient(server.Client(), "http"))
+		if _, err := v.Verify(context.TODO(), host+"/app:v1", opts); err == nil {
+			t.Errorf("expected the verification with another %s to fail", name)
+		}
+	}
+
+	// the bundle must record the signed payload
+	registry.setLayers(t, signatureTag(registry.digest), registry.addBlob(payload, map[string]string{
+		signatureAnnotation:   base64.StdEncoding.EncodeToString(sign(t, key, payload)),
+		certificateAnnotation: cert,
+		bundleAnnotation:      newBundle(t, rekorKey, "hashedrekord", hashOf([]byte("other")), time.Now().Add(-90*time.Minute)),
+	}))
+	v = newVerifier(newRegistryClient(server.Client(), "http"))
+	if _, err := v.Verify(context.TODO(), host+"/app:v1", opts); err == nil {
+		t.Errorf("expected the verification with the bundle of another artifact to fail")
+	}
+}
+
+// addAttestation stores a DSSE envelope with the statement of the predicate type about the image
+func addAttestation(t *testing.T, r *fakeRegistry, key *ecdsa.PrivateKey, predicateType string) descriptor {
+	statement, err := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"predicateType": predicateType,
+		"subject": []map[string]interface{}{{
+			"name":   "app",
+			"digest": map[string]string{"sha256": strings.TrimPrefix(r.digest, "sha256:")},
+		}},
+		"predicate": map[string]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadType := "application/vnd.in-toto+json"
+	env, err := json.Marshal(map[string]interface{}{
+		"payloadType": payloadType,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures": []map[string]string{{
+			"keyid": "",
+			"sig":   base64.StdEncoding.EncodeToString(sign(t, key, pae(payloadType, statement))),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r.addBlob(env, map[string]string{})
+}
+
+func TestVerifyAttestations(t *testing.T) {
+	key := newKey(t)
+	registry := newSignedRegistry(t, key)
+	provenance := "https://slsa.dev/provenance/v0.2"
+	registry.setLayers(t, attestationTag(registry.digest),
+		addAttestation(t, registry, key, provenance),
+		addAttestation(t, registry, newKey(t), "https://cyclonedx.org/bom"),
+	)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	v := newVerifier(newRegistryClient(server.Client(), "http"))
+	if _, err := v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, key), Attestations: []string{provenance}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the attestation of the bom is signed with another key
+	_, err := v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, key), Attestations: []string{provenance, "https://cyclonedx.org/bom"}})
+	if err == nil || !strings.Contains(err.Error(), "no valid attestation found for predicate types https://cyclonedx.org/bom") {
+		t.Errorf("expected missing attestation error, got %v", err)
+	}
+
+	delete(registry.manifests, attestationTag(registry.digest))
+	v = newVerifier(newRegistryClient(server.Client(), "http"))
+	_, err = v.Verify(context.TODO(), host+"/app:v1", Options{Key: publicKeyPEM(t, key), Attestations: []string{provenance}})
+	if err == nil || !strings.Contains(err.Error(), "no attestations") {
+		t.Errorf("expected no attestations error, got %v", err)
+	}
+}
+
+func TestVerifyBundleIntoto(t *testing.T) {
+	rekorKey := newKey(t)
+	integratedTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	raw := newBundle(t, rekorKey, "intoto", hashOf([]byte("statement")), integratedTime)
+	got, err := verifyBundle(&rekorKey.PublicKey, []byte(raw), hashOf([]byte("statement")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(integratedTime) {
+		t.Errorf("expected integrated time %v, got %v", integratedTime, got)
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	registry := newSignedRegistry(t, newKey(t))
+	large := registry.addBlob(make([]byte, maxResponseSize+1), nil)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	ref, err := ParseReference(strings.TrimPrefix(server.URL, "http://") + "/app:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := newRegistryClient(server.Client(), "http")
+	if _, err := rc.getBlob(context.TODO(), ref, large.Digest); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("expected response size error, got %v", err)
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	root, _, _ := newSigner(t, "alice@example.com", "https://accounts.example.com")
+	key := publicKeyPEM(t, newKey(t))
+	testcases := []struct {
+		name  string
+		opts  Options
+		valid bool
+	}{
+		{"key", Options{Key: key}, true},
+		{"key and rekor key", Options{Key: key, RekorKey: key}, true},
+		{"keyless", Options{Roots: root, Subject: "*@example.com", RekorKey: key}, true},
+		{"no signer", Options{}, false},
+		{"key and roots", Options{Key: key, Roots: root}, false},
+		{"key and subject", Options{Key: key, Subject: "*@example.com"}, false},
+		{"keyless without subject", Options{Roots: root, RekorKey: key}, false},
+		{"keyless without rekor key", Options{Roots: root, Subject: "*@example.com"}, false},
+		{"invalid roots", Options{Roots: "not a certificate", Subject: "*@example.com", RekorKey: key}, false},
+	}
+	for _, tc := range testcases {
+		if err := ValidateOptions(tc.opts); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid %v, got %v", tc.name, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cosign
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultRegistry = "index.docker.io"
+	defaultTag      = "latest"
+)
+
+// Reference identifies an image in a registry
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference such as nginx, ghcr.io/org/app:1.0 or
+// registry:5000/app@sha256:..., images without registry are pulled from Docker Hub
+func ParseReference(image string) (Reference, error) {
+	var ref Reference
+	if image == "" {
+		return ref, fmt.Errorf("empty image reference")
+	}
+	name := image
+	if i := strings.Index(name, "@"); i != -1 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !strings.HasPrefix(ref.Digest, "sha256:") {
+			return ref, fmt.Errorf("unsupported digest %s in image %s", ref.Digest, image)
+		}
+	}
+	// the tag follows the last colon after the last slash, the registry may have a port
+	if i := strings.LastIndex(name, ":"); i != -1 && i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultTag
+	}
+
+	// the first component is a registry if it contains a dot or a port, or is localhost
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = defaultRegistry
+		ref.Repository = name
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" {
+		return ref, fmt.Errorf("invalid image reference %s", image)
+	}
+	return ref, nil
+}
+
+// String returns the fully qualified reference
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// signatureTag returns the tag of the cosign signature of the image digest, i.e. sha256-<hex>.sig
+func signatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// attestationTag returns the tag of the cosign attestations of the image digest, i.e. sha256-<hex>.att
+func attestationTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".att"
+}
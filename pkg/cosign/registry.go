@@ -0,0 +1,224 @@
+package cosign
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxResponseSize is the size limit of the manifests, blobs and tokens read from the registries,
+// signatures and attestations are small, larger responses are rejected
+const maxResponseSize = 4 << 20
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// manifest is the subset of the OCI image manifest used to read signatures
+type manifest struct {
+	Layers []descriptor `json:"layers"`
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// registryClient reads manifests and blobs with the OCI distribution API,
+// registries requiring credentials are not supported, anonymous bearer tokens are requested if needed
+type registryClient struct {
+	client *http.Client
+	// https, or http for tests
+	scheme string
+
+	mu sync.Mutex
+	// bearer token per registry and repository
+	tokens map[string]string
+}
+
+func newRegistryClient(client *http.Client, scheme string) *registryClient {
+	return &registryClient{
+		client: client,
+		scheme: scheme,
+		tokens: map[string]string{},
+	}
+}
+
+// resolveDigest returns the digest of the manifest the tag points to
+func (rc *registryClient) resolveDigest(ctx context.Context, ref Reference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	resp, body, err := rc.get(ctx, ref, "/manifests/"+ref.Tag, manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	return digestOf(body), nil
+}
+
+// getManifest returns the manifest of the tag or digest
+func (rc *registryClient) getManifest(ctx context.Context, ref Reference, reference string) (*manifest, error) {
+	_, body, err := rc.get(ctx, ref, "/manifests/"+reference, manifestMediaTypes)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s of %s: %v", reference, ref.Repository, err)
+	}
+	return &m, nil
+}
+
+// getBlob returns the content of the blob, the content is checked against the digest
+func (rc *registryClient) getBlob(ctx context.Context, ref Reference, digest string) ([]byte, error) {
+	_, body, err := rc.get(ctx, ref, "/blobs/"+digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	if digestOf(body) != digest {
+		return nil, fmt.Errorf("content of blob %s of %s does not match its digest", digest, ref.Repository)
+	}
+	return body, nil
+}
+
+func (rc *registryClient) get(ctx context.Context, ref Reference, path string, accept []string) (*http.Response, []byte, error) {
+	u := fmt.Sprintf("%s://%s/v2/%s%s", rc.scheme, ref.Registry, ref.Repository, path)
+	key := ref.Registry + "/" + ref.Repository
+	resp, body, err := rc.do(ctx, u, accept, rc.token(key))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := rc.fetchToken(ctx, resp.Header.Get("WWW-Authenticate"), ref.Repository)
+		if err != nil {
+			return nil, nil, err
+		}
+		rc.setToken(key, token)
+		if resp, body, err = rc.do(ctx, u, accept, token); err != nil {
+			return nil, nil, err
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, &notFoundError{url: u}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("GET %s: unexpected status %s", u, resp.Status)
+	}
+	return resp, body, nil
+}
+
+func (rc *registryClient) do(ctx context.Context, u string, accept []string, token string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ","))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) > maxResponseSize {
+		return nil, nil, fmt.Errorf("GET %s: response is larger than %d bytes", u, maxResponseSize)
+	}
+	return resp, body, nil
+}
+
+// fetchToken requests an anonymous pull token from the realm of the bearer challenge
+func (rc *registryClient) fetchToken(ctx context.Context, challenge string, repository string) (string, error) {
+	params := parseChallenge(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("unsupported authentication challenge %q, only anonymous bearer tokens are supported", challenge)
+	}
+	query := url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", "repository:"+repository+":pull")
+	resp, body, err := rc.do(ctx, realm+"?"+query.Encode(), nil, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get token from %s: unexpected status %s", realm, resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+func (rc *registryClient) token(key string) string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.tokens[key]
+}
+
+func (rc *registryClient) setToken(key, token string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.tokens[key] = token
+}
+
+// parseChallenge parses a challenge such as: Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) map[string]string {
+	params := map[string]string{}
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return params
+	}
+	for _, param := range strings.Split(challenge[len("bearer "):], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return params
+}
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+type notFoundError struct {
+	url string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("GET %s: not found", e.url)
+}
@@ -0,0 +1,84 @@
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// bundle is the Rekor entry of a signature, stored in the signature layer. It is verified offline
+// with the public key of the log, the log is not contacted
+type bundle struct {
+	SignedEntryTimestamp []byte        `json:"SignedEntryTimestamp"`
+	Payload              bundlePayload `json:"Payload"`
+}
+
+// bundlePayload is the signed part of the bundle, the fields are declared in the order
+// of the canonical JSON encoding signed by the log
+type bundlePayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// entryHash is the hash of an artifact in a log entry
+type entryHash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// entry is the subset of the hashedrekord, rekord and intoto log entries recording the signed artifact
+type entry struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		// hashedrekord and rekord entries
+		Data struct {
+			Hash entryHash `json:"hash"`
+		} `json:"data"`
+		// intoto entries
+		Content struct {
+			PayloadHash entryHash `json:"payloadHash"`
+		} `json:"content"`
+	} `json:"spec"`
+}
+
+// verifyBundle checks that the bundle is signed by the log and records the artifact with the
+// hex encoded SHA-256 hash, it returns the time the entry was added to the log
+func verifyBundle(rekorKey *ecdsa.PublicKey, raw []byte, hash string) (time.Time, error) {
+	var b bundle
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse bundle: %v", err)
+	}
+	signed, err := json.Marshal(b.Payload)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := verifySignature(rekorKey, signed, b.SignedEntryTimestamp); err != nil {
+		return time.Time{}, fmt.Errorf("signed entry timestamp: %v", err)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(b.Payload.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode entry: %v", err)
+	}
+	var e entry
+	if err := json.Unmarshal(body, &e); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse entry: %v", err)
+	}
+	var recorded entryHash
+	switch e.Kind {
+	case "hashedrekord", "rekord":
+		recorded = e.Spec.Data.Hash
+	case "intoto":
+		recorded = e.Spec.Content.PayloadHash
+	default:
+		return time.Time{}, fmt.Errorf("unsupported entry kind %q", e.Kind)
+	}
+	if recorded.Algorithm != "sha256" || recorded.Value != hash {
+		return time.Time{}, fmt.Errorf("entry records another artifact")
+	}
+	return time.Unix(b.Payload.IntegratedTime, 0), nil
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -12,6 +13,8 @@ import (
 	certificates "k8s.io/api/certificates/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/rest"
 )
 
@@ -75,23 +78,31 @@ func (c *Client) submitAndApproveCertificateRequest(req *certificates.Certificat
 	if err != nil {
 		return nil, err
 	}
-	csrList, err := c.ListResource(CSRs, "", nil)
+	var existingCSR string
+	// only the request with the same name is listed
+	options := ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", req.ObjectMeta.Name)}
+	err = c.ListResourceInPages(context.TODO(), CSRs, "", options, func(csrList *unstructured.UnstructuredList) error {
+		for _, csr := range csrList.Items {
+			if csr.GetName() == req.ObjectMeta.Name {
+				existingCSR = csr.GetName()
+				break
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("Unable to list existing certificate requests: %v", err)
 	}
 
-	for _, csr := range csrList.Items {
-		if csr.GetName() == req.ObjectMeta.Name {
-			err := c.DeleteResource(CSRs, "", csr.GetName(), false)
-			if err != nil {
-				return nil, fmt.Errorf("Unable to delete existing certificate request: %v", err)
-			}
-			glog.Info("Old certificate request is deleted")
-			break
+	if existingCSR != "" {
+		err := c.DeleteResource(context.TODO(), CSRs, "", existingCSR, false)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to delete existing certificate request: %v", err)
 		}
+		glog.Info("Old certificate request is deleted")
 	}
 
-	unstrRes, err := c.CreateResource(CSRs, "", req, false)
+	unstrRes, err := c.CreateResource(context.TODO(), CSRs, "", req, false)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +131,7 @@ func (c *Client) fetchCertificateFromRequest(req *certificates.CertificateSignin
 	// TODO: react of SIGINT and SIGTERM
 	timeStart := time.Now()
 	for time.Since(timeStart) < time.Duration(maxWaitSeconds)*time.Second {
-		unstrR, err := c.GetResource(CSRs, "", req.ObjectMeta.Name)
+		unstrR, err := c.GetResource(context.TODO(), CSRs, "", req.ObjectMeta.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -150,7 +161,7 @@ func (c *Client) ReadRootCASecret() (result []byte) {
 		return result
 	}
 	sname := generateRootCASecretName(certProps)
-	stlsca, err := c.GetResource(Secrets, certProps.Namespace, sname)
+	stlsca, err := c.GetResource(context.TODO(), Secrets, certProps.Namespace, sname)
 	if err != nil {
 		return result
 	}
@@ -175,7 +186,7 @@ const rootCAKey string = "rootCA.crt"
 //ReadTlsPair Reads the pair of TLS certificate and key from the specified secret.
 func (c *Client) ReadTlsPair(props tls.TlsCertificateProps) *tls.TlsPemPair {
 	sname := generateTLSPairSecretName(props)
-	unstrSecret, err := c.GetResource(Secrets, props.Namespace, sname)
+	unstrSecret, err := c.GetResource(context.TODO(), Secrets, props.Namespace, sname)
 	if err != nil {
 		glog.Warningf("Unable to get secret %s/%s: %s", props.Namespace, sname, err)
 		return nil
@@ -186,7 +197,7 @@ func (c *Client) ReadTlsPair(props tls.TlsCertificateProps) *tls.TlsPemPair {
 	annotations := unstrSecret.GetAnnotations()
 	if _, ok := annotations[selfSignedAnnotation]; ok {
 		sname := generateRootCASecretName(props)
-		_, err := c.GetResource(Secrets, props.Namespace, sname)
+		_, err := c.GetResource(context.TODO(), Secrets, props.Namespace, sname)
 		if err != nil {
 			glog.Errorf("Root CA secret %s/%s is required while using self-signed certificates TLS pair, defaulting to generating new TLS pair", props.Namespace, sname)
 			return nil
@@ -215,7 +226,7 @@ func (c *Client) ReadTlsPair(props tls.TlsCertificateProps) *tls.TlsPemPair {
 // Updates existing secret or creates new one.
 func (c *Client) WriteTlsPair(props tls.TlsCertificateProps, pemPair *tls.TlsPemPair) error {
 	name := generateTLSPairSecretName(props)
-	_, err := c.GetResource(Secrets, props.Namespace, name)
+	_, err := c.GetResource(context.TODO(), Secrets, props.Namespace, name)
 	if err != nil {
 		secret := &v1.Secret{
 			TypeMeta: metav1.TypeMeta{
@@ -233,7 +244,7 @@ func (c *Client) WriteTlsPair(props tls.TlsCertificateProps, pemPair *tls.TlsPem
 			Type: v1.SecretTypeTLS,
 		}
 
-		_, err := c.CreateResource(Secrets, props.Namespace, secret, false)
+		_, err := c.CreateResource(context.TODO(), Secrets, props.Namespace, secret, false)
 		if err == nil {
 			glog.Infof("Secret %s is created", name)
 		}
@@ -247,7 +258,7 @@ func (c *Client) WriteTlsPair(props tls.TlsCertificateProps, pemPair *tls.TlsPem
 	secret.Data[v1.TLSCertKey] = pemPair.Certificate
 	secret.Data[v1.TLSPrivateKeyKey] = pemPair.PrivateKey
 
-	_, err = c.UpdateResource(Secrets, props.Namespace, secret, false)
+	_, err = c.UpdateResource(context.TODO(), Secrets, props.Namespace, secret, false)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -15,6 +16,7 @@ import (
 	helperv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	patchTypes "k8s.io/apimachinery/pkg/types"
@@ -27,30 +29,66 @@ import (
 	csrtype "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
 	event "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/flowcontrol"
 )
 
+// DefaultFieldManager is the field manager used for server-side apply
+const DefaultFieldManager = "kyverno"
+
+// CRDs are watched to detect new kinds
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1beta1", Resource: "customresourcedefinitions"}
+
+// DefaultTimeout bounds the calls made with a context without deadline
+const DefaultTimeout = 30 * time.Second
+
+// DefaultPageSize is the number of items requested per page when listing resources in pages
+const DefaultPageSize int64 = 500
+
 //Client enables interaction with k8 resource
 type Client struct {
-	client          dynamic.Interface
-	clientConfig    *rest.Config
-	kclient         kubernetes.Interface
+	// the requests of client and kclient time out after DefaultTimeout, abandoned calls included
+	client       dynamic.Interface
+	clientConfig *rest.Config
+	kclient      kubernetes.Interface
+	// client without timeout, the watches of the informers are long-running requests
+	informerClient  dynamic.Interface
 	DiscoveryClient IDiscovery
+	// field manager used for server-side apply
+	fieldManager string
+	// timeout of the calls made with a context without deadline
+	timeout time.Duration
+	// informer caches of the hot kinds
+	typedCache *typedCache
 }
 
 //NewClient creates new instance of client
 func NewClient(config *rest.Config, resync time.Duration, stopCh <-chan struct{}) (*Client, error) {
-	dclient, err := dynamic.NewForConfig(config)
+	informerClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
-	kclient, err := kubernetes.NewForConfig(config)
+	clientConfig := rest.CopyConfig(config)
+	clientConfig.Timeout = DefaultTimeout
+	// the clients created from copies of the config, e.g. impersonating clients, share the QPS and burst limits
+	if clientConfig.RateLimiter == nil {
+		clientConfig.RateLimiter = newRateLimiter(clientConfig)
+	}
+	dclient, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	kclient, err := kubernetes.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, err
 	}
 	client := Client{
-		client:       dclient,
-		clientConfig: config,
-		kclient:      kclient,
+		client:         dclient,
+		clientConfig:   clientConfig,
+		kclient:        kclient,
+		informerClient: informerClient,
+		fieldManager:   DefaultFieldManager,
+		timeout:        DefaultTimeout,
 	}
 	// Set discovery client
 	discoveryClient := ServerPreferredResources{memory.NewMemCacheClient(kclient.Discovery())}
@@ -60,19 +98,36 @@ func NewClient(config *rest.Config, resync time.Duration, stopCh <-chan struct{}
 	// If a resource is removed then and cache is not invalidate yet, we will not detect the removal
 	// but the re-sync shall re-evaluate
 	go discoveryClient.Poll(resync, stopCh)
+	// CRDs installed after startup are registered as soon as they are established
+	crdInformer := dynamicinformer.NewFilteredDynamicInformer(informerClient, crdGVR, "", resync, cache.Indexers{}, nil)
+	crdInformer.Informer().AddEventHandler(discoveryClient.crdEventHandler())
+	go crdInformer.Informer().Run(stopCh)
 
 	client.SetDiscovery(discoveryClient)
 	return &client, nil
 }
 
+// newRateLimiter returns the client-side rate limiter of the config, with the defaults of client-go
+func newRateLimiter(config *rest.Config) flowcontrol.RateLimiter {
+	qps := config.QPS
+	if qps == 0 {
+		qps = rest.DefaultQPS
+	}
+	burst := config.Burst
+	if burst == 0 {
+		burst = rest.DefaultBurst
+	}
+	return flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+}
+
 //NewDynamicSharedInformerFactory returns a new instance of DynamicSharedInformerFactory
 func (c *Client) NewDynamicSharedInformerFactory(defaultResync time.Duration) dynamicinformer.DynamicSharedInformerFactory {
-	return dynamicinformer.NewDynamicSharedInformerFactory(c.client, defaultResync)
+	return dynamicinformer.NewDynamicSharedInformerFactory(c.informerClient, defaultResync)
 }
 
 //GetKubePolicyDeployment returns kube policy depoyment value
-func (c *Client) GetKubePolicyDeployment() (*apps.Deployment, error) {
-	kubePolicyDeployment, err := c.GetResource("Deployment", config.KubePolicyNamespace, config.KubePolicyDeploymentName)
+func (c *Client) GetKubePolicyDeployment(ctx context.Context) (*apps.Deployment, error) {
+	kubePolicyDeployment, err := c.GetResource(ctx, "Deployment", config.KubePolicyNamespace, config.KubePolicyDeploymentName)
 	if err != nil {
 		return nil, err
 	}
@@ -117,71 +172,239 @@ func (c *Client) getGroupVersionMapper(kind string) schema.GroupVersionResource
 	return c.DiscoveryClient.GetGVRFromKind(kind)
 }
 
+// SetTimeout sets the timeout of the calls made with a context without deadline, 0 disables the timeout
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// call returns the result of fn, or the context error once the context is done.
+// The client timeout is applied if the context has no deadline, API errors are wrapped in the typed errors.
+// client-go does not accept a context, a request that is still in flight when the context is done
+// completes in the background and is cancelled by the HTTP timeout of the long-lived clients
+func (c *Client) call(ctx context.Context, kind string, namespace string, name string, fn func() (interface{}, error)) (interface{}, error) {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type result struct {
+		obj interface{}
+		err error
+	}
+	// buffered, so that the goroutine does not block if the call is abandoned
+	resultCh := make(chan result, 1)
+	go func() {
+		obj, err := fn()
+		resultCh <- result{obj: obj, err: err}
+	}()
+	select {
+	case r := <-resultCh:
+		if r.err != nil {
+			return nil, wrapError(r.err, kind, namespace, name)
+		}
+		return r.obj, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// callObject is call for a function returning a single resource
+func (c *Client) callObject(ctx context.Context, kind string, namespace string, name string, fn func() (*unstructured.Unstructured, error)) (*unstructured.Unstructured, error) {
+	obj, err := c.call(ctx, kind, namespace, name, func() (interface{}, error) {
+		return fn()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*unstructured.Unstructured), nil
+}
+
 // GetResource returns the resource in unstructured/json format
-func (c *Client) GetResource(kind string, namespace string, name string, subresources ...string) (*unstructured.Unstructured, error) {
-	return c.getResourceInterface(kind, namespace).Get(name, meta.GetOptions{}, subresources...)
+func (c *Client) GetResource(ctx context.Context, kind string, namespace string, name string, subresources ...string) (*unstructured.Unstructured, error) {
+	return c.callObject(ctx, kind, namespace, name, func() (*unstructured.Unstructured, error) {
+		return c.getResourceInterface(kind, namespace).Get(name, meta.GetOptions{}, subresources...)
+	})
+}
+
+//PatchResource patches the resource with a JSON patch (RFC 6902), a JSON merge patch (RFC 7386)
+// or a strategic merge patch, only the fields in the patch are changed.
+// Strategic merge patches are only supported for built-in kinds, custom resources must use a JSON or merge patch.
+// Use ApplyResource for server-side apply
+func (c *Client) PatchResource(ctx context.Context, kind string, namespace string, name string, patchType patchTypes.PatchType, patch []byte, dryRun bool) (*unstructured.Unstructured, error) {
+	switch patchType {
+	case patchTypes.JSONPatchType, patchTypes.MergePatchType, patchTypes.StrategicMergePatchType:
+	default:
+		return nil, fmt.Errorf("Unable to patch resource: unsupported patch type %s", patchType)
+	}
+	options := meta.PatchOptions{}
+	if dryRun {
+		options = meta.PatchOptions{DryRun: []string{meta.DryRunAll}}
+	}
+	return c.callObject(ctx, kind, namespace, name, func() (*unstructured.Unstructured, error) {
+		return c.getResourceInterface(kind, namespace).Patch(name, patchType, patch, options)
+	})
+}
+
+// ListOptions selects the resources to list, the filtering is done by the API server
+type ListOptions struct {
+	LabelSelector *meta.LabelSelector
+	// e.g. fields.OneTermEqualSelector("metadata.name", name)
+	FieldSelector fields.Selector
+	// maximum number of items to return, the remaining items are listed with the continue token of the returned list
+	Limit    int64
+	Continue string
+	// "" lists the most recent version from etcd, "0" allows the API server to serve the list from its cache
+	ResourceVersion string
 }
 
-//PatchResource patches the resource
-func (c *Client) PatchResource(kind string, namespace string, name string, patch []byte) (*unstructured.Unstructured, error) {
-	return c.getResourceInterface(kind, namespace).Patch(name, patchTypes.JSONPatchType, patch, meta.PatchOptions{})
+func (o ListOptions) toListOptions() meta.ListOptions {
+	options := meta.ListOptions{
+		Limit:           o.Limit,
+		Continue:        o.Continue,
+		ResourceVersion: o.ResourceVersion,
+	}
+	if o.LabelSelector != nil {
+		options.LabelSelector = helperv1.FormatLabelSelector(o.LabelSelector)
+	}
+	if o.FieldSelector != nil {
+		options.FieldSelector = o.FieldSelector.String()
+	}
+	return options
 }
 
 // ListResource returns the list of resources in unstructured/json format
 // Access items using []Items
-func (c *Client) ListResource(kind string, namespace string, lselector *meta.LabelSelector) (*unstructured.UnstructuredList, error) {
-	options := meta.ListOptions{}
-	if lselector != nil {
-		options = meta.ListOptions{LabelSelector: helperv1.FormatLabelSelector(lselector)}
+func (c *Client) ListResource(ctx context.Context, kind string, namespace string, options ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := c.call(ctx, kind, namespace, "", func() (interface{}, error) {
+		return c.getResourceInterface(kind, namespace).List(options.toListOptions())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.(*unstructured.UnstructuredList), nil
+}
+
+// ListResourceInPages lists the resources using limit/continue and calls the handler on each page
+// before the next page is fetched, so only a single page is held in memory at a time
+// the page size defaults to DefaultPageSize if the limit is not set
+func (c *Client) ListResourceInPages(ctx context.Context, kind string, namespace string, options ListOptions, handler func(*unstructured.UnstructuredList) error) error {
+	if options.Limit == 0 {
+		options.Limit = DefaultPageSize
+	}
+	for {
+		list, err := c.ListResource(ctx, kind, namespace, options)
+		if err != nil {
+			return err
+		}
+		if err := handler(list); err != nil {
+			return err
+		}
+		// last page
+		if list.GetContinue() == "" {
+			return nil
+		}
+		options.Continue = list.GetContinue()
+		// the continue token already pins the resource version of the first page
+		options.ResourceVersion = ""
 	}
-	return c.getResourceInterface(kind, namespace).List(options)
 }
 
 // DeleteResource deletes the specified resource
-func (c *Client) DeleteResource(kind string, namespace string, name string, dryRun bool) error {
+func (c *Client) DeleteResource(ctx context.Context, kind string, namespace string, name string, dryRun bool) error {
 	options := meta.DeleteOptions{}
 	if dryRun {
 		options = meta.DeleteOptions{DryRun: []string{meta.DryRunAll}}
 	}
-	return c.getResourceInterface(kind, namespace).Delete(name, &options)
-
+	_, err := c.call(ctx, kind, namespace, name, func() (interface{}, error) {
+		return nil, c.getResourceInterface(kind, namespace).Delete(name, &options)
+	})
+	return err
 }
 
 // CreateResource creates object for the specified resource/namespace
-func (c *Client) CreateResource(kind string, namespace string, obj interface{}, dryRun bool) (*unstructured.Unstructured, error) {
+func (c *Client) CreateResource(ctx context.Context, kind string, namespace string, obj interface{}, dryRun bool) (*unstructured.Unstructured, error) {
 	options := meta.CreateOptions{}
 	if dryRun {
 		options = meta.CreateOptions{DryRun: []string{meta.DryRunAll}}
 	}
 	// convert typed to unstructured obj
 	if unstructuredObj := convertToUnstructured(obj); unstructuredObj != nil {
-		return c.getResourceInterface(kind, namespace).Create(unstructuredObj, options)
+		return c.callObject(ctx, kind, namespace, unstructuredObj.GetName(), func() (*unstructured.Unstructured, error) {
+			return c.getResourceInterface(kind, namespace).Create(unstructuredObj, options)
+		})
 	}
 	return nil, fmt.Errorf("Unable to create resource ")
 }
 
 // UpdateResource updates object for the specified resource/namespace
-func (c *Client) UpdateResource(kind string, namespace string, obj interface{}, dryRun bool) (*unstructured.Unstructured, error) {
+func (c *Client) UpdateResource(ctx context.Context, kind string, namespace string, obj interface{}, dryRun bool) (*unstructured.Unstructured, error) {
 	options := meta.UpdateOptions{}
 	if dryRun {
 		options = meta.UpdateOptions{DryRun: []string{meta.DryRunAll}}
 	}
 	// convert typed to unstructured obj
 	if unstructuredObj := convertToUnstructured(obj); unstructuredObj != nil {
-		return c.getResourceInterface(kind, namespace).Update(unstructuredObj, options)
+		return c.callObject(ctx, kind, namespace, unstructuredObj.GetName(), func() (*unstructured.Unstructured, error) {
+			return c.getResourceInterface(kind, namespace).Update(unstructuredObj, options)
+		})
 	}
 	return nil, fmt.Errorf("Unable to update resource ")
 }
 
+// ApplyResource creates or updates the object for the specified resource/namespace using server-side apply
+// fields managed by other field managers cause a conflict error, unless force is set to take over their ownership
+// apiVersion and kind are set from the discovered resource if they are missing in the object
+func (c *Client) ApplyResource(ctx context.Context, kind string, namespace string, name string, obj interface{}, force bool, dryRun bool) (*unstructured.Unstructured, error) {
+	options := meta.PatchOptions{
+		FieldManager: c.fieldManager,
+		Force:        &force,
+	}
+	if dryRun {
+		options.DryRun = []string{meta.DryRunAll}
+	}
+	// convert typed to unstructured obj
+	unstructuredObj := convertToUnstructured(obj)
+	if unstructuredObj == nil {
+		return nil, fmt.Errorf("Unable to apply resource ")
+	}
+	if unstructuredObj.GetKind() == "" {
+		unstructuredObj.SetKind(kind)
+	}
+	if unstructuredObj.GetAPIVersion() == "" {
+		unstructuredObj.SetAPIVersion(c.getGroupVersionMapper(kind).GroupVersion().String())
+	}
+	unstructuredObj.SetName(name)
+	if namespace != "" {
+		unstructuredObj.SetNamespace(namespace)
+	}
+	data, err := unstructuredObj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return c.callObject(ctx, kind, namespace, name, func() (*unstructured.Unstructured, error) {
+		return c.getResourceInterface(kind, namespace).Patch(name, patchTypes.ApplyPatchType, data, options)
+	})
+}
+
+// SetFieldManager sets the field manager used for server-side apply
+func (c *Client) SetFieldManager(fieldManager string) {
+	c.fieldManager = fieldManager
+}
+
 // UpdateStatusResource updates the resource "status" subresource
-func (c *Client) UpdateStatusResource(kind string, namespace string, obj interface{}, dryRun bool) (*unstructured.Unstructured, error) {
+func (c *Client) UpdateStatusResource(ctx context.Context, kind string, namespace string, obj interface{}, dryRun bool) (*unstructured.Unstructured, error) {
 	options := meta.UpdateOptions{}
 	if dryRun {
 		options = meta.UpdateOptions{DryRun: []string{meta.DryRunAll}}
 	}
 	// convert typed to unstructured obj
 	if unstructuredObj := convertToUnstructured(obj); unstructuredObj != nil {
-		return c.getResourceInterface(kind, namespace).UpdateStatus(unstructuredObj, options)
+		return c.callObject(ctx, kind, namespace, unstructuredObj.GetName(), func() (*unstructured.Unstructured, error) {
+			return c.getResourceInterface(kind, namespace).UpdateStatus(unstructuredObj, options)
+		})
 	}
 	return nil, fmt.Errorf("Unable to update resource ")
 }
@@ -216,6 +439,8 @@ func convertToCSR(obj *unstructured.Unstructured) (*certificates.CertificateSign
 //IDiscovery provides interface to mange Kind and GVR mapping
 type IDiscovery interface {
 	GetGVRFromKind(kind string) schema.GroupVersionResource
+	// IsNamespaced returns true if the resources of the kind are namespaced, false if they are cluster-wide
+	IsNamespaced(kind string) (bool, error)
 	GetServerVersion() (*version.Info, error)
 	OpenAPISchema() (*openapi_v2.Document, error)
 }
@@ -249,6 +474,30 @@ func (c ServerPreferredResources) Poll(resync time.Duration, stopCh <-chan struc
 	}
 }
 
+// crdEventHandler invalidates the local cache when a CRD is added, changed or removed,
+// so that the kind of a custom resource is resolved without waiting for the next poll
+func (c ServerPreferredResources) crdEventHandler() cache.ResourceEventHandler {
+	invalidate := func(obj interface{}) {
+		if crd, ok := obj.(*unstructured.Unstructured); ok {
+			glog.V(4).Infof("CRD %s changed, invalidating local client cache for registered resources", crd.GetName())
+		}
+		c.cachedClient.Invalidate()
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: invalidate,
+		UpdateFunc: func(old, cur interface{}) {
+			oldCRD, oldOk := old.(*unstructured.Unstructured)
+			curCRD, curOk := cur.(*unstructured.Unstructured)
+			// informer resync does not change the CRD
+			if oldOk && curOk && oldCRD.GetResourceVersion() == curCRD.GetResourceVersion() {
+				return
+			}
+			invalidate(cur)
+		},
+		DeleteFunc: invalidate,
+	}
+}
+
 func (c ServerPreferredResources) OpenAPISchema() (*openapi_v2.Document, error) {
 	return c.cachedClient.OpenAPISchema()
 }
@@ -279,12 +528,34 @@ func (c ServerPreferredResources) GetServerVersion() (*version.Info, error) {
 	return c.cachedClient.ServerVersion()
 }
 
+//IsNamespaced returns true if the resources of the kind are namespaced,
+// the cache is invalidated and the discovery retried once if the kind is not found
+func (c ServerPreferredResources) IsNamespaced(kind string) (bool, error) {
+	_, resource, err := loadServerResource(kind, c.cachedClient)
+	if err != nil && !c.cachedClient.Fresh() {
+		c.cachedClient.Invalidate()
+		_, resource, err = loadServerResource(kind, c.cachedClient)
+	}
+	if err != nil {
+		return false, err
+	}
+	return resource.Namespaced, nil
+}
+
 func loadServerResources(k string, cdi discovery.CachedDiscoveryInterface) (schema.GroupVersionResource, error) {
+	gv, resource, err := loadServerResource(k, cdi)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return gv.WithResource(resource.Name), nil
+}
+
+// loadServerResource returns the preferred group version and the API resource of the kind
+func loadServerResource(k string, cdi discovery.CachedDiscoveryInterface) (schema.GroupVersion, meta.APIResource, error) {
 	serverresources, err := cdi.ServerPreferredResources()
-	emptyGVR := schema.GroupVersionResource{}
 	if err != nil {
 		glog.Error(err)
-		return emptyGVR, err
+		return schema.GroupVersion{}, meta.APIResource{}, err
 	}
 	for _, serverresource := range serverresources {
 		for _, resource := range serverresource.APIResources {
@@ -294,11 +565,11 @@ func loadServerResources(k string, cdi discovery.CachedDiscoveryInterface) (sche
 				gv, err := schema.ParseGroupVersion(serverresource.GroupVersion)
 				if err != nil {
 					glog.Error(err)
-					return emptyGVR, err
+					return schema.GroupVersion{}, meta.APIResource{}, err
 				}
-				return gv.WithResource(resource.Name), nil
+				return gv, resource, nil
 			}
 		}
 	}
-	return emptyGVR, fmt.Errorf("kind '%s' not found", k)
+	return schema.GroupVersion{}, meta.APIResource{}, fmt.Errorf("kind '%s' not found", k)
 }
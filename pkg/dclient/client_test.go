@@ -1,11 +1,27 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 // GetResource
@@ -62,37 +78,239 @@ func newFixture(t *testing.T) *fixture {
 func TestCRUDResource(t *testing.T) {
 	f := newFixture(t)
 	// Get Resource
-	_, err := f.client.GetResource("thekind", "ns-foo", "name-foo")
+	_, err := f.client.GetResource(context.TODO(), "thekind", "ns-foo", "name-foo")
 	if err != nil {
 		t.Errorf("GetResource not working: %s", err)
 	}
 	// List Resources
-	_, err = f.client.ListResource("thekind", "ns-foo", nil)
+	_, err = f.client.ListResource(context.TODO(), "thekind", "ns-foo", ListOptions{})
 	if err != nil {
 		t.Errorf("ListResource not working: %s", err)
 	}
 	// DeleteResouce
-	err = f.client.DeleteResource("thekind", "ns-foo", "name-bar", false)
+	err = f.client.DeleteResource(context.TODO(), "thekind", "ns-foo", "name-bar", false)
 	if err != nil {
 		t.Errorf("DeleteResouce not working: %s", err)
 	}
 	// CreateResource
-	_, err = f.client.CreateResource("thekind", "ns-foo", newUnstructured("group/version", "TheKind", "ns-foo", "name-foo1"), false)
+	_, err = f.client.CreateResource(context.TODO(), "thekind", "ns-foo", newUnstructured("group/version", "TheKind", "ns-foo", "name-foo1"), false)
 	if err != nil {
 		t.Errorf("CreateResource not working: %s", err)
 	}
 	//	UpdateResource
-	_, err = f.client.UpdateResource("thekind", "ns-foo", newUnstructuredWithSpec("group/version", "TheKind", "ns-foo", "name-foo1", map[string]interface{}{"foo": "bar"}), false)
+	_, err = f.client.UpdateResource(context.TODO(), "thekind", "ns-foo", newUnstructuredWithSpec("group/version", "TheKind", "ns-foo", "name-foo1", map[string]interface{}{"foo": "bar"}), false)
 	if err != nil {
 		t.Errorf("UpdateResource not working: %s", err)
 	}
 	// UpdateStatusResource
-	_, err = f.client.UpdateStatusResource("thekind", "ns-foo", newUnstructuredWithSpec("group/version", "TheKind", "ns-foo", "name-foo1", map[string]interface{}{"foo": "status"}), false)
+	_, err = f.client.UpdateStatusResource(context.TODO(), "thekind", "ns-foo", newUnstructuredWithSpec("group/version", "TheKind", "ns-foo", "name-foo1", map[string]interface{}{"foo": "status"}), false)
 	if err != nil {
 		t.Errorf("UpdateStatusResource not working: %s", err)
 	}
 }
 
+// pagingDynamicClient serves the lists of the fake dynamic client in pages of options.Limit items,
+// the continue token is the offset of the next page
+type pagingDynamicClient struct {
+	dynamic.Interface
+	continueTokens []string
+}
+
+func (c *pagingDynamicClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return pagingResource{NamespaceableResourceInterface: c.Interface.Resource(resource), client: c}
+}
+
+type pagingResource struct {
+	dynamic.NamespaceableResourceInterface
+	client *pagingDynamicClient
+}
+
+func (r pagingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return pagingNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace), client: r.client}
+}
+
+type pagingNamespacedResource struct {
+	dynamic.ResourceInterface
+	client *pagingDynamicClient
+}
+
+func (r pagingNamespacedResource) List(opts meta.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.client.continueTokens = append(r.client.continueTokens, opts.Continue)
+	list, err := r.ResourceInterface.List(meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	var offset int
+	if opts.Continue != "" {
+		if offset, err = strconv.Atoi(opts.Continue); err != nil || offset > len(list.Items) {
+			return nil, errors.NewBadRequest("invalid continue token")
+		}
+	}
+	end := len(list.Items)
+	if opts.Limit > 0 && offset+int(opts.Limit) < end {
+		end = offset + int(opts.Limit)
+		list.SetContinue(strconv.Itoa(end))
+	}
+	list.Items = list.Items[offset:end]
+	return list, nil
+}
+
+func TestListResourceInPages(t *testing.T) {
+	f := newFixture(t)
+	pagingClient := &pagingDynamicClient{Interface: f.client.client}
+	f.client.client = pagingClient
+	var pages [][]string
+	err := f.client.ListResourceInPages(context.TODO(), "thekind", "ns-foo", ListOptions{Limit: 2}, func(list *unstructured.UnstructuredList) error {
+		var names []string
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+		pages = append(pages, names)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ListResourceInPages not working: %s", err)
+	}
+	expectedPages := [][]string{{"name-bar", "name-baz"}, {"name-foo"}}
+	if !reflect.DeepEqual(pages, expectedPages) {
+		t.Errorf("expected pages %v, got %v", expectedPages, pages)
+	}
+	if expectedTokens := []string{"", "2"}; !reflect.DeepEqual(pagingClient.continueTokens, expectedTokens) {
+		t.Errorf("expected continue tokens %v, got %v", expectedTokens, pagingClient.continueTokens)
+	}
+
+	// an error of the handler stops the listing
+	pagingClient.continueTokens = nil
+	err = f.client.ListResourceInPages(context.TODO(), "thekind", "ns-foo", ListOptions{Limit: 1}, func(list *unstructured.UnstructuredList) error {
+		return fmt.Errorf("failed")
+	})
+	if err == nil || len(pagingClient.continueTokens) != 1 {
+		t.Errorf("expected the listing to stop after the first page, got %v and %d pages", err, len(pagingClient.continueTokens))
+	}
+}
+
+func TestListOptions(t *testing.T) {
+	options := ListOptions{
+		LabelSelector:   &meta.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", "name-foo"),
+		Limit:           10,
+		ResourceVersion: "0",
+	}.toListOptions()
+	if options.LabelSelector != "app=foo" || options.FieldSelector != "metadata.name=name-foo" || options.Limit != 10 || options.ResourceVersion != "0" {
+		t.Errorf("unexpected list options %v", options)
+	}
+}
+
+func TestApplyResource(t *testing.T) {
+	f := newFixture(t)
+	var patchAction clienttesting.PatchActionImpl
+	f.client.client.(*fake.FakeDynamicClient).PrependReactor("patch", "thekinds", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction = action.(clienttesting.PatchActionImpl)
+		return true, newUnstructured("group/version", "TheKind", "ns-foo", "name-foo"), nil
+	})
+	_, err := f.client.ApplyResource(context.TODO(), "TheKind", "ns-foo", "name-foo", &unstructured.Unstructured{Object: map[string]interface{}{}}, false, false)
+	if err != nil {
+		t.Errorf("ApplyResource not working: %s", err)
+	}
+	if patchAction.PatchType != types.ApplyPatchType {
+		t.Errorf("expected patch type %s, got %s", types.ApplyPatchType, patchAction.PatchType)
+	}
+	applied := &unstructured.Unstructured{}
+	if err := applied.UnmarshalJSON(patchAction.Patch); err != nil {
+		t.Fatal(err)
+	}
+	if applied.GetAPIVersion() != "group/version" || applied.GetKind() != "TheKind" || applied.GetName() != "name-foo" || applied.GetNamespace() != "ns-foo" {
+		t.Errorf("unexpected applied object %v", applied.Object)
+	}
+}
+
+func TestPatchResource(t *testing.T) {
+	f := newFixture(t)
+	// JSON patch
+	obj, err := f.client.PatchResource(context.TODO(), "thekind", "ns-foo", "name-foo", types.JSONPatchType, []byte(`[{"op":"add","path":"/metadata/labels","value":{"a":"b"}}]`), false)
+	if err != nil {
+		t.Fatalf("PatchResource not working: %s", err)
+	}
+	if obj.GetLabels()["a"] != "b" {
+		t.Errorf("expected label a=b, got %v", obj.GetLabels())
+	}
+	// merge patch, only changes the fields in the patch
+	obj, err = f.client.PatchResource(context.TODO(), "thekind", "ns-foo", "name-foo", types.MergePatchType, []byte(`{"metadata":{"labels":{"c":"d"}}}`), false)
+	if err != nil {
+		t.Fatalf("PatchResource not working: %s", err)
+	}
+	if obj.GetLabels()["a"] != "b" || obj.GetLabels()["c"] != "d" {
+		t.Errorf("expected labels a=b and c=d, got %v", obj.GetLabels())
+	}
+	// server-side apply goes through ApplyResource
+	_, err = f.client.PatchResource(context.TODO(), "thekind", "ns-foo", "name-foo", types.ApplyPatchType, []byte(`{}`), false)
+	if err == nil {
+		t.Errorf("expected apply patch type to be rejected")
+	}
+}
+
+type fakeCachedDiscovery struct {
+	discovery.CachedDiscoveryInterface
+	invalidations int
+}
+
+func (c *fakeCachedDiscovery) Invalidate() {
+	c.invalidations++
+}
+
+func TestCRDEventHandler(t *testing.T) {
+	cachedClient := &fakeCachedDiscovery{}
+	handler := ServerPreferredResources{cachedClient: cachedClient}.crdEventHandler()
+
+	crd := newUnstructured("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "", "thekinds.group")
+	crd.SetResourceVersion("1")
+	handler.OnAdd(crd)
+	if cachedClient.invalidations != 1 {
+		t.Errorf("expected the cache to be invalidated when a CRD is added")
+	}
+	// resync
+	handler.OnUpdate(crd, crd)
+	if cachedClient.invalidations != 1 {
+		t.Errorf("expected the cache not to be invalidated on resync")
+	}
+	established := crd.DeepCopy()
+	established.SetResourceVersion("2")
+	handler.OnUpdate(crd, established)
+	if cachedClient.invalidations != 2 {
+		t.Errorf("expected the cache to be invalidated when a CRD is updated")
+	}
+	handler.OnDelete(established)
+	if cachedClient.invalidations != 3 {
+		t.Errorf("expected the cache to be invalidated when a CRD is deleted")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	f := newFixture(t)
+	f.client.SetTimeout(10 * time.Millisecond)
+	unblock := make(chan struct{})
+	defer close(unblock)
+	f.client.client.(*fake.FakeDynamicClient).PrependReactor("get", "thekinds", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		// hung API call
+		<-unblock
+		return true, nil, nil
+	})
+	_, err := f.client.GetResource(context.TODO(), "thekind", "ns-foo", "name-foo")
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the call to time out, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = f.client.GetResource(ctx, "thekind", "ns-foo", "name-foo")
+	if err != context.Canceled {
+		t.Errorf("expected the call to be canceled, got %v", err)
+	}
+}
+
 func TestEventInterface(t *testing.T) {
 	f := newFixture(t)
 	iEvent, err := f.client.GetEventsInterface()
@@ -118,8 +336,35 @@ func TestCSRInterface(t *testing.T) {
 
 func TestKubePolicyDeployment(t *testing.T) {
 	f := newFixture(t)
-	_, err := f.client.GetKubePolicyDeployment()
+	_, err := f.client.GetKubePolicyDeployment(context.TODO())
 	if err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestIsNamespaced(t *testing.T) {
+	fakeDiscovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*meta.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []meta.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Namespaced: false},
+				{Name: "pods", Kind: "Pod", Namespaced: true},
+				{Name: "pods/status", Kind: "Pod", Namespaced: true},
+			},
+		},
+	}}}
+	discoveryClient := ServerPreferredResources{memory.NewMemCacheClient(fakeDiscovery)}
+
+	for kind, expected := range map[string]bool{"Namespace": false, "Pod": true} {
+		namespaced, err := discoveryClient.IsNamespaced(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if namespaced != expected {
+			t.Errorf("kind %s: expected namespaced %v, got %v", kind, expected, namespaced)
+		}
+	}
+	if _, err := discoveryClient.IsNamespaced("Unknown"); err == nil {
+		t.Errorf("expected an error for an unknown kind")
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// The errors returned by the client for the common API error reasons.
+// They embed the API status, so the apimachinery helpers like errors.IsNotFound keep working,
+// callers can switch on the error type instead:
+//	switch err.(type) {
+//	case *client.NotFound:
+//	case *client.Conflict:
+//	}
+
+// resourceError identifies the resource of the failed call
+type resourceError struct {
+	*apierrors.StatusError
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (e *resourceError) resource() string {
+	return fmt.Sprintf("%s/%s/%s", e.Kind, e.Namespace, e.Name)
+}
+
+// Unwrap returns the API status error
+func (e *resourceError) Unwrap() error {
+	return e.StatusError
+}
+
+// NotFound is returned if the resource does not exist
+type NotFound struct {
+	resourceError
+}
+
+func (e *NotFound) Error() string {
+	return fmt.Sprintf("resource %s not found", e.resource())
+}
+
+// Conflict is returned if the resource was modified since it was read, or already exists
+type Conflict struct {
+	resourceError
+}
+
+func (e *Conflict) Error() string {
+	return fmt.Sprintf("conflict on resource %s: %s", e.resource(), e.StatusError.Error())
+}
+
+// Forbidden is returned if the client is not allowed to access the resource
+type Forbidden struct {
+	resourceError
+}
+
+func (e *Forbidden) Error() string {
+	return fmt.Sprintf("access to resource %s forbidden: %s", e.resource(), e.StatusError.Error())
+}
+
+// Throttled is returned if the API server rejected the call with too many requests
+type Throttled struct {
+	resourceError
+	// seconds to wait before the next call, as suggested by the API server
+	RetryAfterSeconds int
+}
+
+func (e *Throttled) Error() string {
+	return fmt.Sprintf("request for resource %s throttled, retry after %d seconds", e.resource(), e.RetryAfterSeconds)
+}
+
+// wrapError returns the typed error for the API errors with a common reason,
+// other errors are returned as is
+func wrapError(err error, kind string, namespace string, name string) error {
+	statusErr, ok := err.(*apierrors.StatusError)
+	if !ok {
+		return err
+	}
+	rerr := resourceError{StatusError: statusErr, Kind: kind, Namespace: namespace, Name: name}
+	switch {
+	case apierrors.IsNotFound(err):
+		return &NotFound{rerr}
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return &Conflict{rerr}
+	case apierrors.IsForbidden(err):
+		return &Forbidden{rerr}
+	case apierrors.IsTooManyRequests(err):
+		retryAfter, _ := apierrors.SuggestsClientDelay(err)
+		return &Throttled{resourceError: rerr, RetryAfterSeconds: retryAfter}
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWrapError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "configmaps"}
+
+	err := wrapError(apierrors.NewNotFound(gr, "cm-foo"), ConfigMaps, "ns-foo", "cm-foo")
+	if _, ok := err.(*NotFound); !ok {
+		t.Errorf("expected NotFound, got %T", err)
+	}
+	// the API status is preserved
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the error to be recognized as not found")
+	}
+	if err.Error() != "resource ConfigMap/ns-foo/cm-foo not found" {
+		t.Errorf("unexpected error message %s", err.Error())
+	}
+
+	err = wrapError(apierrors.NewConflict(gr, "cm-foo", errors.New("modified")), ConfigMaps, "ns-foo", "cm-foo")
+	if _, ok := err.(*Conflict); !ok || !apierrors.IsConflict(err) {
+		t.Errorf("expected Conflict, got %T", err)
+	}
+	err = wrapError(apierrors.NewAlreadyExists(gr, "cm-foo"), ConfigMaps, "ns-foo", "cm-foo")
+	if _, ok := err.(*Conflict); !ok {
+		t.Errorf("expected Conflict, got %T", err)
+	}
+	err = wrapError(apierrors.NewForbidden(gr, "cm-foo", errors.New("denied")), ConfigMaps, "ns-foo", "cm-foo")
+	if _, ok := err.(*Forbidden); !ok || !apierrors.IsForbidden(err) {
+		t.Errorf("expected Forbidden, got %T", err)
+	}
+	err = wrapError(apierrors.NewTooManyRequests("throttled", 5), ConfigMaps, "ns-foo", "cm-foo")
+	throttled, ok := err.(*Throttled)
+	if !ok {
+		t.Fatalf("expected Throttled, got %T", err)
+	}
+	if throttled.RetryAfterSeconds != 5 || !IsRetriable(err) {
+		t.Errorf("expected a retriable error with retry after 5 seconds, got %v", err)
+	}
+
+	// other errors are not wrapped
+	invalid := apierrors.NewBadRequest("invalid")
+	if wrapError(invalid, ConfigMaps, "ns-foo", "cm-foo") != invalid {
+		t.Errorf("expected the bad request error not to be wrapped")
+	}
+}
+
+func TestGetResource_NotFound(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.client.GetResource(context.TODO(), "thekind", "ns-foo", "name-missing")
+	notFound, ok := err.(*NotFound)
+	if !ok {
+		t.Fatalf("expected NotFound, got %T: %v", err, err)
+	}
+	if notFound.Kind != "thekind" || notFound.Namespace != "ns-foo" || notFound.Name != "name-missing" {
+		t.Errorf("unexpected resource %s", notFound.resource())
+	}
+}
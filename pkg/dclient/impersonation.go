@@ -0,0 +1,59 @@
+package client
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// serviceAccountUsernamePrefix is the prefix of the user name of service accounts
+const serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+// Impersonate returns a client that sends the requests as the impersonated user, groups and extra attributes,
+// the API server authorizes the requests against the permissions of the impersonated user.
+// The client shares the discovery cache, the rate limiter, the field manager and the timeout with c, the kyverno
+// service account requires the "impersonate" verb on the impersonated users, groups and service accounts.
+// The impersonated client does not read from the informer caches of c, which are filled with the kyverno permissions
+func (c *Client) Impersonate(impersonate rest.ImpersonationConfig) (*Client, error) {
+	if c.clientConfig == nil {
+		return nil, fmt.Errorf("Unable to impersonate %s: client is not created from a rest config", impersonate.UserName)
+	}
+	config := rest.CopyConfig(c.clientConfig)
+	config.Impersonate = impersonate
+	dclient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	kclient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		client:          dclient,
+		clientConfig:    config,
+		kclient:         kclient,
+		informerClient:  dclient,
+		DiscoveryClient: c.DiscoveryClient,
+		fieldManager:    c.fieldManager,
+		timeout:         c.timeout,
+	}, nil
+}
+
+// ImpersonateServiceAccount returns a client that sends the requests as the service account
+func (c *Client) ImpersonateServiceAccount(namespace string, name string) (*Client, error) {
+	return c.Impersonate(rest.ImpersonationConfig{
+		UserName: ServiceAccountUsername(namespace, name),
+		Groups: []string{
+			"system:serviceaccounts",
+			"system:serviceaccounts:" + namespace,
+			"system:authenticated",
+		},
+	})
+}
+
+// ServiceAccountUsername returns the user name of the service account, i.e. system:serviceaccount:<namespace>:<name>
+func ServiceAccountUsername(namespace string, name string) string {
+	return serviceAccountUsernamePrefix + namespace + ":" + name
+}
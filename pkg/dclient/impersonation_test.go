@@ -0,0 +1,41 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+func TestImpersonateServiceAccount(t *testing.T) {
+	c := &Client{
+		clientConfig: &rest.Config{Host: "https://localhost:6443", RateLimiter: flowcontrol.NewFakeAlwaysRateLimiter()},
+		fieldManager: DefaultFieldManager,
+	}
+	impersonated, err := c.ImpersonateServiceAccount("ns-foo", "sa-foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impersonated.clientConfig.Impersonate.UserName != "system:serviceaccount:ns-foo:sa-foo" {
+		t.Errorf("unexpected impersonated user %s", impersonated.clientConfig.Impersonate.UserName)
+	}
+	groups := []string{"system:serviceaccounts", "system:serviceaccounts:ns-foo", "system:authenticated"}
+	if !reflect.DeepEqual(impersonated.clientConfig.Impersonate.Groups, groups) {
+		t.Errorf("unexpected impersonated groups %v", impersonated.clientConfig.Impersonate.Groups)
+	}
+	if impersonated.clientConfig.RateLimiter != c.clientConfig.RateLimiter {
+		t.Errorf("expected the impersonated client to share the rate limiter")
+	}
+	// the original client is not modified
+	if c.clientConfig.Impersonate.UserName != "" {
+		t.Errorf("expected the original client not to impersonate")
+	}
+}
+
+func TestImpersonate_MockClient(t *testing.T) {
+	f := newFixture(t)
+	if _, err := f.client.Impersonate(rest.ImpersonationConfig{UserName: "foo"}); err == nil {
+		t.Errorf("expected an error for a client without rest config")
+	}
+}
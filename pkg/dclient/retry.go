@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	backoff "github.com/cenkalti/backoff"
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// RetryConfig configures the exponential backoff used to retry failed API calls
+type RetryConfig struct {
+	// wait before the first retry
+	InitialInterval time.Duration
+	// upper bound of the wait between two retries
+	MaxInterval time.Duration
+	// stop retrying once this much time has passed since the first call
+	MaxElapsedTime time.Duration
+	// factor applied to the wait after each retry
+	Multiplier float64
+}
+
+// DefaultRetryConfig retries for up to 3 seconds, with waits growing from 500ms to 1s
+var DefaultRetryConfig = RetryConfig{
+	InitialInterval: 500 * time.Millisecond,
+	MaxInterval:     time.Second,
+	MaxElapsedTime:  3 * time.Second,
+	Multiplier:      1.5,
+}
+
+func (rc RetryConfig) backOff() *backoff.ExponentialBackOff {
+	exbackoff := &backoff.ExponentialBackOff{
+		InitialInterval:     rc.InitialInterval,
+		RandomizationFactor: 0.5,
+		Multiplier:          rc.Multiplier,
+		MaxInterval:         rc.MaxInterval,
+		MaxElapsedTime:      rc.MaxElapsedTime,
+		Clock:               backoff.SystemClock,
+	}
+	exbackoff.Reset()
+	return exbackoff
+}
+
+// IsRetriable returns true if the error is transient and the call may succeed when repeated:
+// conflicts, throttling (429), server timeouts, unavailable servers and dropped connections
+func IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsConflict(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) {
+		return true
+	}
+	if _, ok := apierrors.SuggestsClientDelay(err); ok {
+		return true
+	}
+	if err == io.ErrUnexpectedEOF || utilnet.IsProbableEOF(err) || utilnet.IsConnectionReset(err) {
+		return true
+	}
+	if netErr, ok := err.(net.Error); ok {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+	return false
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retriable,
+// the backoff is exhausted or the context is canceled. The last error is returned.
+// On conflicts fn is called again as is, so it must re-read the resource it updates
+func Retry(ctx context.Context, config RetryConfig, fn func() error) error {
+	return RetryOnError(ctx, config, IsRetriable, fn)
+}
+
+// RetryOnError is like Retry, with retriable deciding which errors are retried
+func RetryOnError(ctx context.Context, config RetryConfig, retriable func(error) bool, fn func() error) error {
+	var i int
+	operation := func() error {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !retriable(err) {
+			return backoff.Permanent(err)
+		}
+		glog.V(4).Infof("retry %d: %v", i, err)
+		i++
+		return err
+	}
+	return backoff.Retry(operation, backoff.WithContext(config.backOff(), ctx))
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var testRetryConfig = RetryConfig{
+	InitialInterval: time.Millisecond,
+	MaxInterval:     time.Millisecond,
+	MaxElapsedTime:  time.Second,
+	Multiplier:      1,
+}
+
+func TestIsRetriable(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	testcases := []struct {
+		err       error
+		retriable bool
+	}{
+		{apierrors.NewConflict(gr, "pod", errors.New("modified")), true},
+		{apierrors.NewTooManyRequests("throttled", 1), true},
+		{apierrors.NewServerTimeout(gr, "get", 1), true},
+		{apierrors.NewServiceUnavailable("unavailable"), true},
+		{apierrors.NewNotFound(gr, "pod"), false},
+		{apierrors.NewForbidden(gr, "pod", errors.New("denied")), false},
+		{errors.New("invalid"), false},
+		{nil, false},
+	}
+	for _, tc := range testcases {
+		if IsRetriable(tc.err) != tc.retriable {
+			t.Errorf("expected IsRetriable(%v) to be %v", tc.err, tc.retriable)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	err := Retry(context.Background(), testRetryConfig, func() error {
+		calls++
+		if calls < 3 {
+			return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "pod", errors.New("modified"))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetry_Permanent(t *testing.T) {
+	var calls int
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "pod")
+	err := Retry(context.Background(), testRetryConfig, func() error {
+		calls++
+		return notFound
+	})
+	if err != notFound {
+		t.Errorf("expected the not found error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call, got %d", calls)
+	}
+}
+
+func TestRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	err := Retry(ctx, testRetryConfig, func() error {
+		calls++
+		return apierrors.NewTooManyRequests("throttled", 1)
+	})
+	if !apierrors.IsTooManyRequests(err) {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call, got %d", calls)
+	}
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CloneSourceLabel selects the config maps served from the informer cache, e.g. the clone sources of
+// generate rules. The other config maps are read from the API server, so that kyverno does not hold
+// all the config maps of the cluster in memory
+const CloneSourceLabel = "generate.kyverno.io/clone-source"
+
+// typedCache serves the hot kinds from informer caches, without the round trip to the API server
+// and the conversion from unstructured
+type typedCache struct {
+	nsLister corelisters.NamespaceLister
+	nsSynced cache.InformerSynced
+	cmLister corelisters.ConfigMapLister
+	cmSynced cache.InformerSynced
+}
+
+// SetTypedCache serves Namespaces and ConfigMaps from the informers once they are synced,
+// the informers must be started by the caller. The config map informer may be filtered,
+// e.g. with CloneSourceLabel, the config maps that are not in its cache are read from the API server.
+// Secrets are not cached, so that kyverno does not need to list and watch all the secrets of the cluster
+func (c *Client) SetTypedCache(nsInformer coreinformers.NamespaceInformer, cmInformer coreinformers.ConfigMapInformer) {
+	c.typedCache = &typedCache{
+		nsLister: nsInformer.Lister(),
+		nsSynced: nsInformer.Informer().HasSynced,
+		cmLister: cmInformer.Lister(),
+		cmSynced: cmInformer.Informer().HasSynced,
+	}
+}
+
+// GetNamespace returns the namespace from the informer cache, or from the API server if the cache is not synced
+// the returned object is shared with the cache and must not be modified
+func (c *Client) GetNamespace(ctx context.Context, name string) (*v1.Namespace, error) {
+	if c.typedCache != nil && c.typedCache.nsSynced() {
+		ns, err := c.typedCache.nsLister.Get(name)
+		if err != nil {
+			return nil, wrapError(err, Namespaces, "", name)
+		}
+		return ns, nil
+	}
+	ns, err := c.call(ctx, Namespaces, "", name, func() (interface{}, error) {
+		return c.kclient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ns.(*v1.Namespace), nil
+}
+
+// GetConfigMap returns the config map from the informer cache, or from the API server if the cache is not synced
+// or does not hold the config map. The returned object is shared with the cache and must not be modified
+func (c *Client) GetConfigMap(ctx context.Context, namespace string, name string) (*v1.ConfigMap, error) {
+	if c.typedCache != nil && c.typedCache.cmSynced() {
+		if cm, err := c.typedCache.cmLister.ConfigMaps(namespace).Get(name); err == nil {
+			return cm, nil
+		}
+	}
+	cm, err := c.call(ctx, ConfigMaps, namespace, name, func() (interface{}, error) {
+		return c.kclient.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cm.(*v1.ConfigMap), nil
+}
+
+// GetSecret returns the secret from the API server with the typed client
+func (c *Client) GetSecret(ctx context.Context, namespace string, name string) (*v1.Secret, error) {
+	secret, err := c.call(ctx, Secrets, namespace, name, func() (interface{}, error) {
+		return c.kclient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return secret.(*v1.Secret), nil
+}
+
+// GetCachedResource returns Namespaces, ConfigMaps and Secrets using the typed accessors, which serve
+// the namespaces and the cached config maps from the informers, other kinds are fetched from the API server. The resource may be stale, use GetResource
+// to read the latest version before an update
+func (c *Client) GetCachedResource(ctx context.Context, kind string, namespace string, name string) (*unstructured.Unstructured, error) {
+	var obj runtime.Object
+	var err error
+	switch kind {
+	case Namespaces:
+		obj, err = c.GetNamespace(ctx, name)
+	case ConfigMaps:
+		obj, err = c.GetConfigMap(ctx, namespace, name)
+	case Secrets:
+		obj, err = c.GetSecret(ctx, namespace, name)
+	default:
+		return c.GetResource(ctx, kind, namespace, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	resource := &unstructured.Unstructured{Object: content}
+	// objects in the informer cache do not have the type meta
+	resource.SetAPIVersion("v1")
+	resource.SetKind(kind)
+	return resource, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetCachedResource(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret-foo", Namespace: "ns-foo"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+	uncached := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-bar", Namespace: "ns-foo"}}
+	c := &Client{kclient: kubernetesfake.NewSimpleClientset(secret, uncached), timeout: DefaultTimeout}
+
+	// not cached
+	obj, err := c.GetCachedResource(context.TODO(), Secrets, "ns-foo", "secret-foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "secret-foo" || obj.GetKind() != Secrets || obj.GetAPIVersion() != "v1" {
+		t.Errorf("unexpected secret %v", obj.Object)
+	}
+
+	// served from the informer cache
+	informers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(), 0)
+	c.SetTypedCache(informers.Core().V1().Namespaces(), informers.Core().V1().ConfigMaps())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informers.Start(stopCh)
+	informers.WaitForCacheSync(stopCh)
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-foo", Namespace: "ns-foo"}}
+	if err := informers.Core().V1().ConfigMaps().Informer().GetIndexer().Add(cm); err != nil {
+		t.Fatal(err)
+	}
+	obj, err = c.GetCachedResource(context.TODO(), ConfigMaps, "ns-foo", "cm-foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "cm-foo" || obj.GetKind() != ConfigMaps {
+		t.Errorf("unexpected config map %v", obj.Object)
+	}
+	// the config map is not in the filtered informer cache
+	obj, err = c.GetCachedResource(context.TODO(), ConfigMaps, "ns-foo", "cm-bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "cm-bar" {
+		t.Errorf("unexpected config map %v", obj.Object)
+	}
+	_, err = c.GetCachedResource(context.TODO(), ConfigMaps, "ns-foo", "cm-baz")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	// secrets are always read from the API server
+	obj, err = c.GetCachedResource(context.TODO(), Secrets, "ns-foo", "secret-foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "secret-foo" {
+		t.Errorf("unexpected secret %v", obj.Object)
+	}
+}
+
+func TestGetCachedResource_OtherKinds(t *testing.T) {
+	f := newFixture(t)
+	obj, err := f.client.GetCachedResource(context.TODO(), "thekind", "ns-foo", "name-foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "name-foo" {
+		t.Errorf("unexpected resource %v", obj.Object)
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"fmt"
 	"strings"
 
 	openapi_v2 "github.com/googleapis/gnostic/OpenAPIv2"
@@ -30,8 +31,11 @@ func NewMockClient(scheme *runtime.Scheme, objects ...runtime.Object) (*Client,
 	// the typed and dynamic client are initialized with similar resources
 	kclient := kubernetesfake.NewSimpleClientset(objects...)
 	return &Client{
-		client:  client,
-		kclient: kclient,
+		client:         client,
+		kclient:        kclient,
+		informerClient: client,
+		fieldManager:   DefaultFieldManager,
+		timeout:        DefaultTimeout,
 	}, nil
 
 }
@@ -76,6 +80,24 @@ func (c *fakeDiscoveryClient) GetGVRFromKind(kind string) schema.GroupVersionRes
 	return c.getGVR(resource)
 }
 
+// clusterScopedResources are the cluster-wide resources known to the fake discovery client
+var clusterScopedResources = map[string]bool{
+	"namespaces":                true,
+	"nodes":                     true,
+	"persistentvolumes":         true,
+	"clusterroles":              true,
+	"clusterrolebindings":       true,
+	"customresourcedefinitions": true,
+}
+
+func (c *fakeDiscoveryClient) IsNamespaced(kind string) (bool, error) {
+	resource := strings.ToLower(kind) + "s"
+	if c.getGVR(resource).Resource == "" {
+		return false, fmt.Errorf("kind '%s' not found", kind)
+	}
+	return !clusterScopedResources[resource], nil
+}
+
 func (c *fakeDiscoveryClient) OpenAPISchema() (*openapi_v2.Document, error) {
 	return nil, nil
 }
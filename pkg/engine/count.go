@@ -0,0 +1,79 @@
+package engine
+
+import (
+	gocontext "context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/engine/validate"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// validateCount counts the existing resources of the kind of the resource that match the count pattern,
+// the rule fails if the resource would exceed the limit. It returns nil if the resource is not counted
+func validateCount(policyContext PolicyContext, resource unstructured.Unstructured, rule kyverno.Rule) *response.RuleResponse {
+	startTime := time.Now()
+	count := rule.Validation.Count
+	if !matchesCountPattern(resource, count.Pattern) {
+		glog.V(4).Infof("resource %s/%s/%s does not match the count pattern of rule %s", resource.GetKind(), resource.GetNamespace(), resource.GetName(), rule.Name)
+		return nil
+	}
+
+	resp := &response.RuleResponse{
+		Name: rule.Name,
+		Type: utils.Validation.String(),
+	}
+	defer func() {
+		resp.RuleStats.ProcessingTime = time.Since(startTime)
+	}()
+
+	namespace := resource.GetNamespace()
+	scope := fmt.Sprintf("namespace %s", namespace)
+	if count.Scope == kyverno.ClusterScope {
+		namespace = ""
+		scope = "the cluster"
+	}
+	ctx := policyContext.RequestContext
+	if ctx == nil {
+		ctx = gocontext.Background()
+	}
+	resources, err := policyContext.ResourceLister.ListResources(ctx, resource.GetKind(), namespace)
+	if err != nil {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("Validation rule '%s' failed to count resources of kind %s: %v", rule.Name, resource.GetKind(), err)
+		return resp
+	}
+
+	// the admitted resource is counted once, whether it already exists or not
+	total := 1
+	for _, existing := range resources {
+		if existing.GetNamespace() == resource.GetNamespace() && existing.GetName() == resource.GetName() {
+			continue
+		}
+		if matchesCountPattern(existing, count.Pattern) {
+			total++
+		}
+	}
+
+	if total > count.Limit {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("Validation error: %s; Validation rule '%s' failed, %d resources of kind %s in %s exceed the limit of %d",
+			rule.Validation.Message, rule.Name, total, resource.GetKind(), scope, count.Limit)
+		return resp
+	}
+	resp.Success = true
+	resp.Message = fmt.Sprintf("Validation rule '%s' succeeded, %d of %d resources of kind %s in %s.", rule.Name, total, count.Limit, resource.GetKind(), scope)
+	return resp
+}
+
+func matchesCountPattern(resource unstructured.Unstructured, pattern interface{}) bool {
+	if pattern == nil {
+		return true
+	}
+	_, err := validate.ValidateResourceWithPattern(resource.Object, pattern)
+	return err == nil
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeLister lists the resources it holds
+type fakeLister struct {
+	resources []unstructured.Unstructured
+}
+
+func (l fakeLister) ListResources(ctx gocontext.Context, kind, namespace string) ([]unstructured.Unstructured, error) {
+	var resources []unstructured.Unstructured
+	for _, resource := range l.resources {
+		if resource.GetKind() == kind && (namespace == "" || resource.GetNamespace() == namespace) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+func newService(t *testing.T, namespace, name, serviceType string) unstructured.Unstructured {
+	var resource unstructured.Unstructured
+	raw := fmt.Sprintf(`{"apiVersion":"v1","kind":"Service","metadata":{"name":%q,"namespace":%q},"spec":{"type":%q}}`, name, namespace, serviceType)
+	assert.NilError(t, json.Unmarshal([]byte(raw), &resource.Object))
+	return resource
+}
+
+func Test_Validate_Count(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "limit-load-balancers"},
+		"spec": {
+			"rules": [
+				{
+					"name": "limit-load-balancers",
+					"match": {"resources": {"kinds": ["Service"]}},
+					"validate": {
+						"message": "at most 2 load balancers per namespace",
+						"count": {"limit": 2, "pattern": {"spec": {"type": "LoadBalancer"}}}
+					}
+				}
+			]
+		}
+	}`)
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+
+	lister := fakeLister{resources: []unstructured.Unstructured{
+		newService(t, "team-a", "lb-1", "LoadBalancer"),
+		newService(t, "team-a", "lb-2", "LoadBalancer"),
+		newService(t, "team-a", "internal", "ClusterIP"),
+		newService(t, "team-b", "lb-1", "LoadBalancer"),
+	}}
+	validate := func(resource unstructured.Unstructured, lister ResourceLister) []bool {
+		resp := Validate(PolicyContext{Policy: policy, NewResource: resource, Context: context.NewContext(), ResourceLister: lister})
+		var results []bool
+		for _, rule := range resp.PolicyResponse.Rules {
+			results = append(results, rule.Success)
+		}
+		return results
+	}
+
+	// a third load balancer in team-a exceeds the limit
+	assert.DeepEqual(t, validate(newService(t, "team-a", "lb-3", "LoadBalancer"), lister), []bool{false})
+	// a second load balancer in team-b is allowed
+	assert.DeepEqual(t, validate(newService(t, "team-b", "lb-2", "LoadBalancer"), lister), []bool{true})
+	// an existing load balancer is not counted twice
+	assert.DeepEqual(t, validate(newService(t, "team-a", "lb-2", "LoadBalancer"), lister), []bool{true})
+	// services that are no load balancers are not counted
+	assert.Assert(t, validate(newService(t, "team-a", "internal-2", "ClusterIP"), lister) == nil)
+	// without lister, e.g. in background processing, the rule is skipped
+	assert.Assert(t, validate(newService(t, "team-a", "lb-3", "LoadBalancer"), nil) == nil)
+
+	// counted in the cluster, team-b exceeds the limit too
+	policy.Spec.Rules[0].Validation.Count.Scope = kyverno.ClusterScope
+	assert.DeepEqual(t, validate(newService(t, "team-b", "lb-2", "LoadBalancer"), lister), []bool{false})
+
+	// policy exceptions exempt resources from count rules as well
+	exception := kyverno.PolicyException{Spec: kyverno.PolicyExceptionSpec{
+		Exceptions:     []kyverno.Exception{{PolicyName: "limit-load-balancers", RuleNames: []string{"*"}}},
+		MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Service"}}},
+	}}
+	exception.Name = "team-b-load-balancers"
+	exception.Namespace = "team-b"
+	resp := Validate(PolicyContext{Policy: policy, NewResource: newService(t, "team-b", "lb-2", "LoadBalancer"), Context: context.NewContext(),
+		ResourceLister: lister, Exceptions: exceptionList{exception}})
+	assert.Assert(t, resp.IsSuccesful())
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/minio/minio/pkg/wildcard"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// findException returns the policy exception exempting the resource from the rule of the policy, or nil.
+// An exception only applies to the resources of its namespace, unless it is in the Kyverno namespace
+func findException(exceptions []*kyverno.PolicyException, policyName, ruleName string, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo) *kyverno.PolicyException {
+	for _, exception := range exceptions {
+		if exception.Namespace != config.KubePolicyNamespace && exception.Namespace != resource.GetNamespace() {
+			continue
+		}
+		if !exemptsRule(exception.Spec, policyName, ruleName) {
+			continue
+		}
+		rule := kyverno.Rule{MatchResources: exception.Spec.MatchResources}
+		if err := MatchesResourceDescription(resource, rule, admissionInfo); err != nil {
+			continue
+		}
+		return exception
+	}
+	return nil
+}
+
+func exemptsRule(spec kyverno.PolicyExceptionSpec, policyName, ruleName string) bool {
+	for _, e := range spec.Exceptions {
+		if e.PolicyName != policyName {
+			continue
+		}
+		for _, name := range e.RuleNames {
+			if wildcard.Match(name, ruleName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyExceptions marks the failed rules of the response as successful if a policy exception exempts
+// the resource from them, the exception is recorded in the rule message.
+// It is applied to the responses of all the validating rules: validate, verifyImages and verifyManifests
+func applyExceptions(resp *response.EngineResponse, policyContext PolicyContext) {
+	if policyContext.Exceptions == nil || resp.IsSuccesful() {
+		return
+	}
+	policyName := policyContext.Policy.Name
+	exceptions, err := policyContext.Exceptions.ListExceptions(policyName)
+	if err != nil {
+		glog.Errorf("failed to list the policy exceptions of policy %s: %v", policyName, err)
+		return
+	}
+	if len(exceptions) == 0 {
+		return
+	}
+	resource := policyContext.NewResource
+	for i := range resp.PolicyResponse.Rules {
+		ruleResponse := &resp.PolicyResponse.Rules[i]
+		if ruleResponse.Success {
+			continue
+		}
+		exception := findException(exceptions, policyName, ruleResponse.Name, resource, policyContext.AdmissionInfo)
+		if exception == nil {
+			continue
+		}
+		ruleResponse.Success = true
+		ruleResponse.Message = fmt.Sprintf("exempted by policy exception %s/%s: %s", exception.Namespace, exception.Name, ruleResponse.Message)
+	}
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+)
+
+// exceptionList lists the exceptions it holds that exempt rules of the policy
+type exceptionList []kyverno.PolicyException
+
+func (l exceptionList) ListExceptions(policyName string) ([]*kyverno.PolicyException, error) {
+	var exceptions []*kyverno.PolicyException
+	for i := range l {
+		for _, e := range l[i].Spec.Exceptions {
+			if e.PolicyName == policyName {
+				exceptions = append(exceptions, &l[i])
+				break
+			}
+		}
+	}
+	return exceptions, nil
+}
+
+func Test_Validate_PolicyException(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {
+			"name": "disallow-host-network"
+		},
+		"spec": {
+			"rules": [
+				{
+					"name": "host-network",
+					"match": {
+						"resources": {
+							"kinds": [
+								"Pod"
+							]
+						}
+					},
+					"validate": {
+						"message": "host network is not allowed",
+						"pattern": {
+							"spec": {
+								"=(hostNetwork)": false
+							}
+						}
+					}
+				}
+			]
+		}
+	}
+	`)
+	rawResource := []byte(`
+	{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+			"name": "node-exporter",
+			"namespace": "monitoring",
+			"labels": {
+				"app": "node-exporter"
+			}
+		},
+		"spec": {
+			"hostNetwork": true,
+			"containers": [
+				{
+					"name": "node-exporter",
+					"image": "prom/node-exporter"
+				}
+			]
+		}
+	}
+	`)
+	rawException := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "PolicyException",
+		"metadata": {
+			"name": "node-exporter",
+			"namespace": "monitoring"
+		},
+		"spec": {
+			"exceptions": [
+				{
+					"policyName": "disallow-host-network",
+					"ruleNames": [
+						"host-*"
+					]
+				}
+			],
+			"match": {
+				"resources": {
+					"kinds": [
+						"Pod"
+					],
+					"selector": {
+						"matchLabels": {
+							"app": "node-exporter"
+						}
+					}
+				}
+			}
+		}
+	}
+	`)
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+	var exception kyverno.PolicyException
+	assert.NilError(t, json.Unmarshal(rawException, &exception))
+	resourceUnstructured, err := utils.ConvertToUnstructured(rawResource)
+	assert.NilError(t, err)
+
+	policyContext := PolicyContext{
+		Policy:      policy,
+		Context:     context.NewContext(),
+		NewResource: *resourceUnstructured,
+	}
+	er := Validate(policyContext)
+	assert.Assert(t, !er.IsSuccesful())
+
+	policyContext.Exceptions = exceptionList{exception}
+	er = Validate(policyContext)
+	assert.Assert(t, er.IsSuccesful())
+	assert.Equal(t, er.PolicyResponse.Rules[0].Message, "exempted by policy exception monitoring/node-exporter: "+
+		"Validation error: host network is not allowed; Validation rule 'host-network' failed at path '/spec/hostNetwork/'")
+
+	// exceptions of other namespaces do not apply
+	exception.Namespace = "default"
+	policyContext.Exceptions = exceptionList{exception}
+	er = Validate(policyContext)
+	assert.Assert(t, !er.IsSuccesful())
+
+	// except those of the kyverno namespace
+	exception.Namespace = config.KubePolicyNamespace
+	policyContext.Exceptions = exceptionList{exception}
+	er = Validate(policyContext)
+	assert.Assert(t, er.IsSuccesful())
+
+	// the exception must match the resource
+	exception.Spec.MatchResources.Selector.MatchLabels["app"] = "other"
+	policyContext.Exceptions = exceptionList{exception}
+	er = Validate(policyContext)
+	assert.Assert(t, !er.IsSuccesful())
+}
@@ -20,12 +20,29 @@ func Generate(policyContext PolicyContext) (resp response.EngineResponse) {
 	resource := policyContext.NewResource
 	admissionInfo := policyContext.AdmissionInfo
 	ctx := policyContext.Context
-	return filterRules(policy, resource, admissionInfo, ctx)
+	return filterRules(policy, resource, admissionInfo, ctx, "Generation")
 }
 
-func filterRule(rule kyverno.Rule, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.EvalInterface) *response.RuleResponse {
-	if !rule.HasGenerate() {
-		return nil
+// MutateExisting returns the list of mutate rules with targets that are applicable on this policy and resource,
+// the targets are mutated asynchronously
+func MutateExisting(policyContext PolicyContext) (resp response.EngineResponse) {
+	policy := policyContext.Policy
+	resource := policyContext.NewResource
+	admissionInfo := policyContext.AdmissionInfo
+	ctx := policyContext.Context
+	return filterRules(policy, resource, admissionInfo, ctx, "Mutation")
+}
+
+func filterRule(rule kyverno.Rule, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.EvalInterface, ruleType string) *response.RuleResponse {
+	switch ruleType {
+	case "Generation":
+		if !rule.HasGenerate() {
+			return nil
+		}
+	case "Mutation":
+		if !rule.HasMutateExisting() {
+			return nil
+		}
 	}
 
 	startTime := time.Now()
@@ -45,7 +62,7 @@ func filterRule(rule kyverno.Rule, resource unstructured.Unstructured, admission
 	// build rule Response
 	return &response.RuleResponse{
 		Name:    rule.Name,
-		Type:    "Generation",
+		Type:    ruleType,
 		Success: true,
 		RuleStats: response.RuleStats{
 			ProcessingTime: time.Since(startTime),
@@ -53,7 +70,7 @@ func filterRule(rule kyverno.Rule, resource unstructured.Unstructured, admission
 	}
 }
 
-func filterRules(policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.EvalInterface) response.EngineResponse {
+func filterRules(policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.EvalInterface, ruleType string) response.EngineResponse {
 	resp := response.EngineResponse{
 		PolicyResponse: response.PolicyResponse{
 			Policy: policy.Name,
@@ -66,7 +83,7 @@ func filterRules(policy kyverno.ClusterPolicy, resource unstructured.Unstructure
 	}
 
 	for _, rule := range policy.Spec.Rules {
-		if ruleResp := filterRule(rule, resource, admissionInfo, ctx); ruleResp != nil {
+		if ruleResp := filterRule(rule, resource, admissionInfo, ctx, ruleType); ruleResp != nil {
 			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, *ruleResp)
 		}
 	}
@@ -0,0 +1,200 @@
+package engine
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/minio/minio/pkg/wildcard"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/cosign"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// podSpecPaths are the paths of the pod specs in pods, pod controllers and cron jobs
+var podSpecPaths = [][]string{
+	{"spec"},
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// containerImage is the image of a container and the JSON pointer to its image field
+type containerImage struct {
+	path  string
+	image string
+}
+
+// VerifyImages verifies the signatures of the container images matched by the verifyImages rules,
+// verified images are pinned to their digest with JSON patches
+func VerifyImages(policyContext PolicyContext) (resp response.EngineResponse) {
+	startTime := time.Now()
+	policy := policyContext.Policy
+	resource := policyContext.NewResource
+	ctx := policyContext.Context
+
+	startResultResponse(&resp, policy, resource)
+	glog.V(4).Infof("started applying verifyImages rules of policy %q (%v)", policy.Name, startTime)
+	defer func() {
+		resp.PolicyResponse.ProcessingTime = time.Since(startTime)
+		glog.V(4).Infof("finished applying verifyImages rules of policy %q (%v)", policy.Name, resp.PolicyResponse.ProcessingTime)
+	}()
+
+	images := extractImages(resource)
+	if len(images) == 0 {
+		return resp
+	}
+
+	patchedResource := resource
+	for _, rule := range policy.Spec.Rules {
+		if !rule.HasVerifyImages() {
+			continue
+		}
+		if err := MatchesResourceDescription(resource, rule, policyContext.AdmissionInfo); err != nil {
+			glog.V(4).Infof("resource %s/%s does not satisfy the resource description for the rule:\n%s", resource.GetNamespace(), resource.GetName(), err.Error())
+			continue
+		}
+		copyConditions := copyConditions(rule.Conditions)
+		if !variables.EvaluateConditions(ctx, copyConditions) {
+			glog.V(4).Infof("resource %s/%s does not satisfy the conditions for the rule ", resource.GetNamespace(), resource.GetName())
+			continue
+		}
+
+		ruleResponse := verifyImages(policyContext, rule, images)
+		if ruleResponse == nil {
+			continue
+		}
+		if ruleResponse.Success && len(ruleResponse.Patches) != 0 {
+			patchedResource = applyImagePatches(patchedResource, ruleResponse.Patches)
+		}
+		resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, *ruleResponse)
+		incrementAppliedCount(&resp)
+	}
+	resp.PatchedResource = patchedResource
+	// failed rules have no patches, exempting them does not change the patched resource
+	applyExceptions(&resp, policyContext)
+	return resp
+}
+
+// verifyImages returns the response of the rule, or nil if no image matches the rule
+func verifyImages(policyContext PolicyContext, rule kyverno.Rule, images []containerImage) *response.RuleResponse {
+	startTime := time.Now()
+	var ruleResponse *response.RuleResponse
+	var verified []string
+	for _, imageVerify := range rule.VerifyImages {
+		for _, ci := range images {
+			if !wildcard.Match(imageVerify.Image, ci.image) {
+				continue
+			}
+			if ruleResponse == nil {
+				ruleResponse = &response.RuleResponse{
+					Name:    rule.Name,
+					Type:    utils.ImageVerification.String(),
+					Success: true,
+				}
+			}
+			digest, err := verifyImage(policyContext, ci.image, imageVerify)
+			if err != nil {
+				ruleResponse.Success = false
+				ruleResponse.Message = fmt.Sprintf("image verification failed for %s: %v", ci.image, err)
+				ruleResponse.Patches = nil
+				ruleResponse.RuleStats.ProcessingTime = time.Since(startTime)
+				return ruleResponse
+			}
+			verified = append(verified, ci.image)
+			if strings.Contains(ci.image, "@") {
+				continue
+			}
+			patch, err := json.Marshal(map[string]string{
+				"op":    "replace",
+				"path":  ci.path,
+				"value": ci.image + "@" + digest,
+			})
+			if err != nil {
+				glog.Errorf("failed to create patch for image %s: %v", ci.image, err)
+				continue
+			}
+			ruleResponse.Patches = append(ruleResponse.Patches, patch)
+		}
+	}
+	if ruleResponse != nil {
+		ruleResponse.Message = fmt.Sprintf("verified images %s", strings.Join(verified, ", "))
+		ruleResponse.RuleStats.ProcessingTime = time.Since(startTime)
+	}
+	return ruleResponse
+}
+
+// verifyImage returns the digest of the verified image, images cannot be verified without verifier
+func verifyImage(policyContext PolicyContext, image string, imageVerify kyverno.ImageVerification) (string, error) {
+	if policyContext.ImageVerifier == nil {
+		return "", fmt.Errorf("no image verifier is configured")
+	}
+	ctx := policyContext.RequestContext
+	if ctx == nil {
+		ctx = gocontext.Background()
+	}
+	return policyContext.ImageVerifier.Verify(ctx, image, cosign.Options{
+		Key:          imageVerify.Key,
+		Roots:        imageVerify.Roots,
+		Subject:      imageVerify.Subject,
+		Issuer:       imageVerify.Issuer,
+		RekorKey:     imageVerify.RekorKey,
+		Attestations: imageVerify.Attestations,
+	})
+}
+
+// extractImages returns the images of the containers and init containers of the resource
+func extractImages(resource unstructured.Unstructured) []containerImage {
+	var images []containerImage
+	for _, specPath := range podSpecPaths {
+		for _, field := range []string{"initContainers", "containers"} {
+			fields := append(append([]string{}, specPath...), field)
+			containers, found, err := unstructured.NestedSlice(resource.Object, fields...)
+			if err != nil || !found {
+				continue
+			}
+			for i, container := range containers {
+				c, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				image, ok := c["image"].(string)
+				if !ok || image == "" {
+					continue
+				}
+				images = append(images, containerImage{
+					path:  fmt.Sprintf("/%s/%d/image", strings.Join(fields, "/"), i),
+					image: image,
+				})
+			}
+		}
+		if len(images) != 0 {
+			break
+		}
+	}
+	return images
+}
+
+func applyImagePatches(resource unstructured.Unstructured, patches [][]byte) unstructured.Unstructured {
+	raw, err := resource.MarshalJSON()
+	if err != nil {
+		glog.Errorf("failed to marshal resource: %v", err)
+		return resource
+	}
+	patched, err := utils.ApplyPatches(raw, patches)
+	if err != nil {
+		glog.Errorf("failed to apply image patches: %v", err)
+		return resource
+	}
+	var patchedResource unstructured.Unstructured
+	if err := patchedResource.UnmarshalJSON(patched); err != nil {
+		glog.Errorf("failed to unmarshal patched resource: %v", err)
+		return resource
+	}
+	return patchedResource
+}
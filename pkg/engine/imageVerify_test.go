@@ -0,0 +1,128 @@
+package engine
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/cosign"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeVerifier verifies the images listed in digests
+type fakeVerifier struct {
+	digests map[string]string
+}
+
+func (v fakeVerifier) Verify(ctx gocontext.Context, image string, opts cosign.Options) (string, error) {
+	if digest, ok := v.digests[image]; ok {
+		return digest, nil
+	}
+	return "", fmt.Errorf("image %s is not signed", image)
+}
+
+func Test_VerifyImages(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {
+			"name": "check-image"
+		},
+		"spec": {
+			"validationFailureAction": "enforce",
+			"rules": [
+				{
+					"name": "check-image",
+					"match": {
+						"resources": {
+							"kinds": [
+								"Deployment"
+							]
+						}
+					},
+					"verifyImages": [
+						{
+							"image": "ghcr.io/myorg/*",
+							"key": "key"
+						}
+					]
+				}
+			]
+		}
+	}
+	`)
+	rawResource := []byte(`
+	{
+		"apiVersion": "apps/v1",
+		"kind": "Deployment",
+		"metadata": {
+			"name": "app"
+		},
+		"spec": {
+			"template": {
+				"spec": {
+					"initContainers": [
+						{
+							"name": "init",
+							"image": "busybox"
+						}
+					],
+					"containers": [
+						{
+							"name": "app",
+							"image": "ghcr.io/myorg/app:v1"
+						},
+						{
+							"name": "sidecar",
+							"image": "ghcr.io/myorg/sidecar@sha256:def"
+						}
+					]
+				}
+			}
+		}
+	}
+	`)
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+	resourceUnstructured, err := utils.ConvertToUnstructured(rawResource)
+	assert.NilError(t, err)
+
+	policyContext := PolicyContext{
+		Policy:      policy,
+		Context:     context.NewContext(),
+		NewResource: *resourceUnstructured,
+		ImageVerifier: fakeVerifier{digests: map[string]string{
+			"ghcr.io/myorg/app:v1":             "sha256:abc",
+			"ghcr.io/myorg/sidecar@sha256:def": "sha256:def",
+		}},
+	}
+	er := VerifyImages(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, er.IsSuccesful())
+	assert.Equal(t, er.PolicyResponse.ValidationFailureAction, "enforce")
+	patches := er.GetPatches()
+	assert.Equal(t, len(patches), 1)
+	assert.Equal(t, string(patches[0]), `{"op":"replace","path":"/spec/template/spec/containers/0/image","value":"ghcr.io/myorg/app:v1@sha256:abc"}`)
+	containers, _, _ := unstructured.NestedSlice(er.PatchedResource.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, containers[0].(map[string]interface{})["image"], "ghcr.io/myorg/app:v1@sha256:abc")
+
+	// unsigned image
+	policyContext.ImageVerifier = fakeVerifier{}
+	er = VerifyImages(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, !er.IsSuccesful())
+	assert.Equal(t, len(er.GetPatches()), 0)
+
+	// images cannot be verified without verifier
+	policyContext.ImageVerifier = nil
+	er = VerifyImages(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, !er.IsSuccesful())
+}
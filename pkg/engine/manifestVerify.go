@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"github.com/nirmata/kyverno/pkg/manifest"
+)
+
+// VerifyManifests verifies that the resource matches a manifest signed by one of the keys of the verifyManifests rules.
+// The new resource is always verified, also on updates, so that unsigned changes are blocked
+func VerifyManifests(policyContext PolicyContext) (resp response.EngineResponse) {
+	startTime := time.Now()
+	policy := policyContext.Policy
+	resource := policyContext.NewResource
+	ctx := policyContext.Context
+
+	startResultResponse(&resp, policy, resource)
+	glog.V(4).Infof("started applying verifyManifests rules of policy %q (%v)", policy.Name, startTime)
+	defer func() {
+		resp.PolicyResponse.ProcessingTime = time.Since(startTime)
+		glog.V(4).Infof("finished applying verifyManifests rules of policy %q (%v)", policy.Name, resp.PolicyResponse.ProcessingTime)
+	}()
+
+	// deleted resources have no manifest to verify
+	if resource.Object == nil {
+		return resp
+	}
+
+	for _, rule := range policy.Spec.Rules {
+		if !rule.HasVerifyManifests() {
+			continue
+		}
+		if err := MatchesResourceDescription(resource, rule, policyContext.AdmissionInfo); err != nil {
+			glog.V(4).Infof("resource %s/%s does not satisfy the resource description for the rule:\n%s", resource.GetNamespace(), resource.GetName(), err.Error())
+			continue
+		}
+		copyConditions := copyConditions(rule.Conditions)
+		if !variables.EvaluateConditions(ctx, copyConditions) {
+			glog.V(4).Infof("resource %s/%s does not satisfy the conditions for the rule ", resource.GetNamespace(), resource.GetName())
+			continue
+		}
+
+		ruleResponse := verifyManifest(policyContext, rule)
+		resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResponse)
+		incrementAppliedCount(&resp)
+	}
+	applyExceptions(&resp, policyContext)
+	return resp
+}
+
+func verifyManifest(policyContext PolicyContext, rule kyverno.Rule) response.RuleResponse {
+	startTime := time.Now()
+	resource := policyContext.NewResource
+	ruleResponse := response.RuleResponse{
+		Name:    rule.Name,
+		Type:    utils.ManifestVerification.String(),
+		Success: true,
+		Message: fmt.Sprintf("verified manifest of %s %s", resource.GetKind(), resource.GetName()),
+	}
+	if err := manifest.Verify(resource, rule.VerifyManifests); err != nil {
+		ruleResponse.Success = false
+		ruleResponse.Message = fmt.Sprintf("manifest verification failed for %s %s: %v", resource.GetKind(), resource.GetName(), err)
+	}
+	ruleResponse.RuleStats.ProcessingTime = time.Since(startTime)
+	return ruleResponse
+}
@@ -36,6 +36,10 @@ func Mutate(policyContext PolicyContext) (resp response.EngineResponse) {
 	patchedResource := policyContext.NewResource
 	for _, rule := range policy.Spec.Rules {
 		var ruleResponse response.RuleResponse
+		// the targets of the rule are mutated in the background instead of the resource
+		if rule.HasMutateExisting() {
+			continue
+		}
 		//TODO: to be checked before calling the resources as well
 		if !rule.HasMutate() && !strings.Contains(PodControllers, resource.GetKind()) {
 			continue
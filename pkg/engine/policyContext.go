@@ -1,7 +1,10 @@
 package engine
 
 import (
+	gocontext "context"
+
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/cosign"
 	client "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/engine/context"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -20,4 +23,22 @@ type PolicyContext struct {
 	Client *client.Client
 	// Contexts to store resources
 	Context context.EvalInterface
+	// Image signature verifier - used by verifyImages
+	ImageVerifier cosign.Verifier
+	// Policy exceptions exempting resources from failed rules
+	Exceptions ExceptionLister
+	// Cached resource lister - used by count rules
+	ResourceLister ResourceLister
+	// Context of the admission request, bounds the calls to image registries, the API server and the resource cache
+	RequestContext gocontext.Context
+}
+
+// ExceptionLister lists the policy exceptions exempting rules of the policy, in all namespaces
+type ExceptionLister interface {
+	ListExceptions(policyName string) ([]*kyverno.PolicyException, error)
+}
+
+// ResourceLister lists the resources of a kind in the namespace, or in all namespaces if the namespace is empty
+type ResourceLister interface {
+	ListResources(ctx gocontext.Context, kind, namespace string) ([]unstructured.Unstructured, error)
 }
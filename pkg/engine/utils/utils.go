@@ -16,6 +16,10 @@ const (
 	Validation
 	//Generation type for generation rule
 	Generation
+	//ImageVerification type for verifyImages rule
+	ImageVerification
+	//ManifestVerification type for verifyManifests rule
+	ManifestVerification
 	//All type for other rule operations(future)
 	All
 )
@@ -25,6 +29,8 @@ func (ri RuleType) String() string {
 		"Mutation",
 		"Validation",
 		"Generation",
+		"ImageVerification",
+		"ManifestVerification",
 		"All",
 	}[ri]
 }
@@ -31,7 +31,8 @@ func Validate(policyContext PolicyContext) (resp response.EngineResponse) {
 	if reflect.DeepEqual(oldR, unstructured.Unstructured{}) {
 		// Create Mode
 		// Operate on New Resource only
-		resp := validateResource(ctx, policy, newR, admissionInfo)
+		resp := validateResource(ctx, policy, newR, admissionInfo, policyContext)
+		applyExceptions(resp, policyContext)
 		startResultResponse(resp, policy, newR)
 		defer endResultResponse(resp, startTime)
 		// set PatchedResource with origin resource if empty
@@ -44,12 +45,13 @@ func Validate(policyContext PolicyContext) (resp response.EngineResponse) {
 	// Update Mode
 	// Operate on New and Old Resource only
 	// New resource
-	oldResponse := validateResource(ctx, policy, oldR, admissionInfo)
-	newResponse := validateResource(ctx, policy, newR, admissionInfo)
+	oldResponse := validateResource(ctx, policy, oldR, admissionInfo, policyContext)
+	newResponse := validateResource(ctx, policy, newR, admissionInfo, policyContext)
 
 	// if the old and new response is same then return empty response
 	if !isSameResponse(oldResponse, newResponse) {
 		// there are changes send response
+		applyExceptions(newResponse, policyContext)
 		startResultResponse(newResponse, policy, newR)
 		defer endResultResponse(newResponse, startTime)
 		if reflect.DeepEqual(newResponse.PatchedResource, unstructured.Unstructured{}) {
@@ -70,7 +72,7 @@ func startResultResponse(resp *response.EngineResponse, policy kyverno.ClusterPo
 	resp.PolicyResponse.Resource.Namespace = newR.GetNamespace()
 	resp.PolicyResponse.Resource.Kind = newR.GetKind()
 	resp.PolicyResponse.Resource.APIVersion = newR.GetAPIVersion()
-	resp.PolicyResponse.ValidationFailureAction = policy.Spec.ValidationFailureAction
+	resp.PolicyResponse.ValidationFailureAction = policy.GetValidationFailureAction()
 }
 
 func endResultResponse(resp *response.EngineResponse, startTime time.Time) {
@@ -84,7 +86,7 @@ func incrementAppliedCount(resp *response.EngineResponse) {
 	resp.PolicyResponse.RulesAppliedCount++
 }
 
-func validateResource(ctx context.EvalInterface, policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo) *response.EngineResponse {
+func validateResource(ctx context.EvalInterface, policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, policyContext PolicyContext) *response.EngineResponse {
 	resp := &response.EngineResponse{}
 	for _, rule := range policy.Spec.Rules {
 		if !rule.HasValidate() {
@@ -115,6 +117,20 @@ func validateResource(ctx context.EvalInterface, policy kyverno.ClusterPolicy, r
 			incrementAppliedCount(resp)
 			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResponse)
 		}
+
+		if rule.Validation.Count != nil {
+			// resources are only counted at admission, where the cached listers are available
+			if policyContext.ResourceLister == nil {
+				glog.V(4).Infof("no resource lister configured, skip counting resources for rule %s", rule.Name)
+				continue
+			}
+			ruleResponse := validateCount(policyContext, resource, rule)
+			if ruleResponse == nil {
+				continue
+			}
+			incrementAppliedCount(resp)
+			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, *ruleResponse)
+		}
 	}
 	return resp
 }
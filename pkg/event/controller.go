@@ -1,6 +1,7 @@
 package event
 
 import (
+	"context"
 	"time"
 
 	"github.com/golang/glog"
@@ -170,7 +171,7 @@ func (gen *Generator) syncHandler(key Info) error {
 			return err
 		}
 	default:
-		robj, err = gen.client.GetResource(key.Kind, key.Namespace, key.Name)
+		robj, err = gen.client.GetResource(context.TODO(), key.Kind, key.Namespace, key.Name)
 		if err != nil {
 			glog.V(4).Infof("Error creating event: unable to get resource %s/%s/%s, will retry ", key.Kind, key.Namespace, key.Name)
 			return err
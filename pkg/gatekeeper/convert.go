@@ -0,0 +1,304 @@
+package gatekeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ConstraintGroup is the API group of the Gatekeeper constraints
+	ConstraintGroup = "constraints.gatekeeper.sh"
+	// TemplateGroup is the API group of the Gatekeeper constraint templates
+	TemplateGroup = "templates.gatekeeper.sh"
+)
+
+// The kinds of the constraint templates of the Gatekeeper library that can be converted
+const (
+	RequiredLabels  = "K8sRequiredLabels"
+	AllowedRepos    = "K8sAllowedRepos"
+	ContainerLimits = "K8sContainerLimits"
+)
+
+// converter translates the parameters of a constraint into a validation,
+// the warnings report the parameters that could not be translated exactly
+type converter struct {
+	// the kinds validated by the rule, the kinds of the constraint are used if empty
+	kinds   []string
+	convert func(parameters map[string]interface{}) (kyverno.Validation, []string, error)
+}
+
+var converters = map[string]converter{
+	RequiredLabels:  {convert: convertRequiredLabels},
+	AllowedRepos:    {kinds: []string{"Pod"}, convert: convertAllowedRepos},
+	ContainerLimits: {kinds: []string{"Pod"}, convert: convertContainerLimits},
+}
+
+// IsSupported returns true if the constraints of the template kind can be converted
+func IsSupported(kind string) bool {
+	_, ok := converters[kind]
+	return ok
+}
+
+// TemplateKind returns the kind of the constraints defined by a constraint template
+func TemplateKind(template unstructured.Unstructured) (string, error) {
+	kind, _, err := unstructured.NestedString(template.Object, "spec", "crd", "spec", "names", "kind")
+	if err != nil {
+		return "", err
+	}
+	if kind == "" {
+		return "", fmt.Errorf("constraint template %s does not define the constraint kind", template.GetName())
+	}
+	return kind, nil
+}
+
+// Convert translates a Gatekeeper constraint into a Kyverno policy with a validate rule,
+// the rego of the constraint template is not translated: only the templates of the Gatekeeper
+// library listed above are supported. The warnings report the parts of the constraint that
+// could not be translated exactly. Parts of the match that cannot be translated, and would change
+// the resources the policy applies to, fail the conversion unless force is set, they are then dropped
+func Convert(constraint unstructured.Unstructured, force bool) (*kyverno.ClusterPolicy, []string, error) {
+	kind := constraint.GetKind()
+	c, ok := converters[kind]
+	if !ok {
+		return nil, nil, fmt.Errorf("constraint kind %s is not supported", kind)
+	}
+	name := constraint.GetName()
+
+	parameters, _, err := unstructured.NestedMap(constraint.Object, "spec", "parameters")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters in constraint %s: %v", name, err)
+	}
+	validation, warnings, err := c.convert(parameters)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert constraint %s: %v", name, err)
+	}
+
+	match, exclude, matchWarnings, err := convertMatch(constraint, force)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert the match of constraint %s: %v", name, err)
+	}
+	warnings = append(warnings, matchWarnings...)
+	if len(c.kinds) != 0 {
+		match.Kinds = c.kinds
+	}
+	if len(match.Kinds) == 0 {
+		return nil, nil, fmt.Errorf("constraint %s does not match any kind", name)
+	}
+
+	action, err := convertEnforcementAction(constraint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert constraint %s: %v", name, err)
+	}
+
+	background := true
+	policy := &kyverno.ClusterPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kyverno.SchemeGroupVersion.String(),
+			Kind:       "ClusterPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kyverno.Spec{
+			ValidationFailureAction: action,
+			Background:              &background,
+			Rules: []kyverno.Rule{
+				{
+					Name:             strings.ToLower(kind),
+					MatchResources:   match,
+					ExcludeResources: exclude,
+					Validation:       validation,
+				},
+			},
+		},
+	}
+	return policy, warnings, nil
+}
+
+// convertMatch translates the match of the constraint into the match and exclude blocks of the rule
+func convertMatch(constraint unstructured.Unstructured, force bool) (kyverno.MatchResources, kyverno.ExcludeResources, []string, error) {
+	var match kyverno.MatchResources
+	var exclude kyverno.ExcludeResources
+	var warnings []string
+
+	m, _, err := unstructured.NestedMap(constraint.Object, "spec", "match")
+	if err != nil {
+		return match, exclude, nil, err
+	}
+	if kinds, ok := m["kinds"].([]interface{}); ok {
+		for _, k := range kinds {
+			kindMatch, ok := k.(map[string]interface{})
+			if !ok {
+				return match, exclude, nil, fmt.Errorf("invalid kinds %v", k)
+			}
+			names, _, err := unstructured.NestedStringSlice(kindMatch, "kinds")
+			if err != nil {
+				return match, exclude, nil, err
+			}
+			for _, name := range names {
+				if name == "*" {
+					return match, exclude, nil, fmt.Errorf("the wildcard kind is not supported")
+				}
+				match.Kinds = append(match.Kinds, name)
+			}
+		}
+	}
+	if match.Namespaces, _, err = unstructured.NestedStringSlice(m, "namespaces"); err != nil {
+		return match, exclude, nil, err
+	}
+	if exclude.Namespaces, _, err = unstructured.NestedStringSlice(m, "excludedNamespaces"); err != nil {
+		return match, exclude, nil, err
+	}
+	if match.Name, _, err = unstructured.NestedString(m, "name"); err != nil {
+		return match, exclude, nil, err
+	}
+	if selector, ok := m["labelSelector"]; ok {
+		raw, err := json.Marshal(selector)
+		if err != nil {
+			return match, exclude, nil, err
+		}
+		match.Selector = &metav1.LabelSelector{}
+		if err := json.Unmarshal(raw, match.Selector); err != nil {
+			return match, exclude, nil, fmt.Errorf("invalid labelSelector: %v", err)
+		}
+	}
+	if _, ok := m["namespaceSelector"]; ok {
+		if !force {
+			return match, exclude, nil, fmt.Errorf("namespaceSelector is not supported, list the namespaces instead or force the conversion to ignore it")
+		}
+		warnings = append(warnings, "namespaceSelector is not supported and was ignored, list the namespaces instead")
+	}
+	if scope, ok := m["scope"]; ok && scope != "*" {
+		if !force {
+			return match, exclude, nil, fmt.Errorf("scope %v is not supported, force the conversion to ignore it", scope)
+		}
+		warnings = append(warnings, fmt.Sprintf("scope %v is not supported and was ignored", scope))
+	}
+	return match, exclude, warnings, nil
+}
+
+// convertEnforcementAction translates the enforcement action of the constraint, deny by default
+func convertEnforcementAction(constraint unstructured.Unstructured) (string, error) {
+	action, _, err := unstructured.NestedString(constraint.Object, "spec", "enforcementAction")
+	if err != nil {
+		return "", err
+	}
+	switch action {
+	case "", "deny":
+		return "enforce", nil
+	case "dryrun", "warn":
+		return "audit", nil
+	default:
+		return "", fmt.Errorf("unknown enforcementAction %q", action)
+	}
+}
+
+// convertRequiredLabels requires the labels to be set, the parameters are either
+// a list of label keys or a list of objects with a key and an allowed regex
+func convertRequiredLabels(parameters map[string]interface{}) (kyverno.Validation, []string, error) {
+	var warnings []string
+	labels, ok := parameters["labels"].([]interface{})
+	if !ok || len(labels) == 0 {
+		return kyverno.Validation{}, nil, fmt.Errorf("parameter labels is required")
+	}
+	pattern := map[string]interface{}{}
+	var keys []string
+	for _, l := range labels {
+		var key string
+		switch label := l.(type) {
+		case string:
+			key = label
+		case map[string]interface{}:
+			key, _ = label["key"].(string)
+			if regex, _ := label["allowedRegex"].(string); regex != "" {
+				warnings = append(warnings, fmt.Sprintf("allowedRegex %q of label %s is not supported, any non-empty value is allowed", regex, key))
+			}
+		}
+		if key == "" {
+			return kyverno.Validation{}, nil, fmt.Errorf("invalid label %v", l)
+		}
+		pattern[key] = "?*"
+		keys = append(keys, key)
+	}
+	message, _ := parameters["message"].(string)
+	if message == "" {
+		message = fmt.Sprintf("the following labels are required: %s", strings.Join(keys, ", "))
+	}
+	return kyverno.Validation{
+		Message: message,
+		Pattern: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": pattern,
+			},
+		},
+	}, warnings, nil
+}
+
+// convertAllowedRepos requires the images of the containers to start with one of the repositories
+func convertAllowedRepos(parameters map[string]interface{}) (kyverno.Validation, []string, error) {
+	repos, _, err := unstructured.NestedStringSlice(parameters, "repos")
+	if err != nil {
+		return kyverno.Validation{}, nil, err
+	}
+	if len(repos) == 0 {
+		return kyverno.Validation{}, nil, fmt.Errorf("parameter repos is required")
+	}
+	var statements []string
+	for _, repo := range repos {
+		statements = append(statements, repo+"*")
+	}
+	image := map[string]interface{}{"image": strings.Join(statements, " | ")}
+	return kyverno.Validation{
+		Message: fmt.Sprintf("images must come from the repositories %s", strings.Join(repos, ", ")),
+		Pattern: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"=(initContainers)": []interface{}{image},
+				"containers":        []interface{}{image},
+			},
+		},
+	}, nil, nil
+}
+
+// convertContainerLimits requires the containers to set cpu and memory limits,
+// lower than or equal to the cpu and memory parameters
+func convertContainerLimits(parameters map[string]interface{}) (kyverno.Validation, []string, error) {
+	limits := map[string]interface{}{}
+	var maximums []string
+	for _, resource := range []string{"cpu", "memory"} {
+		limits[resource] = "?*"
+		value, ok := parameters[resource]
+		if !ok {
+			continue
+		}
+		max := fmt.Sprint(value)
+		if _, err := apiresource.ParseQuantity(max); err != nil {
+			return kyverno.Validation{}, nil, fmt.Errorf("invalid %s limit %q: %v", resource, max, err)
+		}
+		limits[resource] = "<=" + max
+		maximums = append(maximums, fmt.Sprintf("%s %s", resource, max))
+	}
+	message := "cpu and memory limits are required"
+	if len(maximums) != 0 {
+		message = fmt.Sprintf("%s, the maximum limits are %s", message, strings.Join(maximums, ", "))
+	}
+	return kyverno.Validation{
+		Message: message,
+		Pattern: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{
+						"resources": map[string]interface{}{
+							"limits": limits,
+						},
+					},
+				},
+			},
+		},
+	}, nil, nil
+}
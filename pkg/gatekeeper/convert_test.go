@@ -0,0 +1,135 @@
+package gatekeeper
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func toUnstructured(t *testing.T, raw string) unstructured.Unstructured {
+	resource, err := utils.ConvertToUnstructured([]byte(raw))
+	assert.NilError(t, err)
+	return *resource
+}
+
+func validate(t *testing.T, policy *kyverno.ClusterPolicy, rawResource string) bool {
+	policyContext := engine.PolicyContext{
+		Policy:      *policy,
+		Context:     context.NewContext(),
+		NewResource: toUnstructured(t, rawResource),
+	}
+	er := engine.Validate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	return er.IsSuccesful()
+}
+
+func Test_Convert_RequiredLabels(t *testing.T) {
+	constraint := toUnstructured(t, `{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind": "K8sRequiredLabels",
+		"metadata": {"name": "ns-must-have-owner"},
+		"spec": {
+			"enforcementAction": "dryrun",
+			"match": {
+				"kinds": [{"apiGroups": [""], "kinds": ["Namespace"]}],
+				"excludedNamespaces": ["kube-system"],
+				"namespaceSelector": {"matchLabels": {"team": "a"}}
+			},
+			"parameters": {
+				"labels": [{"key": "owner", "allowedRegex": "^[a-z]+$"}]
+			}
+		}
+	}`)
+
+	// the namespaceSelector would be dropped, the policy would apply to all namespaces
+	_, _, err := Convert(constraint, false)
+	assert.ErrorContains(t, err, "namespaceSelector is not supported")
+
+	policy, warnings, err := Convert(constraint, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(warnings), 2)
+	assert.Equal(t, policy.Name, "ns-must-have-owner")
+	assert.Equal(t, policy.Spec.ValidationFailureAction, "audit")
+	rule := policy.Spec.Rules[0]
+	assert.DeepEqual(t, rule.MatchResources.Kinds, []string{"Namespace"})
+	assert.DeepEqual(t, rule.ExcludeResources.Namespaces, []string{"kube-system"})
+
+	assert.Assert(t, validate(t, policy, `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "a", "labels": {"owner": "me"}}}`))
+	assert.Assert(t, !validate(t, policy, `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "b", "labels": {"team": "a"}}}`))
+}
+
+func Test_Convert_AllowedRepos(t *testing.T) {
+	constraint := toUnstructured(t, `{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind": "K8sAllowedRepos",
+		"metadata": {"name": "allowed-repos"},
+		"spec": {
+			"match": {
+				"kinds": [{"apiGroups": [""], "kinds": ["Pod"]}]
+			},
+			"parameters": {
+				"repos": ["openpolicyagent/", "ghcr.io/myorg/"]
+			}
+		}
+	}`)
+
+	policy, warnings, err := Convert(constraint, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(warnings), 0)
+	assert.Equal(t, policy.Spec.ValidationFailureAction, "enforce")
+
+	assert.Assert(t, validate(t, policy, `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "a"},
+		"spec": {"containers": [{"name": "a", "image": "openpolicyagent/opa"}, {"name": "b", "image": "ghcr.io/myorg/app:v1"}]}}`))
+	assert.Assert(t, !validate(t, policy, `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "b"},
+		"spec": {"containers": [{"name": "a", "image": "openpolicyagent/opa"}], "initContainers": [{"name": "init", "image": "busybox"}]}}`))
+}
+
+func Test_Convert_ContainerLimits(t *testing.T) {
+	constraint := toUnstructured(t, `{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind": "K8sContainerLimits",
+		"metadata": {"name": "container-limits"},
+		"spec": {
+			"parameters": {
+				"cpu": "200m",
+				"memory": "1Gi"
+			}
+		}
+	}`)
+
+	policy, _, err := Convert(constraint, false)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policy.Spec.Rules[0].MatchResources.Kinds, []string{"Pod"})
+
+	assert.Assert(t, validate(t, policy, `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "a"},
+		"spec": {"containers": [{"name": "a", "image": "nginx", "resources": {"limits": {"cpu": "100m", "memory": "512Mi"}}}]}}`))
+	assert.Assert(t, !validate(t, policy, `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "b"},
+		"spec": {"containers": [{"name": "a", "image": "nginx", "resources": {"limits": {"cpu": "1", "memory": "512Mi"}}}]}}`))
+	assert.Assert(t, !validate(t, policy, `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "c"},
+		"spec": {"containers": [{"name": "a", "image": "nginx"}]}}`))
+}
+
+func Test_Convert_Unsupported(t *testing.T) {
+	constraint := toUnstructured(t, `{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind": "K8sPSPPrivilegedContainer",
+		"metadata": {"name": "psp-privileged-container"}
+	}`)
+	_, _, err := Convert(constraint, false)
+	assert.ErrorContains(t, err, "not supported")
+
+	// the kinds are required for the constraints of templates that are not specific to pods
+	constraint = toUnstructured(t, `{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind": "K8sRequiredLabels",
+		"metadata": {"name": "all-must-have-owner"},
+		"spec": {"parameters": {"labels": ["owner"]}}
+	}`)
+	_, _, err = Convert(constraint, false)
+	assert.ErrorContains(t, err, "does not match any kind")
+}
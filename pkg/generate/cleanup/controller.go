@@ -25,29 +25,29 @@ const (
 	maxRetries = 5
 )
 
-//Controller manages life-cycle of generate-requests
+//Controller manages life-cycle of update-requests
 type Controller struct {
 	// dyanmic client implementation
 	client *dclient.Client
 	// typed client for kyverno CRDs
 	kyvernoClient *kyvernoclient.Clientset
-	// handler for GR CR
-	syncHandler func(grKey string) error
-	// handler to enqueue GR
-	enqueueGR func(gr *kyverno.GenerateRequest)
+	// handler for UR CR
+	syncHandler func(urKey string) error
+	// handler to enqueue UR
+	enqueueUR func(ur *kyverno.UpdateRequest)
 
-	// control is used to delete the GR
+	// control is used to delete the UR
 	control ControlInterface
-	// gr that need to be synced
+	// ur that need to be synced
 	queue workqueue.RateLimitingInterface
 	// pLister can list/get cluster policy from the shared informer's store
 	pLister kyvernolister.ClusterPolicyLister
-	// grLister can list/get generate request from the shared informer's store
-	grLister kyvernolister.GenerateRequestNamespaceLister
+	// urLister can list/get update request from the shared informer's store
+	urLister kyvernolister.UpdateRequestNamespaceLister
 	// pSynced returns true if the cluster policy has been synced at least once
 	pSynced cache.InformerSynced
-	// grSynced returns true if the generate request store has been synced at least once
-	grSynced cache.InformerSynced
+	// urSynced returns true if the update request store has been synced at least once
+	urSynced cache.InformerSynced
 	// dyanmic sharedinformer factory
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory
 	//TODO: list of generic informers
@@ -55,40 +55,40 @@ type Controller struct {
 	nsInformer informers.GenericInformer
 }
 
-//NewController returns a new controller instance to manage generate-requests
+//NewController returns a new controller instance to manage update-requests
 func NewController(
 	kyvernoclient *kyvernoclient.Clientset,
 	client *dclient.Client,
 	pInformer kyvernoinformer.ClusterPolicyInformer,
-	grInformer kyvernoinformer.GenerateRequestInformer,
+	urInformer kyvernoinformer.UpdateRequestInformer,
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory,
 ) *Controller {
 	c := Controller{
 		kyvernoClient: kyvernoclient,
 		client:        client,
 		//TODO: do the math for worst case back off and make sure cleanup runs after that
-		// as we dont want a deleted GR to be re-queue
-		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(1, 30), "generate-request-cleanup"),
+		// as we dont want a deleted UR to be re-queue
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(1, 30), "update-request-cleanup"),
 		dynamicInformer: dynamicInformer,
 	}
 	c.control = Control{client: kyvernoclient}
-	c.enqueueGR = c.enqueue
-	c.syncHandler = c.syncGenerateRequest
+	c.enqueueUR = c.enqueue
+	c.syncHandler = c.syncUpdateRequest
 
 	c.pLister = pInformer.Lister()
-	c.grLister = grInformer.Lister().GenerateRequests("kyverno")
+	c.urLister = urInformer.Lister().UpdateRequests("kyverno")
 
 	c.pSynced = pInformer.Informer().HasSynced
-	c.grSynced = grInformer.Informer().HasSynced
+	c.urSynced = urInformer.Informer().HasSynced
 
 	pInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		DeleteFunc: c.deletePolicy, // we only cleanup if the policy is delete
 	}, 2*time.Minute)
 
-	grInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
-		AddFunc:    c.addGR,
-		UpdateFunc: c.updateGR,
-		DeleteFunc: c.deleteGR,
+	urInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addUR,
+		UpdateFunc: c.updateUR,
+		DeleteFunc: c.deleteUR,
 	}, 2*time.Minute)
 	//TODO: dynamic registration
 	// Only supported for namespaces
@@ -103,14 +103,14 @@ func NewController(
 
 func (c *Controller) deleteGenericResource(obj interface{}) {
 	r := obj.(*unstructured.Unstructured)
-	grs, err := c.grLister.GetGenerateRequestsForResource(r.GetKind(), r.GetNamespace(), r.GetName())
+	urs, err := c.urLister.GetUpdateRequestsForResource(r.GetKind(), r.GetNamespace(), r.GetName())
 	if err != nil {
-		glog.Errorf("failed to Generate Requests for resource %s/%s/%s: %v", r.GetKind(), r.GetNamespace(), r.GetName(), err)
+		glog.Errorf("failed to Update Requests for resource %s/%s/%s: %v", r.GetKind(), r.GetNamespace(), r.GetName(), err)
 		return
 	}
-	// re-evaluate the GR as the resource was deleted
-	for _, gr := range grs {
-		c.enqueueGR(gr)
+	// re-evaluate the UR as the resource was deleted
+	for _, ur := range urs {
+		c.enqueueUR(ur)
 	}
 }
 
@@ -124,64 +124,64 @@ func (c *Controller) deletePolicy(obj interface{}) {
 		}
 		_, ok = tombstone.Obj.(*kyverno.ClusterPolicy)
 		if !ok {
-			glog.Info(fmt.Errorf("Tombstone contained object that is not a Generate Request %#v", obj))
+			glog.Info(fmt.Errorf("Tombstone contained object that is not an Update Request %#v", obj))
 			return
 		}
 	}
 	glog.V(4).Infof("Deleting Policy %s", p.Name)
-	// clean up the GR
-	// Get the corresponding GR
-	// get the list of GR for the current Policy version
-	grs, err := c.grLister.GetGenerateRequestsForClusterPolicy(p.Name)
+	// clean up the UR
+	// Get the corresponding UR
+	// get the list of UR for the current Policy version
+	urs, err := c.urLister.GetUpdateRequestsForClusterPolicy(p.Name)
 	if err != nil {
-		glog.Errorf("failed to Generate Requests for policy %s: %v", p.Name, err)
+		glog.Errorf("failed to Update Requests for policy %s: %v", p.Name, err)
 		return
 	}
-	for _, gr := range grs {
-		c.addGR(gr)
+	for _, ur := range urs {
+		c.addUR(ur)
 	}
 }
 
-func (c *Controller) addGR(obj interface{}) {
-	gr := obj.(*kyverno.GenerateRequest)
-	c.enqueueGR(gr)
+func (c *Controller) addUR(obj interface{}) {
+	ur := obj.(*kyverno.UpdateRequest)
+	c.enqueueUR(ur)
 }
 
-func (c *Controller) updateGR(old, cur interface{}) {
-	gr := cur.(*kyverno.GenerateRequest)
-	c.enqueueGR(gr)
+func (c *Controller) updateUR(old, cur interface{}) {
+	ur := cur.(*kyverno.UpdateRequest)
+	c.enqueueUR(ur)
 }
 
-func (c *Controller) deleteGR(obj interface{}) {
-	gr, ok := obj.(*kyverno.GenerateRequest)
+func (c *Controller) deleteUR(obj interface{}) {
+	ur, ok := obj.(*kyverno.UpdateRequest)
 	if !ok {
 		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
 		if !ok {
 			glog.Info(fmt.Errorf("Couldn't get object from tombstone %#v", obj))
 			return
 		}
-		_, ok = tombstone.Obj.(*kyverno.GenerateRequest)
+		_, ok = tombstone.Obj.(*kyverno.UpdateRequest)
 		if !ok {
-			glog.Info(fmt.Errorf("Tombstone contained object that is not a Generate Request %#v", obj))
+			glog.Info(fmt.Errorf("Tombstone contained object that is not an Update Request %#v", obj))
 			return
 		}
 	}
-	glog.V(4).Infof("Deleting GR %s", gr.Name)
+	glog.V(4).Infof("Deleting UR %s", ur.Name)
 	// sync Handler will remove it from the queue
-	c.enqueueGR(gr)
+	c.enqueueUR(ur)
 }
 
-func (c *Controller) enqueue(gr *kyverno.GenerateRequest) {
-	key, err := cache.MetaNamespaceKeyFunc(gr)
+func (c *Controller) enqueue(ur *kyverno.UpdateRequest) {
+	key, err := cache.MetaNamespaceKeyFunc(ur)
 	if err != nil {
 		glog.Error(err)
 		return
 	}
-	glog.V(4).Infof("cleanup enqueu: %v", gr.Name)
+	glog.V(4).Infof("cleanup enqueu: %v", ur.Name)
 	c.queue.Add(key)
 }
 
-//Run starts the generate-request re-conciliation loop
+//Run starts the update-request re-conciliation loop
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 	defer c.queue.ShutDown()
@@ -189,7 +189,7 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	glog.Info("Starting generate-policy-cleanup controller")
 	defer glog.Info("Shutting down generate-policy-cleanup controller")
 
-	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.grSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.urSynced) {
 		glog.Error("generate-policy-cleanup controller: failed to sync informer cache")
 		return
 	}
@@ -225,33 +225,33 @@ func (c *Controller) handleErr(err error, key interface{}) {
 	}
 
 	if c.queue.NumRequeues(key) < maxRetries {
-		glog.Errorf("Error syncing Generate Request %v: %v", key, err)
+		glog.Errorf("Error syncing Update Request %v: %v", key, err)
 		c.queue.AddRateLimited(key)
 		return
 	}
 	utilruntime.HandleError(err)
-	glog.Infof("Dropping generate request %q out of the queue: %v", key, err)
+	glog.Infof("Dropping update request %q out of the queue: %v", key, err)
 	c.queue.Forget(key)
 }
 
-func (c *Controller) syncGenerateRequest(key string) error {
+func (c *Controller) syncUpdateRequest(key string) error {
 	var err error
 	startTime := time.Now()
-	glog.V(4).Infof("Started syncing GR %q (%v)", key, startTime)
+	glog.V(4).Infof("Started syncing UR %q (%v)", key, startTime)
 	defer func() {
-		glog.V(4).Infof("Finished syncing GR %q (%v)", key, time.Since(startTime))
+		glog.V(4).Infof("Finished syncing UR %q (%v)", key, time.Since(startTime))
 	}()
-	_, grName, err := cache.SplitMetaNamespaceKey(key)
+	_, urName, err := cache.SplitMetaNamespaceKey(key)
 	if errors.IsNotFound(err) {
-		glog.Infof("Generate Request %s has been deleted", key)
+		glog.Infof("Update Request %s has been deleted", key)
 		return nil
 	}
 	if err != nil {
 		return err
 	}
-	gr, err := c.grLister.Get(grName)
+	ur, err := c.urLister.Get(urName)
 	if err != nil {
 		return err
 	}
-	return c.processGR(*gr)
+	return c.processUR(*ur)
 }
@@ -7,7 +7,7 @@ import (
 
 // ControlInterface manages resource deletes
 type ControlInterface interface {
-	Delete(gr string) error
+	Delete(ur string) error
 }
 
 //Control provides implementation to manage resource
@@ -16,6 +16,6 @@ type Control struct {
 }
 
 //Delete deletes the specified resource
-func (c Control) Delete(gr string) error {
-	return c.client.KyvernoV1().GenerateRequests("kyverno").Delete(gr, &metav1.DeleteOptions{})
+func (c Control) Delete(ur string) error {
+	return c.client.KyvernoV1().UpdateRequests("kyverno").Delete(ur, &metav1.DeleteOptions{})
 }
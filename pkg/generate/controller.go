@@ -1,7 +1,9 @@
 package generate
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -26,7 +28,8 @@ const (
 	maxRetries = 5
 )
 
-// Controller manages the life-cycle for Generate-Requests and applies generate rule
+// Controller manages the life-cycle for Update-Requests and applies the generate rules,
+// or the mutate rules with targets, of their policy
 type Controller struct {
 	// dyanmic client implementation
 	client *dclient.Client
@@ -34,44 +37,51 @@ type Controller struct {
 	kyvernoClient *kyvernoclient.Clientset
 	// event generator interface
 	eventGen event.Interface
-	// handler for GR CR
-	syncHandler func(grKey string) error
-	// handler to enqueue GR
-	enqueueGR func(gr *kyverno.GenerateRequest)
+	// handler for UR CR, the context is cancelled when the controller is stopped
+	syncHandler func(ctx context.Context, urKey string) error
+	// handler to enqueue UR
+	enqueueUR func(ur *kyverno.UpdateRequest)
 
-	// grStatusControl is used to update GR status
+	// statusControl is used to update UR status
 	statusControl StatusControlInterface
-	// Gr that need to be synced
+	// UR that need to be synced
 	queue workqueue.RateLimitingInterface
 	// pLister can list/get cluster policy from the shared informer's store
 	pLister kyvernolister.ClusterPolicyLister
-	// grLister can list/get generate request from the shared informer's store
-	grLister kyvernolister.GenerateRequestNamespaceLister
+	// urLister can list/get update request from the shared informer's store
+	urLister kyvernolister.UpdateRequestNamespaceLister
 	// pSynced returns true if the Cluster policy store has been synced at least once
 	pSynced cache.InformerSynced
-	// grSynced returns true if the Generate Request store has been synced at least once
-	grSynced cache.InformerSynced
+	// urSynced returns true if the Update Request store has been synced at least once
+	urSynced cache.InformerSynced
 	// policy violation generator
 	pvGenerator policyviolation.GeneratorInterface
 	// dyanmic sharedinformer factory
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory
+	// resources are submitted in dry-run mode before they are persisted
+	dryRun bool
 	//TODO: list of generic informers
 	// only support Namespaces for re-evalutation on resource updates
 	nsInformer informers.GenericInformer
 
 	policyStatusListener policystatus.Listener
+
+	// clients impersonating the service accounts of the generate rules, by namespace/name
+	impersonatedClients map[string]*dclient.Client
+	impersonatedMutex   sync.Mutex
 }
 
-//NewController returns an instance of the Generate-Request Controller
+//NewController returns an instance of the Update-Request Controller
 func NewController(
 	kyvernoclient *kyvernoclient.Clientset,
 	client *dclient.Client,
 	pInformer kyvernoinformer.ClusterPolicyInformer,
-	grInformer kyvernoinformer.GenerateRequestInformer,
+	urInformer kyvernoinformer.UpdateRequestInformer,
 	eventGen event.Interface,
 	pvGenerator policyviolation.GeneratorInterface,
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory,
 	policyStatus policystatus.Listener,
+	dryRun bool,
 ) *Controller {
 	c := Controller{
 		client:        client,
@@ -79,10 +89,12 @@ func NewController(
 		eventGen:      eventGen,
 		pvGenerator:   pvGenerator,
 		//TODO: do the math for worst case back off and make sure cleanup runs after that
-		// as we dont want a deleted GR to be re-queue
-		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(1, 30), "generate-request"),
+		// as we dont want a deleted UR to be re-queue
+		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(1, 30), "update-request"),
 		dynamicInformer:      dynamicInformer,
 		policyStatusListener: policyStatus,
+		dryRun:               dryRun,
+		impersonatedClients:  map[string]*dclient.Client{},
 	}
 	c.statusControl = StatusControl{client: kyvernoclient}
 
@@ -91,20 +103,20 @@ func NewController(
 		// Deletion of policy will be handled by cleanup controller
 	}, 2*time.Minute)
 
-	grInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
-		AddFunc:    c.addGR,
-		UpdateFunc: c.updateGR,
-		DeleteFunc: c.deleteGR,
+	urInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addUR,
+		UpdateFunc: c.updateUR,
+		DeleteFunc: c.deleteUR,
 	}, 2*time.Minute)
 
-	c.enqueueGR = c.enqueue
-	c.syncHandler = c.syncGenerateRequest
+	c.enqueueUR = c.enqueue
+	c.syncHandler = c.syncUpdateRequest
 
 	c.pLister = pInformer.Lister()
-	c.grLister = grInformer.Lister().GenerateRequests("kyverno")
+	c.urLister = urInformer.Lister().UpdateRequests("kyverno")
 
 	c.pSynced = pInformer.Informer().HasSynced
-	c.grSynced = pInformer.Informer().HasSynced
+	c.urSynced = urInformer.Informer().HasSynced
 
 	//TODO: dynamic registration
 	// Only supported for namespaces
@@ -119,20 +131,20 @@ func NewController(
 func (c *Controller) updateGenericResource(old, cur interface{}) {
 	curR := cur.(*unstructured.Unstructured)
 
-	grs, err := c.grLister.GetGenerateRequestsForResource(curR.GetKind(), curR.GetNamespace(), curR.GetName())
+	urs, err := c.urLister.GetUpdateRequestsForResource(curR.GetKind(), curR.GetNamespace(), curR.GetName())
 	if err != nil {
-		glog.Errorf("failed to Generate Requests for resource %s/%s/%s: %v", curR.GetKind(), curR.GetNamespace(), curR.GetName(), err)
+		glog.Errorf("failed to Update Requests for resource %s/%s/%s: %v", curR.GetKind(), curR.GetNamespace(), curR.GetName(), err)
 		return
 	}
-	// re-evaluate the GR as the resource was updated
-	for _, gr := range grs {
-		c.enqueueGR(gr)
+	// re-evaluate the UR as the resource was updated
+	for _, ur := range urs {
+		c.enqueueUR(ur)
 	}
 
 }
 
-func (c *Controller) enqueue(gr *kyverno.GenerateRequest) {
-	key, err := cache.MetaNamespaceKeyFunc(gr)
+func (c *Controller) enqueue(ur *kyverno.UpdateRequest) {
+	key, err := cache.MetaNamespaceKeyFunc(ur)
 	if err != nil {
 		glog.Error(err)
 		return
@@ -149,56 +161,56 @@ func (c *Controller) updatePolicy(old, cur interface{}) {
 		return
 	}
 	glog.V(4).Infof("Updating Policy %s", oldP.Name)
-	// get the list of GR for the current Policy version
-	grs, err := c.grLister.GetGenerateRequestsForClusterPolicy(curP.Name)
+	// get the list of UR for the current Policy version
+	urs, err := c.urLister.GetUpdateRequestsForClusterPolicy(curP.Name)
 	if err != nil {
-		glog.Errorf("failed to Generate Requests for policy %s: %v", curP.Name, err)
+		glog.Errorf("failed to Update Requests for policy %s: %v", curP.Name, err)
 		return
 	}
-	// re-evaluate the GR as the policy was updated
-	for _, gr := range grs {
-		c.enqueueGR(gr)
+	// re-evaluate the UR as the policy was updated
+	for _, ur := range urs {
+		c.enqueueUR(ur)
 	}
 }
 
-func (c *Controller) addGR(obj interface{}) {
-	gr := obj.(*kyverno.GenerateRequest)
-	c.enqueueGR(gr)
+func (c *Controller) addUR(obj interface{}) {
+	ur := obj.(*kyverno.UpdateRequest)
+	c.enqueueUR(ur)
 }
 
-func (c *Controller) updateGR(old, cur interface{}) {
-	oldGr := old.(*kyverno.GenerateRequest)
-	curGr := cur.(*kyverno.GenerateRequest)
-	if oldGr.ResourceVersion == curGr.ResourceVersion {
+func (c *Controller) updateUR(old, cur interface{}) {
+	oldUR := old.(*kyverno.UpdateRequest)
+	curUR := cur.(*kyverno.UpdateRequest)
+	if oldUR.ResourceVersion == curUR.ResourceVersion {
 		// Periodic resync will send update events for all known Namespace.
 		// Two different versions of the same replica set will always have different RVs.
 		return
 	}
 	// only process the ones that are in "Pending"/"Completed" state
-	// if the Generate Request fails due to incorrect policy, it will be requeued during policy update
-	if curGr.Status.State == kyverno.Failed {
+	// if the Update Request fails due to incorrect policy, it will be requeued during policy update
+	if curUR.Status.State == kyverno.Failed {
 		return
 	}
-	c.enqueueGR(curGr)
+	c.enqueueUR(curUR)
 }
 
-func (c *Controller) deleteGR(obj interface{}) {
-	gr, ok := obj.(*kyverno.GenerateRequest)
+func (c *Controller) deleteUR(obj interface{}) {
+	ur, ok := obj.(*kyverno.UpdateRequest)
 	if !ok {
 		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
 		if !ok {
 			glog.Info(fmt.Errorf("Couldn't get object from tombstone %#v", obj))
 			return
 		}
-		_, ok = tombstone.Obj.(*kyverno.GenerateRequest)
+		_, ok = tombstone.Obj.(*kyverno.UpdateRequest)
 		if !ok {
-			glog.Info(fmt.Errorf("Tombstone contained object that is not a Generate Request %#v", obj))
+			glog.Info(fmt.Errorf("Tombstone contained object that is not an Update Request %#v", obj))
 			return
 		}
 	}
-	glog.V(4).Infof("Deleting GR %s", gr.Name)
+	glog.V(4).Infof("Deleting UR %s", ur.Name)
 	// sync Handler will remove it from the queue
-	c.enqueueGR(gr)
+	c.enqueueUR(ur)
 }
 
 //Run ...
@@ -209,30 +221,33 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	glog.Info("Starting generate-policy controller")
 	defer glog.Info("Shutting down generate-policy controller")
 
-	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.grSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.urSynced) {
 		glog.Error("generate-policy controller: failed to sync informer cache")
 		return
 	}
+	// the calls of the update requests in flight are cancelled when the controller is stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
+		go wait.Until(func() { c.worker(ctx) }, time.Second, stopCh)
 	}
 	<-stopCh
 }
 
 // worker runs a worker thread that just dequeues items, processes them, and marks them done.
 // It enforces that the syncHandler is never invoked concurrently with the same key.
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
+func (c *Controller) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
 	}
 }
 
-func (c *Controller) processNextWorkItem() bool {
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	key, quit := c.queue.Get()
 	if quit {
 		return false
 	}
 	defer c.queue.Done(key)
-	err := c.syncHandler(key.(string))
+	err := c.syncHandler(ctx, key.(string))
 	c.handleErr(err, key)
 
 	return true
@@ -245,31 +260,31 @@ func (c *Controller) handleErr(err error, key interface{}) {
 	}
 
 	if c.queue.NumRequeues(key) < maxRetries {
-		glog.Errorf("Error syncing Generate Request %v: %v", key, err)
+		glog.Errorf("Error syncing Update Request %v: %v", key, err)
 		c.queue.AddRateLimited(key)
 		return
 	}
 	utilruntime.HandleError(err)
-	glog.Infof("Dropping generate request %q out of the queue: %v", key, err)
+	glog.Infof("Dropping update request %q out of the queue: %v", key, err)
 	c.queue.Forget(key)
 }
 
-func (c *Controller) syncGenerateRequest(key string) error {
+func (c *Controller) syncUpdateRequest(ctx context.Context, key string) error {
 	var err error
 	startTime := time.Now()
-	glog.V(4).Infof("Started syncing GR %q (%v)", key, startTime)
+	glog.V(4).Infof("Started syncing UR %q (%v)", key, startTime)
 	defer func() {
-		glog.V(4).Infof("Finished syncing GR %q (%v)", key, time.Since(startTime))
+		glog.V(4).Infof("Finished syncing UR %q (%v)", key, time.Since(startTime))
 	}()
-	_, grName, err := cache.SplitMetaNamespaceKey(key)
+	_, urName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		return err
 	}
 
-	gr, err := c.grLister.Get(grName)
+	ur, err := c.urLister.Get(urName)
 	if err != nil {
 		glog.V(4).Info(err)
 		return err
 	}
-	return c.processGR(gr)
+	return c.processUR(ctx, ur)
 }
@@ -1,6 +1,7 @@
 package generate
 
 import (
+	gocontext "context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -12,37 +13,36 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/context"
 	"github.com/nirmata/kyverno/pkg/engine/validate"
 	"github.com/nirmata/kyverno/pkg/engine/variables"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-func (c *Controller) processGR(gr *kyverno.GenerateRequest) error {
+func (c *Controller) processUR(ctx gocontext.Context, ur *kyverno.UpdateRequest) error {
 	var err error
 	var resource *unstructured.Unstructured
-	var genResources []kyverno.ResourceSpec
+	var resources []kyverno.ResourceSpec
 	// 1 - Check if the resource exists
-	resource, err = getResource(c.client, gr.Spec.Resource)
+	resource, err = getResource(ctx, c.client, ur.Spec.Resource)
 	if err != nil {
 		// Dont update status
 		glog.V(4).Infof("resource does not exist or is yet to be created, requeuing: %v", err)
 		return err
 	}
-	// 2 - Apply the generate policy on the resource
-	genResources, err = c.applyGenerate(*resource, *gr)
+	// 2 - Apply the policy on the resource
+	switch ur.Spec.Type {
+	case kyverno.Mutate:
+		resources, err = c.applyMutate(ctx, *resource, *ur)
+	default:
+		resources, err = c.applyGenerate(*resource, *ur)
+	}
 	// 3 - Report Events
-	reportEvents(err, c.eventGen, *gr, *resource)
+	reportEvents(err, c.eventGen, *ur, *resource)
 	// 4 - Update Status
-	return updateStatus(c.statusControl, *gr, err, genResources)
+	return updateStatus(c.statusControl, *ur, err, resources)
 }
 
-func (c *Controller) applyGenerate(resource unstructured.Unstructured, gr kyverno.GenerateRequest) ([]kyverno.ResourceSpec, error) {
-	// Get the list of rules to be applied
-	// get policy
-	policy, err := c.pLister.Get(gr.Spec.Policy)
-	if err != nil {
-		glog.V(4).Infof("policy %s not found: %v", gr.Spec.Policy, err)
-		return nil, nil
-	}
+// newPolicyContext returns the context to apply the policy for the trigger resource of the update request
+func newPolicyContext(policy kyverno.ClusterPolicy, resource unstructured.Unstructured, ur kyverno.UpdateRequest) (*engine.PolicyContext, error) {
 	// build context
 	ctx := context.NewContext()
 	resourceRaw, err := resource.MarshalJSON()
@@ -55,45 +55,59 @@ func (c *Controller) applyGenerate(resource unstructured.Unstructured, gr kyvern
 		glog.Infof("Failed to load resource in context: %v", err)
 		return nil, err
 	}
-	err = ctx.AddUserInfo(gr.Spec.Context.UserRequestInfo)
+	err = ctx.AddUserInfo(ur.Spec.Context.UserRequestInfo)
 	if err != nil {
 		glog.Infof("Failed to load userInfo in context: %v", err)
 		return nil, err
 	}
-	err = ctx.AddSA(gr.Spec.Context.UserRequestInfo.AdmissionUserInfo.Username)
+	err = ctx.AddSA(ur.Spec.Context.UserRequestInfo.AdmissionUserInfo.Username)
 	if err != nil {
 		glog.Infof("Failed to load serviceAccount in context: %v", err)
 		return nil, err
 	}
 
-	policyContext := engine.PolicyContext{
+	return &engine.PolicyContext{
 		NewResource:   resource,
-		Policy:        *policy,
+		Policy:        policy,
 		Context:       ctx,
-		AdmissionInfo: gr.Spec.Context.UserRequestInfo,
+		AdmissionInfo: ur.Spec.Context.UserRequestInfo,
+	}, nil
+}
+
+func (c *Controller) applyGenerate(resource unstructured.Unstructured, ur kyverno.UpdateRequest) ([]kyverno.ResourceSpec, error) {
+	// Get the list of rules to be applied
+	// get policy
+	policy, err := c.pLister.Get(ur.Spec.Policy)
+	if err != nil {
+		glog.V(4).Infof("policy %s not found: %v", ur.Spec.Policy, err)
+		return nil, nil
+	}
+	policyContext, err := newPolicyContext(*policy, resource, ur)
+	if err != nil {
+		return nil, err
 	}
 
 	// check if the policy still applies to the resource
-	engineResponse := engine.Generate(policyContext)
+	engineResponse := engine.Generate(*policyContext)
 	if len(engineResponse.PolicyResponse.Rules) == 0 {
-		glog.V(4).Infof("policy %s, dont not apply to resource %v", gr.Spec.Policy, gr.Spec.Resource)
-		return nil, fmt.Errorf("policy %s, dont not apply to resource %v", gr.Spec.Policy, gr.Spec.Resource)
+		glog.V(4).Infof("policy %s, dont not apply to resource %v", ur.Spec.Policy, ur.Spec.Resource)
+		return nil, fmt.Errorf("policy %s, dont not apply to resource %v", ur.Spec.Policy, ur.Spec.Resource)
 	}
 
 	// Apply the generate rule on resource
-	return c.applyGeneratePolicy(policyContext, gr)
+	return c.applyGeneratePolicy(*policyContext, ur)
 }
 
-func updateStatus(statusControl StatusControlInterface, gr kyverno.GenerateRequest, err error, genResources []kyverno.ResourceSpec) error {
+func updateStatus(statusControl StatusControlInterface, ur kyverno.UpdateRequest, err error, resources []kyverno.ResourceSpec) error {
 	if err != nil {
-		return statusControl.Failed(gr, err.Error(), genResources)
+		return statusControl.Failed(ur, err.Error(), resources)
 	}
 
-	// Generate request successfully processed
-	return statusControl.Success(gr, genResources)
+	// Update request successfully processed
+	return statusControl.Success(ur, resources)
 }
 
-func (c *Controller) applyGeneratePolicy(policyContext engine.PolicyContext, gr kyverno.GenerateRequest) ([]kyverno.ResourceSpec, error) {
+func (c *Controller) applyGeneratePolicy(policyContext engine.PolicyContext, ur kyverno.UpdateRequest) ([]kyverno.ResourceSpec, error) {
 	// List of generatedResources
 	var genResources []kyverno.ResourceSpec
 	// Get the response as the actions to be performed on the resource
@@ -108,6 +122,23 @@ func (c *Controller) applyGeneratePolicy(policyContext engine.PolicyContext, gr
 		return rcreationTime.Before(&pcreationTime)
 	}()
 
+	// verify that the API server, including other admission webhooks, accepts all the
+	// resources to be generated before any of them is persisted
+	if c.dryRun {
+		for _, rule := range policy.Spec.Rules {
+			if !rule.HasGenerate() {
+				continue
+			}
+			client, err := c.clientFor(rule)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := applyRule(client, rule, resource, ctx, processExisting, true); err != nil {
+				return nil, fmt.Errorf("dry-run of rule %s failed: %v", rule.Name, err)
+			}
+		}
+	}
+
 	ruleNameToProcessingTime := make(map[string]time.Duration)
 	for _, rule := range policy.Spec.Rules {
 		if !rule.HasGenerate() {
@@ -115,7 +146,11 @@ func (c *Controller) applyGeneratePolicy(policyContext engine.PolicyContext, gr
 		}
 
 		startTime := time.Now()
-		genResource, err := applyRule(c.client, rule, resource, ctx, processExisting)
+		client, err := c.clientFor(rule)
+		if err != nil {
+			return nil, err
+		}
+		genResource, err := applyRule(client, rule, resource, ctx, processExisting, false)
 		if err != nil {
 			return nil, err
 		}
@@ -124,7 +159,7 @@ func (c *Controller) applyGeneratePolicy(policyContext engine.PolicyContext, gr
 		genResources = append(genResources, genResource)
 	}
 
-	if gr.Status.State == "" {
+	if ur.Status.State == "" {
 		c.policyStatusListener.Send(generateSyncStats{
 			policyName:               policy.Name,
 			ruleNameToProcessingTime: ruleNameToProcessingTime,
@@ -134,6 +169,27 @@ func (c *Controller) applyGeneratePolicy(policyContext engine.PolicyContext, gr
 	return genResources, nil
 }
 
+// clientFor returns the client applying the generate rule. The client impersonates the service account
+// of the rule if it is set, the impersonating clients are created once per service account
+func (c *Controller) clientFor(rule kyverno.Rule) (*dclient.Client, error) {
+	sa := rule.Generation.ServiceAccount
+	if sa == (kyverno.ServiceAccountReference{}) {
+		return c.client, nil
+	}
+	c.impersonatedMutex.Lock()
+	defer c.impersonatedMutex.Unlock()
+	key := sa.Namespace + "/" + sa.Name
+	if client, ok := c.impersonatedClients[key]; ok {
+		return client, nil
+	}
+	client, err := c.client.ImpersonateServiceAccount(sa.Namespace, sa.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to impersonate service account %s of rule %s: %v", key, rule.Name, err)
+	}
+	c.impersonatedClients[key] = client
+	return client, nil
+}
+
 type generateSyncStats struct {
 	policyName               string
 	ruleNameToProcessingTime map[string]time.Duration
@@ -172,7 +228,9 @@ func updateGenerateExecutionTime(newTime time.Duration, oldAverageTimeString str
 	return time.Duration(newAverageTimeInNanoSeconds) * time.Nanosecond
 }
 
-func applyRule(client *dclient.Client, rule kyverno.Rule, resource unstructured.Unstructured, ctx context.EvalInterface, processExisting bool) (kyverno.ResourceSpec, error) {
+// applyRule creates or updates the resource generated by the rule
+// with dryRun set the resource is submitted to the API server but not persisted
+func applyRule(client *dclient.Client, rule kyverno.Rule, resource unstructured.Unstructured, ctx context.EvalInterface, processExisting bool, dryRun bool) (kyverno.ResourceSpec, error) {
 	var rdata map[string]interface{}
 	var err error
 	var mode ResourceMode
@@ -249,40 +307,41 @@ func applyRule(client *dclient.Client, rule kyverno.Rule, resource unstructured.
 	// - kyverno.io/generated-by: kind/namespace/name (trigger resource)
 	manageLabels(newResource, resource)
 
+	// Reset the server populated metadata, it is either copied from the clone source
+	// or must not be used as a precondition when applying the resource
+	newResource.SetResourceVersion("")
+	newResource.SetUID("")
+	newResource.SetSelfLink("")
+	newResource.SetCreationTimestamp(metav1.Time{})
+	newResource.SetManagedFields(nil)
+
+	// Create or update the resource with server-side apply. The applied object only contains the
+	// fields of the rule, the ownership of these fields is forced: resources generated before
+	// server-side apply was used are owned by the manager of the update, and would conflict
 	if mode == Create {
-		// Reset resource version
-		newResource.SetResourceVersion("")
-		// Create the resource
 		glog.V(4).Infof("Creating new resource %s/%s/%s", genKind, genNamespace, genName)
-		_, err = client.CreateResource(genKind, genNamespace, newResource, false)
-		if err != nil {
-			// Failed to create resource
-			return noGenResource, err
-		}
-		glog.V(4).Infof("Created new resource %s/%s/%s", genKind, genNamespace, genName)
-
 	} else if mode == Update {
 		glog.V(4).Infof("Updating existing resource %s/%s/%s", genKind, genNamespace, genName)
-		// Update the resource
-		_, err := client.UpdateResource(genKind, genNamespace, newResource, false)
-		if err != nil {
-			// Failed to update resource
-			return noGenResource, err
-		}
-		glog.V(4).Infof("Updated existing resource %s/%s/%s", genKind, genNamespace, genName)
 	}
+	_, err = client.ApplyResource(gocontext.TODO(), genKind, genNamespace, genName, newResource, true, dryRun)
+	if err != nil {
+		// Failed to apply resource
+		return noGenResource, err
+	}
+	glog.V(4).Infof("Applied resource %s/%s/%s (dry-run: %t)", genKind, genNamespace, genName, dryRun)
 
 	return newGenResource, nil
 }
 
 func manageData(kind, namespace, name string, data map[string]interface{}, client *dclient.Client, resource unstructured.Unstructured) (map[string]interface{}, ResourceMode, error) {
 	// check if resource to be generated exists
-	obj, err := client.GetResource(kind, namespace, name)
-	if apierrors.IsNotFound(err) {
+	obj, err := client.GetResource(gocontext.TODO(), kind, namespace, name)
+	switch err.(type) {
+	case nil:
+	case *dclient.NotFound:
 		glog.V(4).Infof("Resource %s/%s/%s does not exists, will try to create", kind, namespace, name)
 		return data, Create, nil
-	}
-	if err != nil {
+	default:
 		//something wrong while fetching resource
 		// client-errors
 		return nil, Skip, err
@@ -301,13 +360,13 @@ func manageData(kind, namespace, name string, data map[string]interface{}, clien
 
 func manageClone(kind, namespace, name string, clone map[string]interface{}, client *dclient.Client, resource unstructured.Unstructured) (map[string]interface{}, ResourceMode, error) {
 	// check if resource to be generated exists
-	_, err := client.GetResource(kind, namespace, name)
-	if err == nil {
+	_, err := client.GetResource(gocontext.TODO(), kind, namespace, name)
+	switch err.(type) {
+	case nil:
 		// resource does exists, not need to process further as it is already in expected state
 		return nil, Skip, nil
-	}
-	//TODO: check this
-	if !apierrors.IsNotFound(err) {
+	case *dclient.NotFound:
+	default:
 		//something wrong while fetching resource
 		return nil, Skip, err
 	}
@@ -328,9 +387,13 @@ func manageClone(kind, namespace, name string, clone map[string]interface{}, cli
 
 	glog.V(4).Infof("check if resource %s/%s/%s exists", kind, newRNs, newRName)
 	// check if the resource as reference in clone exists?
-	obj, err := client.GetResource(kind, newRNs, newRName)
-	if err != nil {
-		return nil, Skip, fmt.Errorf("reference clone resource %s/%s/%s not found. %v", kind, newRNs, newRName, err)
+	obj, err := client.GetCachedResource(gocontext.TODO(), kind, newRNs, newRName)
+	switch err.(type) {
+	case nil:
+	case *dclient.NotFound:
+		return nil, Skip, fmt.Errorf("reference clone resource %s/%s/%s not found", kind, newRNs, newRName)
+	default:
+		return nil, Skip, fmt.Errorf("failed to get reference clone resource %s/%s/%s: %v", kind, newRNs, newRName, err)
 	}
 	// create the resource based on the reference clone
 	return obj.UnstructuredContent(), Create, nil
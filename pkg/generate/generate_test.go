@@ -0,0 +1,27 @@
+package generate
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_ClientFor(t *testing.T) {
+	client, err := dclient.NewMockClient(runtime.NewScheme())
+	assert.NilError(t, err)
+	c := &Controller{client: client, impersonatedClients: map[string]*dclient.Client{}}
+
+	rule := kyverno.Rule{Name: "generate-netpol"}
+	ruleClient, err := c.clientFor(rule)
+	assert.NilError(t, err)
+	assert.Assert(t, ruleClient == client)
+
+	// the mock client is not created from a rest config and cannot impersonate
+	rule.Generation.ServiceAccount = kyverno.ServiceAccountReference{Namespace: "kyverno", Name: "generator"}
+	_, err = c.clientFor(rule)
+	assert.ErrorContains(t, err, "failed to impersonate service account kyverno/generator of rule generate-netpol")
+	assert.Equal(t, len(c.impersonatedClients), 0)
+}
@@ -0,0 +1,59 @@
+package generate
+
+import (
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernoclient "github.com/nirmata/kyverno/pkg/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MigrateGenerateRequests replaces the generate requests created by previous versions with
+// update requests, the generated resources are kept so that they are still cleaned up.
+// The update request is named after the generate request, so that a migration interrupted
+// before the generate request is deleted does not create the update request twice
+func MigrateGenerateRequests(client kyvernoclient.Interface) error {
+	grs, err := client.KyvernoV1().GenerateRequests("kyverno").List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, gr := range grs.Items {
+		ur := &kyverno.UpdateRequest{
+			Spec: kyverno.UpdateRequestSpec{
+				Type:     kyverno.Generate,
+				Policy:   gr.Spec.Policy,
+				Resource: gr.Spec.Resource,
+				Context: kyverno.UpdateRequestContext{
+					UserRequestInfo: gr.Spec.Context.UserRequestInfo,
+				},
+			},
+		}
+		ur.SetName("ur-" + gr.Name)
+		ur.SetNamespace("kyverno")
+		created, err := client.KyvernoV1().UpdateRequests("kyverno").Create(ur)
+		if err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return err
+			}
+			if created, err = client.KyvernoV1().UpdateRequests("kyverno").Get(ur.Name, metav1.GetOptions{}); err != nil {
+				return err
+			}
+		}
+		ur = created
+		if gr.Status.State != "" {
+			ur.Status = kyverno.UpdateRequestStatus{
+				State:              gr.Status.State,
+				Message:            gr.Status.Message,
+				GeneratedResources: gr.Status.GeneratedResources,
+			}
+			if _, err := client.KyvernoV1().UpdateRequests("kyverno").UpdateStatus(ur); err != nil {
+				return err
+			}
+		}
+		if err := client.KyvernoV1().GenerateRequests("kyverno").Delete(gr.Name, &metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		glog.V(4).Infof("migrated generate request %s to update request %s", gr.Name, ur.Name)
+	}
+	return nil
+}
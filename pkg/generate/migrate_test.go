@@ -0,0 +1,42 @@
+package generate
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/client/clientset/versioned/fake"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_MigrateGenerateRequests(t *testing.T) {
+	gr := &kyverno.GenerateRequest{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kyverno", Name: "gr-abcde"},
+		Spec: kyverno.GenerateRequestSpec{
+			Policy:   "add-network-policy",
+			Resource: kyverno.ResourceSpec{Kind: "Namespace", Name: "team-a"},
+		},
+		Status: kyverno.GenerateRequestStatus{State: kyverno.Completed},
+	}
+	pending := gr.DeepCopy()
+	pending.Name = "gr-fghij"
+	pending.Status = kyverno.GenerateRequestStatus{}
+	// the update request of a previous, interrupted, migration
+	existing := &kyverno.UpdateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "kyverno", Name: "ur-gr-abcde"}}
+	client := fake.NewSimpleClientset(gr, pending, existing)
+
+	assert.NilError(t, MigrateGenerateRequests(client))
+
+	urs, err := client.KyvernoV1().UpdateRequests("kyverno").List(metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(urs.Items), 2)
+	migrated, err := client.KyvernoV1().UpdateRequests("kyverno").Get("ur-gr-abcde", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, migrated.Status.State, kyverno.Completed)
+	migrated, err = client.KyvernoV1().UpdateRequests("kyverno").Get("ur-gr-fghij", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, migrated.Spec.Policy, "add-network-policy")
+	grs, err := client.KyvernoV1().GenerateRequests("kyverno").List(metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(grs.Items), 0)
+}
@@ -0,0 +1,151 @@
+package generate
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/mutate"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	patchTypes "k8s.io/apimachinery/pkg/types"
+)
+
+// applyMutate mutates the existing targets of the mutate rules that apply to the trigger resource,
+// and returns the targets that were processed
+func (c *Controller) applyMutate(ctx gocontext.Context, resource unstructured.Unstructured, ur kyverno.UpdateRequest) ([]kyverno.ResourceSpec, error) {
+	policy, err := c.pLister.Get(ur.Spec.Policy)
+	if err != nil {
+		glog.V(4).Infof("policy %s not found: %v", ur.Spec.Policy, err)
+		return nil, nil
+	}
+	policyContext, err := newPolicyContext(*policy, resource, ur)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if the policy still applies to the resource
+	engineResponse := engine.MutateExisting(*policyContext)
+	if len(engineResponse.PolicyResponse.Rules) == 0 {
+		glog.V(4).Infof("policy %s, dont not apply to resource %v", ur.Spec.Policy, ur.Spec.Resource)
+		return nil, fmt.Errorf("policy %s, dont not apply to resource %v", ur.Spec.Policy, ur.Spec.Resource)
+	}
+
+	var mutatedResources []kyverno.ResourceSpec
+	for _, ruleResponse := range engineResponse.PolicyResponse.Rules {
+		rule := getRule(*policy, ruleResponse.Name)
+		for _, target := range rule.Mutation.Targets {
+			spec, err := substituteTarget(policyContext.Context, target)
+			if err != nil {
+				return mutatedResources, fmt.Errorf("failed to substitute variables in the targets of rule %s: %v", rule.Name, err)
+			}
+			if err := c.mutateTarget(ctx, policyContext.Context, rule, spec); err != nil {
+				return mutatedResources, fmt.Errorf("failed to mutate target %s/%s/%s of rule %s: %v", spec.Kind, spec.Namespace, spec.Name, rule.Name, err)
+			}
+			mutatedResources = append(mutatedResources, spec)
+		}
+	}
+	return mutatedResources, nil
+}
+
+// mutateTarget applies the overlay and the patches of the rule to the target, the target is only
+// patched if it was changed. The JSON patches only change the mutated fields, so that the changes
+// made to the other fields since the target was read are kept
+func (c *Controller) mutateTarget(ctx gocontext.Context, evalCtx context.EvalInterface, rule kyverno.Rule, spec kyverno.ResourceSpec) error {
+	target, err := getResource(ctx, c.client, spec)
+	if err != nil {
+		return err
+	}
+	_, patches, err := mutateResource(evalCtx, rule, *target)
+	if err != nil {
+		return err
+	}
+	if len(patches) == 0 {
+		glog.V(4).Infof("target %s/%s/%s of rule %s is already mutated", spec.Kind, spec.Namespace, spec.Name, rule.Name)
+		return nil
+	}
+	_, err = c.client.PatchResource(ctx, spec.Kind, spec.Namespace, spec.Name, patchTypes.JSONPatchType, utils.JoinPatches(patches), false)
+	return err
+}
+
+// mutateResource applies the overlay and the patches of the rule, and returns the patched resource and
+// the JSON patches of the overlay and of the patches. No patches are returned if the resource is not changed
+func mutateResource(ctx context.EvalInterface, rule kyverno.Rule, resource unstructured.Unstructured) (unstructured.Unstructured, [][]byte, error) {
+	patchedResource := resource
+	var patches [][]byte
+	if rule.Mutation.Overlay != nil {
+		// substitute the variables on a copy, the overlay belongs to the cached policy
+		overlay, err := copyJSON(rule.Mutation.Overlay)
+		if err != nil {
+			return resource, nil, err
+		}
+		if overlay, err = variables.SubstituteVars(ctx, overlay); err != nil {
+			return resource, nil, err
+		}
+		var ruleResponse response.RuleResponse
+		ruleResponse, patchedResource = mutate.ProcessOverlay(rule.Name, overlay, patchedResource)
+		if !ruleResponse.Success {
+			return resource, nil, fmt.Errorf(ruleResponse.Message)
+		}
+		patches = append(patches, ruleResponse.Patches...)
+	}
+	if rule.Mutation.Patches != nil {
+		var ruleResponse response.RuleResponse
+		ruleResponse, patchedResource = mutate.ProcessPatches(rule, patchedResource)
+		if !ruleResponse.Success {
+			return resource, nil, fmt.Errorf(ruleResponse.Message)
+		}
+		patches = append(patches, ruleResponse.Patches...)
+	}
+	if reflect.DeepEqual(resource.Object, patchedResource.Object) {
+		return resource, nil, nil
+	}
+	return patchedResource, patches, nil
+}
+
+// substituteTarget substitutes the variables in the target, e.g. {{request.object.metadata.namespace}}
+func substituteTarget(ctx context.EvalInterface, target kyverno.ResourceSpec) (kyverno.ResourceSpec, error) {
+	var spec kyverno.ResourceSpec
+	raw, err := json.Marshal(target)
+	if err != nil {
+		return spec, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return spec, err
+	}
+	if document, err = variables.SubstituteVars(ctx, document); err != nil {
+		return spec, err
+	}
+	if raw, err = json.Marshal(document); err != nil {
+		return spec, err
+	}
+	err = json.Unmarshal(raw, &spec)
+	return spec, err
+}
+
+func copyJSON(document interface{}) (interface{}, error) {
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var copy interface{}
+	err = json.Unmarshal(raw, &copy)
+	return copy, err
+}
+
+func getRule(policy kyverno.ClusterPolicy, name string) kyverno.Rule {
+	for _, rule := range policy.Spec.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return kyverno.Rule{}
+}
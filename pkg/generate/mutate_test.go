@@ -0,0 +1,107 @@
+package generate
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func Test_MutateTarget(t *testing.T) {
+	rawRule := []byte(`
+	{
+		"name": "annotate-deployment",
+		"mutate": {
+			"targets": [
+				{"kind": "Deployment", "namespace": "{{request.object.metadata.namespace}}", "name": "app"}
+			],
+			"overlay": {
+				"spec": {"template": {"metadata": {"annotations": {"kyverno.io/config-version": "{{request.object.metadata.resourceVersion}}"}}}}
+			}
+		}
+	}`)
+	rawTrigger := []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "app-config", "namespace": "prod", "resourceVersion": "42"}}`)
+	rawTarget := []byte(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app", "namespace": "prod"}, "spec": {"template": {"metadata": {"labels": {"app": "app"}}}}}`)
+
+	var rule kyverno.Rule
+	assert.NilError(t, json.Unmarshal(rawRule, &rule))
+	ctx := context.NewContext()
+	assert.NilError(t, ctx.AddResource(rawTrigger))
+
+	spec, err := substituteTarget(ctx, rule.Mutation.Targets[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, spec, kyverno.ResourceSpec{Kind: "Deployment", Namespace: "prod", Name: "app"})
+
+	var target unstructured.Unstructured
+	assert.NilError(t, target.UnmarshalJSON(rawTarget))
+	patchedTarget, patches, err := mutateResource(ctx, rule, target)
+	assert.NilError(t, err)
+	assert.Assert(t, len(patches) != 0)
+	annotations, _, _ := unstructured.NestedStringMap(patchedTarget.Object, "spec", "template", "metadata", "annotations")
+	assert.DeepEqual(t, annotations, map[string]string{"kyverno.io/config-version": "42"})
+
+	// the patches change the target as the rule
+	patched, err := utils.ApplyPatches(rawTarget, patches)
+	assert.NilError(t, err)
+	var patchedRaw unstructured.Unstructured
+	assert.NilError(t, patchedRaw.UnmarshalJSON(patched))
+	assert.DeepEqual(t, patchedRaw.Object, patchedTarget.Object)
+
+	// the variables of the cached rule are not substituted
+	overlay, err := json.Marshal(rule.Mutation.Overlay)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(overlay), "{{request.object.metadata.resourceVersion}}"))
+
+	// applying the rule again does not change the target
+	_, patches, err = mutateResource(ctx, rule, patchedTarget)
+	assert.NilError(t, err)
+	assert.Equal(t, len(patches), 0)
+}
+
+func Test_MutateTarget_Patch(t *testing.T) {
+	rawRule := []byte(`
+	{
+		"name": "annotate-deployment",
+		"mutate": {
+			"targets": [
+				{"kind": "Deployment", "namespace": "prod", "name": "app"}
+			],
+			"overlay": {
+				"spec": {"template": {"metadata": {"annotations": {"kyverno.io/config-version": "42"}}}}
+			}
+		}
+	}`)
+	var rule kyverno.Rule
+	assert.NilError(t, json.Unmarshal(rawRule, &rule))
+
+	target := &unstructured.Unstructured{}
+	assert.NilError(t, target.UnmarshalJSON([]byte(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app", "namespace": "prod"}, "spec": {"replicas": 2, "template": {"metadata": {"labels": {"app": "app"}}}}}`)))
+	client, err := dclient.NewMockClient(runtime.NewScheme(), target)
+	assert.NilError(t, err)
+	client.SetDiscovery(dclient.NewFakeDiscoveryClient([]schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+	}))
+	c := &Controller{client: client}
+
+	assert.NilError(t, c.mutateTarget(gocontext.TODO(), context.NewContext(), rule, rule.Mutation.Targets[0]))
+	mutated, err := client.GetResource(gocontext.TODO(), "Deployment", "prod", "app")
+	assert.NilError(t, err)
+	annotations, _, _ := unstructured.NestedStringMap(mutated.Object, "spec", "template", "metadata", "annotations")
+	assert.DeepEqual(t, annotations, map[string]string{"kyverno.io/config-version": "42"})
+	replicas, _, _ := unstructured.NestedInt64(mutated.Object, "spec", "replicas")
+	assert.Equal(t, replicas, int64(2))
+
+	// the calls are bounded by the context of the update request
+	cancelled, cancel := gocontext.WithCancel(gocontext.TODO())
+	cancel()
+	assert.ErrorContains(t, c.mutateTarget(cancelled, context.NewContext(), rule, rule.Mutation.Targets[0]), "context canceled")
+}
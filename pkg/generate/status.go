@@ -8,42 +8,51 @@ import (
 
 //StatusControlInterface provides interface to update status subresource
 type StatusControlInterface interface {
-	Failed(gr kyverno.GenerateRequest, message string, genResources []kyverno.ResourceSpec) error
-	Success(gr kyverno.GenerateRequest, genResources []kyverno.ResourceSpec) error
+	Failed(ur kyverno.UpdateRequest, message string, resources []kyverno.ResourceSpec) error
+	Success(ur kyverno.UpdateRequest, resources []kyverno.ResourceSpec) error
 }
 
-// StatusControl is default implementaation of GRStatusControlInterface
+// StatusControl is default implementaation of URStatusControlInterface
 type StatusControl struct {
 	client kyvernoclient.Interface
 }
 
-//Failed sets gr status.state to failed with message
-func (sc StatusControl) Failed(gr kyverno.GenerateRequest, message string, genResources []kyverno.ResourceSpec) error {
-	gr.Status.State = kyverno.Failed
-	gr.Status.Message = message
-	// Update Generated Resources
-	gr.Status.GeneratedResources = genResources
-	_, err := sc.client.KyvernoV1().GenerateRequests("kyverno").UpdateStatus(&gr)
+//Failed sets ur status.state to failed with message
+func (sc StatusControl) Failed(ur kyverno.UpdateRequest, message string, resources []kyverno.ResourceSpec) error {
+	ur.Status.State = kyverno.Failed
+	ur.Status.Message = message
+	// Update Generated or Mutated Resources
+	setResources(&ur, resources)
+	_, err := sc.client.KyvernoV1().UpdateRequests("kyverno").UpdateStatus(&ur)
 	if err != nil {
-		glog.V(4).Infof("FAILED: updated gr %s status to %s", gr.Name, string(kyverno.Failed))
+		glog.V(4).Infof("FAILED: updated ur %s status to %s", ur.Name, string(kyverno.Failed))
 		return err
 	}
-	glog.V(4).Infof("updated gr %s status to %s", gr.Name, string(kyverno.Failed))
+	glog.V(4).Infof("updated ur %s status to %s", ur.Name, string(kyverno.Failed))
 	return nil
 }
 
-// Success sets the gr status.state to completed and clears message
-func (sc StatusControl) Success(gr kyverno.GenerateRequest, genResources []kyverno.ResourceSpec) error {
-	gr.Status.State = kyverno.Completed
-	gr.Status.Message = ""
-	// Update Generated Resources
-	gr.Status.GeneratedResources = genResources
+// Success sets the ur status.state to completed and clears message
+func (sc StatusControl) Success(ur kyverno.UpdateRequest, resources []kyverno.ResourceSpec) error {
+	ur.Status.State = kyverno.Completed
+	ur.Status.Message = ""
+	// Update Generated or Mutated Resources
+	setResources(&ur, resources)
 
-	_, err := sc.client.KyvernoV1().GenerateRequests("kyverno").UpdateStatus(&gr)
+	_, err := sc.client.KyvernoV1().UpdateRequests("kyverno").UpdateStatus(&ur)
 	if err != nil {
-		glog.V(4).Infof("FAILED: updated gr %s status to %s", gr.Name, string(kyverno.Completed))
+		glog.V(4).Infof("FAILED: updated ur %s status to %s", ur.Name, string(kyverno.Completed))
 		return err
 	}
-	glog.V(4).Infof("updated gr %s status to %s", gr.Name, string(kyverno.Completed))
+	glog.V(4).Infof("updated ur %s status to %s", ur.Name, string(kyverno.Completed))
 	return nil
 }
+
+// setResources records the resources processed by the request
+func setResources(ur *kyverno.UpdateRequest, resources []kyverno.ResourceSpec) {
+	if ur.Spec.Type == kyverno.Mutate {
+		ur.Status.MutatedResources = resources
+		return
+	}
+	ur.Status.GeneratedResources = resources
+}
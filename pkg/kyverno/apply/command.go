@@ -1,24 +1,24 @@
 package apply
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
-	"path/filepath"
+	"time"
 
+	"github.com/nirmata/kyverno/pkg/kyverno/common"
 	"github.com/nirmata/kyverno/pkg/kyverno/sanitizedError"
 
 	policy2 "github.com/nirmata/kyverno/pkg/policy"
 
 	"github.com/golang/glog"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"k8s.io/client-go/discovery"
 
-	"k8s.io/apimachinery/pkg/util/yaml"
-
 	"github.com/nirmata/kyverno/pkg/engine"
 
 	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
@@ -28,6 +28,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/openapi"
 	"github.com/spf13/cobra"
 	yamlv2 "gopkg.in/yaml.v2"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -38,6 +40,8 @@ func Command() *cobra.Command {
 	var cmd *cobra.Command
 	var resourcePaths []string
 	var cluster bool
+	var serverDryRun bool
+	var crdPaths []string
 
 	kubernetesConfig := genericclioptions.NewConfigFlags(true)
 
@@ -59,7 +63,11 @@ func Command() *cobra.Command {
 				return sanitizedError.New(fmt.Sprintf("Specify path to resource file or cluster name"))
 			}
 
-			policies, err := getPolicies(policyPaths)
+			if err := openapi.LoadCRDs(crdPaths); err != nil {
+				return sanitizedError.New(fmt.Sprintf("Could not load custom resource definitions: %v", err))
+			}
+
+			policies, err := common.GetPolicies(policyPaths)
 			if err != nil {
 				if !sanitizedError.IsErrorSanitized(err) {
 					return sanitizedError.New("Could not parse policy paths")
@@ -68,6 +76,11 @@ func Command() *cobra.Command {
 				}
 			}
 
+			for _, policy := range policies {
+				setFalse := false
+				policy.Spec.Background = &setFalse
+			}
+
 			for _, policy := range policies {
 				err := policy2.Validate(*policy)
 				if err != nil {
@@ -88,13 +101,24 @@ func Command() *cobra.Command {
 				return sanitizedError.New(fmt.Errorf("Issues fetching resources").Error())
 			}
 
+			// submits the mutated resources to the API server in dry-run mode
+			var dryRun *dryRunner
+			if serverDryRun {
+				stopCh := make(chan struct{})
+				defer close(stopCh)
+				dryRun, err = newDryRunner(kubernetesConfig, stopCh)
+				if err != nil {
+					return sanitizedError.New(fmt.Errorf("Issues with kubernetes Config").Error())
+				}
+			}
+
 			for i, policy := range policies {
 				for j, resource := range resources {
 					if !(j == 0 && i == 0) {
 						fmt.Printf("\n\n=======================================================================\n")
 					}
 
-					err = applyPolicyOnResource(policy, resource)
+					err = applyPolicyOnResource(policy, resource, dryRun)
 					if err != nil {
 						return sanitizedError.New(fmt.Errorf("Issues applying policy %v on resource %v", policy.Name, resource.GetName()).Error())
 					}
@@ -107,6 +131,8 @@ func Command() *cobra.Command {
 
 	cmd.Flags().StringArrayVarP(&resourcePaths, "resource", "r", []string{}, "Path to resource files")
 	cmd.Flags().BoolVarP(&cluster, "cluster", "c", false, "Checks if policies should be applied to cluster in the current context")
+	cmd.Flags().BoolVar(&serverDryRun, "server-dry-run", false, "Submits the mutated resources to the API server in the current context in dry-run mode to verify that they would be accepted")
+	cmd.Flags().StringArrayVar(&crdPaths, "crd", []string{}, "Path to custom resource definition files, used to validate policies on custom resources")
 
 	return cmd
 }
@@ -186,94 +212,6 @@ func getResourcesOfTypeFromCluster(resourceTypes []string, dClient discovery.Cac
 	return resources, nil
 }
 
-func getPoliciesInDir(path string) ([]*v1.ClusterPolicy, error) {
-	var policies []*v1.ClusterPolicy
-
-	files, err := ioutil.ReadDir(path)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, file := range files {
-		if file.IsDir() {
-			policiesFromDir, err := getPoliciesInDir(filepath.Join(path, file.Name()))
-			if err != nil {
-				return nil, err
-			}
-
-			policies = append(policies, policiesFromDir...)
-		} else {
-			policy, err := getPolicy(filepath.Join(path, file.Name()))
-			if err != nil {
-				return nil, err
-			}
-
-			policies = append(policies, policy)
-		}
-	}
-
-	return policies, nil
-}
-
-func getPolicies(paths []string) ([]*v1.ClusterPolicy, error) {
-	var policies = make([]*v1.ClusterPolicy, 0, len(paths))
-	for _, path := range paths {
-		path = filepath.Clean(path)
-
-		fileDesc, err := os.Stat(path)
-		if err != nil {
-			return nil, err
-		}
-
-		if fileDesc.IsDir() {
-			policiesFromDir, err := getPoliciesInDir(path)
-			if err != nil {
-				return nil, err
-			}
-
-			policies = append(policies, policiesFromDir...)
-		} else {
-			policy, err := getPolicy(path)
-			if err != nil {
-				return nil, err
-			}
-
-			policies = append(policies, policy)
-		}
-	}
-
-	for i := range policies {
-		setFalse := false
-		policies[i].Spec.Background = &setFalse
-	}
-
-	return policies, nil
-}
-
-func getPolicy(path string) (*v1.ClusterPolicy, error) {
-	policy := &v1.ClusterPolicy{}
-
-	file, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load file: %v", err)
-	}
-
-	policyBytes, err := yaml.ToJSON(file)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(policyBytes, policy); err != nil {
-		return nil, sanitizedError.New(fmt.Sprintf("failed to decode policy in %s", path))
-	}
-
-	if policy.TypeMeta.Kind != "ClusterPolicy" {
-		return nil, sanitizedError.New(fmt.Sprintf("resource %v is not a cluster policy", policy.Name))
-	}
-
-	return policy, nil
-}
-
 func getResource(path string) (*unstructured.Unstructured, error) {
 
 	resourceYaml, err := ioutil.ReadFile(path)
@@ -311,7 +249,7 @@ func getResource(path string) (*unstructured.Unstructured, error) {
 	return resource, nil
 }
 
-func applyPolicyOnResource(policy *v1.ClusterPolicy, resource *unstructured.Unstructured) error {
+func applyPolicyOnResource(policy *v1.ClusterPolicy, resource *unstructured.Unstructured, dryRun *dryRunner) error {
 
 	fmt.Printf("\n\nApplying Policy %s on Resource %s/%s/%s\n", policy.Name, resource.GetNamespace(), resource.GetKind(), resource.GetName())
 
@@ -353,6 +291,16 @@ func applyPolicyOnResource(policy *v1.ClusterPolicy, resource *unstructured.Unst
 		}
 	}
 
+	if dryRun != nil {
+		fmt.Printf("\n\nServer dry-run:")
+		if err := dryRun.run(mutateResponse.PatchedResource); err != nil {
+			fmt.Printf("\nResource is rejected by the API server: %v", err)
+		} else {
+			fmt.Printf("\nResource is accepted by the API server")
+		}
+		fmt.Printf("\n\n")
+	}
+
 	var policyHasGenerate bool
 	for _, rule := range policy.Spec.Rules {
 		if rule.HasGenerate() {
@@ -378,3 +326,55 @@ func applyPolicyOnResource(policy *v1.ClusterPolicy, resource *unstructured.Unst
 
 	return nil
 }
+
+// dryRunner submits resources to the API server of the current context without persisting them
+type dryRunner struct {
+	client *dclient.Client
+	mapper meta.RESTMapper
+	// namespace of the current context, used for namespaced resources read from files without namespace
+	namespace string
+}
+
+func newDryRunner(kubernetesConfig *genericclioptions.ConfigFlags, stopCh <-chan struct{}) (*dryRunner, error) {
+	restConfig, err := kubernetesConfig.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dclient.NewClient(restConfig, 10*time.Second, stopCh)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := kubernetesConfig.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	namespace, _, err := kubernetesConfig.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunner{client: client, mapper: mapper, namespace: namespace}, nil
+}
+
+// run submits the resource, resources read from the cluster are updated, resources read from files are created
+func (d *dryRunner) run(resource unstructured.Unstructured) error {
+	namespace := resource.GetNamespace()
+	if namespace == "" {
+		gvk := resource.GroupVersionKind()
+		mapping, err := d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return err
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace = d.namespace
+			resource.SetNamespace(namespace)
+		}
+	}
+
+	var err error
+	if resource.GetResourceVersion() != "" {
+		_, err = d.client.UpdateResource(context.TODO(), resource.GetKind(), namespace, &resource, true)
+	} else {
+		_, err = d.client.CreateResource(context.TODO(), resource.GetKind(), namespace, &resource, true)
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/kyverno/sanitizedError"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// GetPolicies returns the cluster policies of the files, and of the files of the directories
+// and their sub-directories
+func GetPolicies(paths []string) ([]*v1.ClusterPolicy, error) {
+	var policies []*v1.ClusterPolicy
+	for _, path := range paths {
+		path = filepath.Clean(path)
+
+		fileDesc, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileDesc.IsDir() {
+			files, err := ioutil.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			var subPaths []string
+			for _, file := range files {
+				subPaths = append(subPaths, filepath.Join(path, file.Name()))
+			}
+			policiesFromDir, err := GetPolicies(subPaths)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, policiesFromDir...)
+		} else {
+			policiesFromFile, err := GetPoliciesFromFile(path)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, policiesFromFile...)
+		}
+	}
+	return policies, nil
+}
+
+// GetPoliciesFromFile returns the cluster policies of the documents of a YAML or JSON file
+func GetPoliciesFromFile(path string) ([]*v1.ClusterPolicy, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file: %v", err)
+	}
+
+	var policies []*v1.ClusterPolicy
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(file), 4096)
+	for {
+		policy := &v1.ClusterPolicy{}
+		if err := decoder.Decode(policy); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, sanitizedError.New(fmt.Sprintf("failed to decode policy in %s", path))
+		}
+		// skip empty documents
+		if policy.Kind == "" && policy.Name == "" {
+			continue
+		}
+		if policy.Kind != "ClusterPolicy" {
+			return nil, sanitizedError.New(fmt.Sprintf("resource %v is not a cluster policy", policy.Name))
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_GetPolicies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policies")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	policies := `
+apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: policy1
+---
+---
+apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: policy2
+`
+	assert.NilError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "policies.yaml"), []byte(policies), 0644))
+	policy := `{"apiVersion": "kyverno.io/v1", "kind": "ClusterPolicy", "metadata": {"name": "policy3"}}`
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "policy.json"), []byte(policy), 0644))
+
+	result, err := GetPolicies([]string{dir})
+	assert.NilError(t, err)
+	assert.Equal(t, len(result), 3)
+	assert.Equal(t, result[0].Name, "policy3")
+	assert.Equal(t, result[1].Name, "policy1")
+	assert.Equal(t, result[2].Name, "policy2")
+
+	pod := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod1\n"
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "pod.yaml"), []byte(pod), 0644))
+	_, err = GetPolicies([]string{dir})
+	assert.Error(t, err, "resource pod1 is not a cluster policy")
+}
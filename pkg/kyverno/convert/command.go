@@ -0,0 +1,202 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/golang/glog"
+	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/gatekeeper"
+	"github.com/nirmata/kyverno/pkg/kyverno/sanitizedError"
+	policyvalidate "github.com/nirmata/kyverno/pkg/policy"
+	"github.com/spf13/cobra"
+	yamlv2 "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func Command() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:     "convert",
+		Short:   "Converts Gatekeeper constraints into kyverno policies",
+		Example: "kyverno convert /path/to/constraints.yaml /path/to/folderOfConstraintsAndTemplates > policies.yaml",
+		RunE: func(cmd *cobra.Command, paths []string) (err error) {
+			defer func() {
+				if err != nil {
+					if !sanitizedError.IsErrorSanitized(err) {
+						glog.V(4).Info(err)
+						err = fmt.Errorf("Internal error")
+					}
+				}
+			}()
+
+			objects, err := getObjects(paths)
+			if err != nil {
+				if !sanitizedError.IsErrorSanitized(err) {
+					return sanitizedError.New("Could not parse paths")
+				}
+				return err
+			}
+
+			policies, err := convert(objects, force, os.Stderr)
+			if err != nil {
+				return err
+			}
+			return printPolicies(policies, os.Stdout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Converts the constraints whose namespaceSelector or scope cannot be converted, ignoring them")
+	return cmd
+}
+
+// convert converts the constraints, the templates are only checked to be supported,
+// the constraints that cannot be converted are reported and skipped
+func convert(objects []unstructured.Unstructured, force bool, out io.Writer) ([]*v1.ClusterPolicy, error) {
+	var policies []*v1.ClusterPolicy
+	for _, object := range objects {
+		group := object.GroupVersionKind().Group
+		switch {
+		case group == gatekeeper.TemplateGroup && object.GetKind() == "ConstraintTemplate":
+			kind, err := gatekeeper.TemplateKind(object)
+			if err != nil {
+				return nil, sanitizedError.New(err.Error())
+			}
+			if !gatekeeper.IsSupported(kind) {
+				fmt.Fprintf(out, "Constraint template %s is not supported, its constraints are skipped\n", object.GetName())
+			}
+		case group == gatekeeper.ConstraintGroup:
+			policy, warnings, err := gatekeeper.Convert(object, force)
+			if err != nil {
+				fmt.Fprintf(out, "Constraint %s/%s is skipped: %v\n", object.GetKind(), object.GetName(), err)
+				continue
+			}
+			for _, warning := range warnings {
+				fmt.Fprintf(out, "Constraint %s/%s: %s\n", object.GetKind(), object.GetName(), warning)
+			}
+			if err := policyvalidate.Validate(*policy); err != nil {
+				fmt.Fprintf(out, "Constraint %s/%s is skipped, the converted policy is invalid: %v\n", object.GetKind(), object.GetName(), err)
+				continue
+			}
+			policies = append(policies, policy)
+		default:
+			fmt.Fprintf(out, "Resource %s/%s is not a Gatekeeper constraint or template, it is skipped\n", object.GetKind(), object.GetName())
+		}
+	}
+	return policies, nil
+}
+
+func printPolicies(policies []*v1.ClusterPolicy, out io.Writer) error {
+	for i, policy := range policies {
+		// the policy is encoded through JSON to honor the json tags
+		raw, err := json.Marshal(policy)
+		if err != nil {
+			return err
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return err
+		}
+		unstructured.RemoveNestedField(object, "metadata", "creationTimestamp")
+		delete(object, "status")
+		removeEmptyRuleFields(policy, object)
+		policyYaml, err := yamlv2.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if i != 0 {
+			fmt.Fprintln(out, "---")
+		}
+		fmt.Fprint(out, string(policyYaml))
+	}
+	return nil
+}
+
+// removeEmptyRuleFields removes the empty rule fields the policy type does not omit
+func removeEmptyRuleFields(policy *v1.ClusterPolicy, object map[string]interface{}) {
+	rules, _, _ := unstructured.NestedSlice(object, "spec", "rules")
+	for i, rule := range policy.Spec.Rules {
+		r := rules[i].(map[string]interface{})
+		if !rule.HasMutate() {
+			delete(r, "mutate")
+		}
+		if !rule.HasGenerate() {
+			delete(r, "generate")
+		}
+		if reflect.DeepEqual(rule.ExcludeResources, v1.ExcludeResources{}) {
+			delete(r, "exclude")
+		}
+	}
+	_ = unstructured.SetNestedSlice(object, rules, "spec", "rules")
+}
+
+func getObjects(paths []string) ([]unstructured.Unstructured, error) {
+	var objects []unstructured.Unstructured
+	for _, path := range paths {
+		path = filepath.Clean(path)
+
+		fileDesc, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileDesc.IsDir() {
+			files, err := ioutil.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			var subPaths []string
+			for _, file := range files {
+				subPaths = append(subPaths, filepath.Join(path, file.Name()))
+			}
+			objectsFromDir, err := getObjects(subPaths)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objectsFromDir...)
+		} else {
+			objectsFromFile, err := getObjectsFromFile(path)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objectsFromFile...)
+		}
+	}
+	return objects, nil
+}
+
+// getObjectsFromFile returns the objects of the documents of a YAML or JSON file
+func getObjectsFromFile(path string) ([]unstructured.Unstructured, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file: %v", err)
+	}
+
+	var objects []unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(file), 4096)
+	for {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, sanitizedError.New(fmt.Sprintf("failed to decode %s: %v", path, err))
+		}
+		// skip empty documents
+		if len(object) == 0 {
+			continue
+		}
+		if kind, _ := object["kind"].(string); kind == "" {
+			return nil, sanitizedError.New(fmt.Sprintf("resource without kind in %s", path))
+		}
+		objects = append(objects, unstructured.Unstructured{Object: object})
+	}
+	return objects, nil
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/nirmata/kyverno/pkg/admissionpolicy"
+	"github.com/nirmata/kyverno/pkg/kyverno/common"
+	"github.com/nirmata/kyverno/pkg/kyverno/sanitizedError"
+	policyvalidate "github.com/nirmata/kyverno/pkg/policy"
+	"github.com/spf13/cobra"
+	yamlv2 "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Exports the validate rules of kyverno policies as ValidatingAdmissionPolicies",
+		Example: "kyverno export /path/to/policy.yaml /path/to/folderOfPolicies > validatingadmissionpolicies.yaml",
+		RunE: func(cmd *cobra.Command, policyPaths []string) (err error) {
+			defer func() {
+				if err != nil {
+					if !sanitizedError.IsErrorSanitized(err) {
+						glog.V(4).Info(err)
+						err = fmt.Errorf("Internal error")
+					}
+				}
+			}()
+
+			policies, err := common.GetPolicies(policyPaths)
+			if err != nil {
+				if !sanitizedError.IsErrorSanitized(err) {
+					return sanitizedError.New("Could not parse policy paths")
+				}
+				return err
+			}
+
+			var objects []interface{}
+			for _, policy := range policies {
+				if err := policyvalidate.Validate(*policy); err != nil {
+					return sanitizedError.New(fmt.Sprintf("Policy %v is not valid", policy.Name))
+				}
+				admissionPolicies, bindings, skipped := admissionpolicy.Generate(*policy)
+				for _, rule := range skipped {
+					fmt.Fprintf(os.Stderr, "Rule %s of policy %s is not exported: %v\n", rule.Rule, policy.Name, rule.Reason)
+				}
+				for i := range admissionPolicies {
+					objects = append(objects, admissionPolicies[i], bindings[i])
+				}
+			}
+			return printObjects(objects, os.Stdout)
+		},
+	}
+
+	return cmd
+}
+
+// printObjects prints the objects as YAML documents, the objects are encoded
+// through JSON to honor the json tags
+func printObjects(objects []interface{}, out io.Writer) error {
+	for i, object := range objects {
+		raw, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		var encoded map[string]interface{}
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return err
+		}
+		unstructured.RemoveNestedField(encoded, "metadata", "creationTimestamp")
+		objectYaml, err := yamlv2.Marshal(encoded)
+		if err != nil {
+			return err
+		}
+		if i != 0 {
+			fmt.Fprintln(out, "---")
+		}
+		fmt.Fprint(out, string(objectYaml))
+	}
+	return nil
+}
+
@@ -8,6 +8,12 @@ import (
 
 	"github.com/nirmata/kyverno/pkg/kyverno/apply"
 
+	"github.com/nirmata/kyverno/pkg/kyverno/convert"
+
+	"github.com/nirmata/kyverno/pkg/kyverno/export"
+
+	"github.com/nirmata/kyverno/pkg/kyverno/simulate"
+
 	"github.com/nirmata/kyverno/pkg/kyverno/version"
 
 	"github.com/spf13/cobra"
@@ -25,6 +31,9 @@ func CLI() {
 		version.Command(),
 		apply.Command(),
 		validate.Command(),
+		convert.Command(),
+		export.Command(),
+		simulate.Command(),
 	}
 
 	cli.AddCommand(commands...)
@@ -0,0 +1,198 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	v1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// request is an admission request recorded in the audit logs
+type request struct {
+	time      time.Time
+	operation v1beta1.Operation
+	userInfo  authenticationv1.UserInfo
+	object    []byte
+	oldObject []byte
+}
+
+// auditEntry is either an audit event of the API server (audit.k8s.io Event)
+// or an admission review sent to a webhook
+type auditEntry struct {
+	Kind string `json:"kind"`
+
+	// audit event fields
+	Stage     string                    `json:"stage"`
+	Verb      string                    `json:"verb"`
+	User      authenticationv1.UserInfo `json:"user"`
+	ObjectRef *struct {
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus"`
+	RequestObject  json.RawMessage  `json:"requestObject"`
+	ResponseObject json.RawMessage  `json:"responseObject"`
+	StageTimestamp metav1.MicroTime `json:"stageTimestamp"`
+
+	// admission review fields
+	Request *v1beta1.AdmissionRequest `json:"request"`
+}
+
+// auditLogStats counts the requests of the audit logs that are not replayed as recorded
+type auditLogStats struct {
+	// requests that cannot be replayed because their audit events do not contain the objects
+	notReplayable int
+	// requests of admission reviews, which are not timestamped and replayed whatever their age
+	admissionReviews int
+}
+
+// readAuditLogs replays the create and update requests of the audit logs that were admitted after
+// notBefore, in time order. The logs are streamed, only the next request of each log is held in memory:
+// each log is expected in time order, as written by the API server, and the logs are merged
+func readAuditLogs(paths []string, notBefore time.Time, replay func(request)) (auditLogStats, error) {
+	var stats auditLogStats
+	logs := make([]*auditLog, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return stats, err
+		}
+		defer file.Close()
+		log := newAuditLog(file, notBefore)
+		logs = append(logs, log)
+		if err := log.advance(); err != nil {
+			return stats, fmt.Errorf("failed to decode %s: %v", path, err)
+		}
+	}
+
+	for {
+		// the log with the oldest next request, admission reviews are not timestamped and come first
+		oldest := -1
+		for i, log := range logs {
+			if log.next == nil {
+				continue
+			}
+			if oldest == -1 || log.next.time.Before(logs[oldest].next.time) {
+				oldest = i
+			}
+		}
+		if oldest == -1 {
+			break
+		}
+		replay(*logs[oldest].next)
+		if err := logs[oldest].advance(); err != nil {
+			return stats, fmt.Errorf("failed to decode %s: %v", paths[oldest], err)
+		}
+	}
+	for _, log := range logs {
+		stats.notReplayable += log.stats.notReplayable
+		stats.admissionReviews += log.stats.admissionReviews
+	}
+	return stats, nil
+}
+
+// auditLog decodes the JSON entries of an audit log one at a time, one entry per line as written by
+// the API server, or a sequence of JSON documents
+type auditLog struct {
+	decoder   *json.Decoder
+	notBefore time.Time
+	stats     auditLogStats
+	// next is the next request of the log, nil at the end of the log
+	next *request
+}
+
+func newAuditLog(reader io.Reader, notBefore time.Time) *auditLog {
+	return &auditLog{decoder: json.NewDecoder(reader), notBefore: notBefore}
+}
+
+// advance decodes the entries up to the next replayed request
+func (l *auditLog) advance() error {
+	l.next = nil
+	for {
+		var entry auditEntry
+		if err := l.decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch entry.Kind {
+		case "AdmissionReview":
+			if r, ok := fromAdmissionReview(entry); ok {
+				l.stats.admissionReviews++
+				l.next = &r
+				return nil
+			}
+		case "Event":
+			if entry.StageTimestamp.Time.Before(l.notBefore) {
+				continue
+			}
+			r, ok, replayable := fromAuditEvent(entry)
+			if !replayable {
+				l.stats.notReplayable++
+			} else if ok {
+				l.next = &r
+				return nil
+			}
+		default:
+			glog.V(4).Infof("skipping audit log entry of kind %q", entry.Kind)
+		}
+	}
+}
+
+func fromAdmissionReview(entry auditEntry) (request, bool) {
+	if entry.Request == nil || entry.Request.SubResource != "" {
+		return request{}, false
+	}
+	if entry.Request.Operation != v1beta1.Create && entry.Request.Operation != v1beta1.Update {
+		return request{}, false
+	}
+	return request{
+		operation: entry.Request.Operation,
+		userInfo:  entry.Request.UserInfo,
+		object:    entry.Request.Object.Raw,
+		oldObject: entry.Request.OldObject.Raw,
+	}, true
+}
+
+// fromAuditEvent returns the request of a completed create, update or patch, replayable is false if
+// the event does not contain the object, i.e. the audit level is not Request or RequestResponse
+func fromAuditEvent(entry auditEntry) (r request, ok bool, replayable bool) {
+	if entry.Stage != "ResponseComplete" || (entry.ObjectRef != nil && entry.ObjectRef.Subresource != "") {
+		return r, false, true
+	}
+	// rejected requests are not admitted, blocking them changes nothing
+	if entry.ResponseStatus != nil && entry.ResponseStatus.Code >= 400 {
+		return r, false, true
+	}
+
+	r = request{
+		time:     entry.StageTimestamp.Time,
+		userInfo: entry.User,
+	}
+	switch entry.Verb {
+	case "create":
+		r.operation = v1beta1.Create
+		r.object = entry.RequestObject
+	case "update":
+		r.operation = v1beta1.Update
+		r.object = entry.RequestObject
+	case "patch":
+		// the request object of a patch is the patch, the patched object is the response
+		r.operation = v1beta1.Update
+		r.object = entry.ResponseObject
+	default:
+		return r, false, true
+	}
+	if len(r.object) == 0 || string(r.object) == "null" {
+		return r, false, false
+	}
+	return r, true, true
+}
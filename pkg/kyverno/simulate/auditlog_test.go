@@ -0,0 +1,191 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	v1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+const podJSON = `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"nginx","namespace":"default"},"spec":{"containers":[{"name":"nginx","image":"nginx"}]}}`
+
+// auditEvent returns a completed audit event, the objects are omitted if empty
+func auditEvent(verb string, code int, subresource string, requestObject, responseObject string) string {
+	event := fmt.Sprintf(`{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":%q,`+
+		`"user":{"username":"alice"},"objectRef":{"resource":"pods","subresource":%q},"responseStatus":{"code":%d},`+
+		`"stageTimestamp":"2020-05-04T10:00:00.000000Z"`, verb, subresource, code)
+	if requestObject != "" {
+		event += `,"requestObject":` + requestObject
+	}
+	if responseObject != "" {
+		event += `,"responseObject":` + responseObject
+	}
+	return event + "}"
+}
+
+func Test_FromAuditEvent(t *testing.T) {
+	testCases := []struct {
+		name       string
+		event      string
+		ok         bool
+		replayable bool
+		operation  v1beta1.Operation
+	}{
+		{
+			name:       "create",
+			event:      auditEvent("create", 201, "", podJSON, ""),
+			ok:         true,
+			replayable: true,
+			operation:  v1beta1.Create,
+		},
+		{
+			name:       "update",
+			event:      auditEvent("update", 200, "", podJSON, ""),
+			ok:         true,
+			replayable: true,
+			operation:  v1beta1.Update,
+		},
+		{
+			name:       "the patched object of a patch is the response object",
+			event:      auditEvent("patch", 200, "", `{"metadata":{"labels":{"app":"nginx"}}}`, podJSON),
+			ok:         true,
+			replayable: true,
+			operation:  v1beta1.Update,
+		},
+		{
+			name:       "rejected requests are not replayed",
+			event:      auditEvent("create", 403, "", podJSON, ""),
+			replayable: true,
+		},
+		{
+			name:       "subresources are not replayed",
+			event:      auditEvent("update", 200, "status", podJSON, ""),
+			replayable: true,
+		},
+		{
+			name:       "reads are not replayed",
+			event:      auditEvent("get", 200, "", "", podJSON),
+			replayable: true,
+		},
+		{
+			name:  "events of the Metadata level cannot be replayed",
+			event: auditEvent("create", 201, "", "", ""),
+		},
+		{
+			name:  "patches without response object cannot be replayed",
+			event: auditEvent("patch", 200, "", `{"metadata":{"labels":{"app":"nginx"}}}`, ""),
+		},
+	}
+	for _, tc := range testCases {
+		var entry auditEntry
+		assert.NilError(t, json.Unmarshal([]byte(tc.event), &entry), tc.name)
+		r, ok, replayable := fromAuditEvent(entry)
+		assert.Equal(t, ok, tc.ok, tc.name)
+		assert.Equal(t, replayable, tc.replayable, tc.name)
+		if tc.ok {
+			assert.Equal(t, r.operation, tc.operation, tc.name)
+			assert.Equal(t, r.userInfo.Username, "alice", tc.name)
+			assert.Equal(t, string(r.object), podJSON, tc.name)
+		}
+	}
+}
+
+func Test_DecodeAuditLog(t *testing.T) {
+	admissionReview := `{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1beta1","request":{"uid":"1","operation":"CREATE",` +
+		`"userInfo":{"username":"bob"},"object":` + podJSON + `}}`
+	deleteReview := `{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1beta1","request":{"uid":"2","operation":"DELETE","userInfo":{"username":"bob"}}}`
+	oldEvent := strings.Replace(auditEvent("create", 201, "", podJSON, ""), "2020-05-04", "2020-04-01", 1)
+
+	testCases := []struct {
+		name             string
+		log              string
+		users            []string
+		notReplayable    int
+		admissionReviews int
+		err              bool
+	}{
+		{
+			name:  "audit events, one per line",
+			log:   auditEvent("create", 201, "", podJSON, "") + "\n" + auditEvent("patch", 200, "", "{}", podJSON) + "\n",
+			users: []string{"alice", "alice"},
+		},
+		{
+			name:             "admission reviews are replayed whatever the date",
+			log:              admissionReview + "\n" + deleteReview + "\n" + oldEvent,
+			users:            []string{"bob"},
+			admissionReviews: 1,
+		},
+		{
+			name:          "events without objects are counted",
+			log:           auditEvent("create", 201, "", "", "") + auditEvent("create", 403, "", "", ""),
+			notReplayable: 1,
+		},
+		{
+			name: "other kinds are skipped",
+			log:  `{"kind":"EventList","items":[]}`,
+		},
+		{
+			name: "invalid JSON",
+			log:  `{"kind":`,
+			err:  true,
+		},
+	}
+	notBefore := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range testCases {
+		log := newAuditLog(strings.NewReader(tc.log), notBefore)
+		var users []string
+		var err error
+		for err = log.advance(); err == nil && log.next != nil; err = log.advance() {
+			users = append(users, log.next.userInfo.Username)
+		}
+		if tc.err {
+			assert.Assert(t, err != nil, tc.name)
+			continue
+		}
+		assert.NilError(t, err, tc.name)
+		assert.DeepEqual(t, users, tc.users)
+		assert.Equal(t, log.stats.notReplayable, tc.notReplayable, tc.name)
+		assert.Equal(t, log.stats.admissionReviews, tc.admissionReviews, tc.name)
+	}
+}
+
+func Test_ReadAuditLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auditlogs")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the logs are merged in time order
+	eventAt := func(user, timestamp string) string {
+		event := strings.Replace(auditEvent("create", 201, "", podJSON, ""), "2020-05-04T10:00:00.000000Z", timestamp, 1)
+		return strings.Replace(event, `"alice"`, `"`+user+`"`, 1) + "\n"
+	}
+	logs := map[string]string{
+		"audit-1.log": eventAt("a1", "2020-05-04T10:00:00.000000Z") + eventAt("a2", "2020-05-04T12:00:00.000000Z"),
+		"audit-2.log": eventAt("b1", "2020-05-04T11:00:00.000000Z") + eventAt("b2", "2020-05-04T13:00:00.000000Z") +
+			auditEvent("create", 201, "", "", ""),
+	}
+	var paths []string
+	for name, content := range logs {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		paths = append(paths, path)
+	}
+
+	var users []string
+	stats, err := readAuditLogs(paths, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), func(r request) {
+		users = append(users, r.userInfo.Username)
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, users, []string{"a1", "b1", "a2", "b2"})
+	assert.Equal(t, stats.notReplayable, 1)
+
+	_, err = readAuditLogs([]string{filepath.Join(dir, "missing.log")}, time.Time{}, func(request) {})
+	assert.Assert(t, err != nil)
+}
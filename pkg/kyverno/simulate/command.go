@@ -0,0 +1,71 @@
+package simulate
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/nirmata/kyverno/pkg/kyverno/common"
+	"github.com/nirmata/kyverno/pkg/kyverno/sanitizedError"
+	policyvalidate "github.com/nirmata/kyverno/pkg/policy"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	var auditLogPaths []string
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Replays audit logs through policies and reports the requests they would have blocked",
+		Example: fmt.Sprintf("To report what a policy would have blocked over the past week:\n" +
+			"kyverno simulate /path/to/policy.yaml --audit-log /var/log/kubernetes/audit.log\n\n" +
+			"To replay admission reviews of any age:\nkyverno simulate /path/to/policy.yaml --audit-log /path/to/admissionreviews.json --since 0"),
+		RunE: func(cmd *cobra.Command, policyPaths []string) (err error) {
+			defer func() {
+				if err != nil {
+					if !sanitizedError.IsErrorSanitized(err) {
+						glog.V(4).Info(err)
+						err = fmt.Errorf("Internal error")
+					}
+				}
+			}()
+
+			if len(auditLogPaths) == 0 {
+				return sanitizedError.New("Specify path to audit log files")
+			}
+
+			policies, err := common.GetPolicies(policyPaths)
+			if err != nil {
+				if !sanitizedError.IsErrorSanitized(err) {
+					return sanitizedError.New("Could not parse policy paths")
+				}
+				return err
+			}
+			for _, policy := range policies {
+				if err := policyvalidate.Validate(*policy); err != nil {
+					return sanitizedError.New(fmt.Sprintf("Policy %v is not valid", policy.Name))
+				}
+			}
+
+			var notBefore time.Time
+			if since > 0 {
+				notBefore = time.Now().Add(-since)
+			}
+			sim := newSimulator(policies)
+			stats, err := readAuditLogs(auditLogPaths, notBefore, sim.replay)
+			if err != nil {
+				return sanitizedError.New(fmt.Sprintf("Could not read audit logs: %v", err))
+			}
+
+			printReport(os.Stdout, sim.results, sim.replayed, stats, since > 0)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&auditLogPaths, "audit-log", []string{}, "Path to audit log files with audit events or admission reviews in JSON")
+	cmd.Flags().DurationVar(&since, "since", 7*24*time.Hour, "Only replays the audit events of this period, set to 0 to replay all events")
+
+	return cmd
+}
@@ -0,0 +1,181 @@
+package simulate
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	v1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// policyResult is the outcome of replaying the requests through a policy
+type policyResult struct {
+	policy string
+	// number of requests matched by at least one rule
+	matched int
+	blocked []blockedRequest
+	// the policy matches roles or cluster roles, which are not recorded in the audit logs
+	matchesRoles bool
+}
+
+// blockedRequest is a request the policy would have blocked in enforce mode, only the fields
+// of the report are kept, not the objects of the request
+type blockedRequest struct {
+	time      time.Time
+	operation v1beta1.Operation
+	kind      string
+	namespace string
+	name      string
+	username  string
+	rules     []response.RuleResponse
+}
+
+// simulator replays the requests through the policies one request at a time,
+// so that the requests of the audit logs do not have to be held in memory
+type simulator struct {
+	policies []*kyverno.ClusterPolicy
+	results  []policyResult
+	// number of replayed requests
+	replayed int
+}
+
+func newSimulator(policies []*kyverno.ClusterPolicy) *simulator {
+	results := make([]policyResult, 0, len(policies))
+	for _, policy := range policies {
+		results = append(results, policyResult{policy: policy.Name, matchesRoles: matchesRoles(*policy)})
+	}
+	return &simulator{policies: policies, results: results}
+}
+
+// replay replays the request through each policy as the admission webhooks do: the resource
+// is mutated, then the validate and verifyManifests rules are applied. The policies are treated as
+// enforce policies, every failed rule blocks the request
+func (s *simulator) replay(r request) {
+	s.replayed++
+	for i, policy := range s.policies {
+		policyContext, err := newPolicyContext(*policy, r)
+		if err != nil {
+			glog.V(4).Infof("failed to replay request: %v", err)
+			continue
+		}
+
+		var responses []response.EngineResponse
+		mutateResponse := engine.Mutate(policyContext)
+		if mutateResponse.PatchedResource.Object != nil {
+			policyContext.NewResource = mutateResponse.PatchedResource
+		}
+		responses = append(responses, mutateResponse, engine.VerifyManifests(policyContext), engine.Validate(policyContext))
+
+		var matched bool
+		var failed []response.RuleResponse
+		for j, resp := range responses {
+			matched = matched || len(resp.PolicyResponse.Rules) != 0
+			// mutation failures do not block requests
+			if j == 0 {
+				continue
+			}
+			for _, rule := range resp.PolicyResponse.Rules {
+				if !rule.Success {
+					failed = append(failed, rule)
+				}
+			}
+		}
+		result := &s.results[i]
+		if matched {
+			result.matched++
+		}
+		if len(failed) != 0 {
+			resource := policyContext.NewResource
+			result.blocked = append(result.blocked, blockedRequest{
+				time:      r.time,
+				operation: r.operation,
+				kind:      resource.GetKind(),
+				namespace: resource.GetNamespace(),
+				name:      resource.GetName(),
+				username:  r.userInfo.Username,
+				rules:     failed,
+			})
+		}
+	}
+}
+
+// matchesRoles returns true if a rule of the policy matches or excludes roles or cluster roles
+func matchesRoles(policy kyverno.ClusterPolicy) bool {
+	for _, rule := range policy.Spec.Rules {
+		if len(rule.MatchResources.Roles) != 0 || len(rule.MatchResources.ClusterRoles) != 0 ||
+			len(rule.ExcludeResources.Roles) != 0 || len(rule.ExcludeResources.ClusterRoles) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func newPolicyContext(policy kyverno.ClusterPolicy, r request) (engine.PolicyContext, error) {
+	var policyContext engine.PolicyContext
+	var newResource, oldResource unstructured.Unstructured
+	if err := newResource.UnmarshalJSON(r.object); err != nil {
+		return policyContext, err
+	}
+	if len(r.oldObject) != 0 {
+		if err := oldResource.UnmarshalJSON(r.oldObject); err != nil {
+			return policyContext, err
+		}
+	}
+
+	// the roles of the user are not recorded in the audit logs
+	userRequestInfo := kyverno.RequestInfo{AdmissionUserInfo: r.userInfo}
+	ctx := context.NewContext()
+	if err := ctx.AddResource(r.object); err != nil {
+		return policyContext, err
+	}
+	if err := ctx.AddUserInfo(userRequestInfo); err != nil {
+		return policyContext, err
+	}
+	if err := ctx.AddSA(r.userInfo.Username); err != nil {
+		return policyContext, err
+	}
+
+	policyContext = engine.PolicyContext{
+		Policy:        policy,
+		NewResource:   newResource,
+		OldResource:   oldResource,
+		Context:       ctx,
+		AdmissionInfo: userRequestInfo,
+	}
+	return policyContext, nil
+}
+
+// printReport prints the requests blocked by each policy, and the warnings on the requests
+// that were not replayed as admitted. Since is true if the audit events were filtered by age
+func printReport(out io.Writer, results []policyResult, replayed int, stats auditLogStats, since bool) {
+	fmt.Fprintf(out, "Replayed %d requests\n", replayed)
+	for _, result := range results {
+		fmt.Fprintf(out, "\nPolicy %s: %d of %d matched requests would have been blocked\n", result.policy, len(result.blocked), result.matched)
+		if result.matchesRoles {
+			fmt.Fprintf(out, "  Warning: the roles and cluster roles of the users are not recorded in the audit logs, the rules matching or excluding them are evaluated without\n")
+		}
+		for _, blocked := range result.blocked {
+			when := "unknown time"
+			if !blocked.time.IsZero() {
+				when = blocked.time.UTC().Format("2006-01-02T15:04:05Z")
+			}
+			fmt.Fprintf(out, "  %s %s %s %s/%s by %s\n", when, blocked.operation, blocked.kind,
+				blocked.namespace, blocked.name, blocked.username)
+			for _, rule := range blocked.rules {
+				fmt.Fprintf(out, "    %s: %s\n", rule.Name, rule.Message)
+			}
+		}
+	}
+	if stats.notReplayable != 0 {
+		fmt.Fprintf(out, "\n%d requests were skipped, their audit events do not contain the objects. Use the Request or RequestResponse audit level to replay them\n", stats.notReplayable)
+	}
+	if since && stats.admissionReviews != 0 {
+		fmt.Fprintf(out, "\n%d requests of admission reviews were replayed whatever their age, admission reviews are not timestamped and --since does not apply to them\n", stats.admissionReviews)
+	}
+}
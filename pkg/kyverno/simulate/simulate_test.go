@@ -0,0 +1,136 @@
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+	v1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func newPolicy(t *testing.T, raw string) *kyverno.ClusterPolicy {
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal([]byte(raw), &policy))
+	return &policy
+}
+
+func newRequest(user string, object string) request {
+	return request{operation: v1beta1.Create, userInfo: authenticationv1.UserInfo{Username: user}, object: []byte(object)}
+}
+
+func Test_Simulate(t *testing.T) {
+	requireTeam := newPolicy(t, `{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "require-team"},
+		"spec": {
+			"validationFailureAction": "audit",
+			"rules": [{
+				"name": "team",
+				"match": {"resources": {"kinds": ["Pod"]}},
+				"validate": {"message": "the team label is required", "pattern": {"metadata": {"labels": {"team": "?*"}}}}
+			}]
+		}
+	}`)
+	// the resources are mutated before they are validated, as in the webhooks
+	defaultTeam := newPolicy(t, `{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "default-team"},
+		"spec": {
+			"rules": [{
+				"name": "add-team",
+				"match": {"resources": {"kinds": ["Pod"], "namespaces": ["team-a"]}},
+				"mutate": {"overlay": {"metadata": {"labels": {"+(team)": "a"}}}}
+			}, {
+				"name": "team",
+				"match": {"resources": {"kinds": ["Pod"]}, "clusterRoles": ["cluster-admin"]},
+				"validate": {"message": "the team label is required", "pattern": {"metadata": {"labels": {"team": "?*"}}}}
+			}]
+		}
+	}`)
+	requests := []request{
+		newRequest("alice", `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"a","namespace":"team-a"},"spec":{"containers":[{"name":"a","image":"nginx"}]}}`),
+		newRequest("bob", `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"b","namespace":"team-b"},"spec":{"containers":[{"name":"b","image":"nginx"}]}}`),
+		newRequest("carol", `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"c","namespace":"team-b","labels":{"team":"b"}},"spec":{"containers":[{"name":"c","image":"nginx"}]}}`),
+		newRequest("dave", `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"d","namespace":"team-b"}}`),
+	}
+
+	testCases := []struct {
+		policy       *kyverno.ClusterPolicy
+		matched      int
+		blocked      []string
+		matchesRoles bool
+	}{
+		{
+			// audit policies are replayed as enforce policies
+			policy:  requireTeam,
+			matched: 3,
+			blocked: []string{"alice", "bob"},
+		},
+		{
+			// the roles of the users are unknown, the validate rule is not applied
+			policy:       defaultTeam,
+			matched:      1,
+			matchesRoles: true,
+		},
+	}
+	for _, tc := range testCases {
+		sim := newSimulator([]*kyverno.ClusterPolicy{tc.policy})
+		for _, r := range requests {
+			sim.replay(r)
+		}
+		assert.Equal(t, sim.replayed, len(requests))
+		assert.Equal(t, len(sim.results), 1)
+		result := sim.results[0]
+		assert.Equal(t, result.policy, tc.policy.Name)
+		assert.Equal(t, result.matched, tc.matched, tc.policy.Name)
+		assert.Equal(t, result.matchesRoles, tc.matchesRoles, tc.policy.Name)
+		var blocked []string
+		for _, b := range result.blocked {
+			blocked = append(blocked, b.username)
+		}
+		assert.DeepEqual(t, blocked, tc.blocked)
+	}
+}
+
+func Test_PrintReport(t *testing.T) {
+	results := []policyResult{{policy: "require-team", matched: 2, matchesRoles: true}}
+	testCases := []struct {
+		stats    auditLogStats
+		since    bool
+		warnings []string
+	}{
+		{
+			stats: auditLogStats{},
+			since: true,
+		},
+		{
+			stats:    auditLogStats{notReplayable: 3},
+			warnings: []string{"3 requests were skipped"},
+		},
+		{
+			stats:    auditLogStats{admissionReviews: 2},
+			since:    true,
+			warnings: []string{"--since does not apply"},
+		},
+		{
+			stats: auditLogStats{admissionReviews: 2},
+		},
+	}
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		printReport(&out, results, 2, tc.stats, tc.since)
+		report := out.String()
+		assert.Assert(t, strings.Contains(report, "Policy require-team: 0 of 2 matched requests would have been blocked"), report)
+		assert.Assert(t, strings.Contains(report, "roles and cluster roles of the users are not recorded"), report)
+		for _, warning := range tc.warnings {
+			assert.Assert(t, strings.Contains(report, warning), report)
+		}
+		assert.Equal(t, strings.Count(report, "\n\n"), 1+len(tc.warnings), report)
+	}
+}
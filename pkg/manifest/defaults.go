@@ -0,0 +1,115 @@
+package manifest
+
+// anyValue marks the defaulted fields whose value depends on other fields, e.g. the image pull policy
+type anyValue struct{}
+
+// metadataDefaults are set on resources of all kinds, the namespace is often not part of the signed manifest
+var metadataDefaults = map[string]interface{}{
+	"metadata.namespace": anyValue{},
+	"metadata.annotations.kubectl.kubernetes.io/last-applied-configuration": anyValue{},
+}
+
+// podSpecDefaults are the fields of pod specs defaulted by the API server, relative to the pod spec
+var podSpecDefaults = map[string]interface{}{
+	"dnsPolicy":                               "ClusterFirst",
+	"restartPolicy":                           "Always",
+	"schedulerName":                           "default-scheduler",
+	"securityContext":                         map[string]interface{}{},
+	"terminationGracePeriodSeconds":           30,
+	"enableServiceLinks":                      true,
+	"containers.imagePullPolicy":              anyValue{},
+	"containers.terminationMessagePath":       "/dev/termination-log",
+	"containers.terminationMessagePolicy":     "File",
+	"containers.ports.protocol":               "TCP",
+	"initContainers.imagePullPolicy":          anyValue{},
+	"initContainers.terminationMessagePath":   "/dev/termination-log",
+	"initContainers.terminationMessagePolicy": "File",
+	"initContainers.ports.protocol":           "TCP",
+}
+
+// podSpecPaths are the paths of the pod specs of the kinds
+var podSpecPaths = map[string]string{
+	"Pod":         "spec",
+	"Deployment":  "spec.template.spec",
+	"ReplicaSet":  "spec.template.spec",
+	"StatefulSet": "spec.template.spec",
+	"DaemonSet":   "spec.template.spec",
+	"Job":         "spec.template.spec",
+	"CronJob":     "spec.jobTemplate.spec.template.spec",
+}
+
+// kindDefaults are the fields defaulted by the API server and the controllers for the kinds
+var kindDefaults = map[string]map[string]interface{}{
+	"Deployment": {
+		"metadata.annotations.deployment.kubernetes.io/revision": anyValue{},
+		"spec.replicas":                1,
+		"spec.revisionHistoryLimit":    10,
+		"spec.progressDeadlineSeconds": 600,
+		"spec.strategy": map[string]interface{}{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": "25%"},
+		},
+		"spec.strategy.rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": "25%"},
+	},
+	"ReplicaSet": {
+		"spec.replicas": 1,
+	},
+	"StatefulSet": {
+		"spec.replicas":             1,
+		"spec.revisionHistoryLimit": 10,
+		"spec.podManagementPolicy":  "OrderedReady",
+		"spec.updateStrategy": map[string]interface{}{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]interface{}{"partition": 0},
+		},
+		"spec.updateStrategy.rollingUpdate": map[string]interface{}{"partition": 0},
+	},
+	"DaemonSet": {
+		"spec.revisionHistoryLimit": 10,
+		"spec.updateStrategy": map[string]interface{}{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]interface{}{"maxUnavailable": 1},
+		},
+		"spec.updateStrategy.rollingUpdate": map[string]interface{}{"maxUnavailable": 1},
+	},
+	"Job": {
+		"spec.backoffLimit": 6,
+		"spec.completions":  1,
+		"spec.parallelism":  1,
+		// the selector and its labels are generated by the API server
+		"spec.selector": anyValue{},
+		"spec.template.metadata.labels.controller-uid": anyValue{},
+		"spec.template.metadata.labels.job-name":       anyValue{},
+	},
+	"CronJob": {
+		"spec.concurrencyPolicy":          "Allow",
+		"spec.suspend":                    false,
+		"spec.successfulJobsHistoryLimit": 3,
+		"spec.failedJobsHistoryLimit":     1,
+	},
+	"Service": {
+		"spec.type":             "ClusterIP",
+		"spec.sessionAffinity":  "None",
+		"spec.clusterIP":        anyValue{},
+		"spec.ports.protocol":   "TCP",
+		"spec.ports.targetPort": anyValue{},
+	},
+}
+
+// defaultedFields returns the paths of the fields the API server sets on resources of the kind when
+// they are missing, with their default value. These fields may be missing from the signed manifest
+func defaultedFields(kind string) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for path, value := range metadataDefaults {
+		fields[path] = value
+	}
+	for path, value := range kindDefaults[kind] {
+		fields[path] = value
+	}
+	if podSpecPath, ok := podSpecPaths[kind]; ok {
+		for path, value := range podSpecDefaults {
+			fields[podSpecPath+"."+path] = value
+		}
+	}
+	return fields
+}
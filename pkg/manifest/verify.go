@@ -0,0 +1,299 @@
+// Package manifest verifies the signatures of Kubernetes manifests stored in the annotations of the resources.
+// The signed manifest is stored next to its signature, either in separate annotations or in a single bundle
+// annotation, and the resource must match the signed manifest.
+package manifest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/cosign"
+	"golang.org/x/crypto/openpgp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// DefaultAnnotationDomain is the domain of the signature annotations used by k8s-manifest-sigstore
+	DefaultAnnotationDomain = "cosign.sigstore.dev"
+
+	messageAnnotation   = "message"
+	signatureAnnotation = "signature"
+	bundleAnnotation    = "bundle"
+
+	pgpKeyHeader       = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	pgpSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+)
+
+// maxMessageSize is the size limit of the decompressed signed message, the manifests of a resource
+// are far smaller, larger messages are rejected instead of being decompressed in memory
+const maxMessageSize = 4 << 20
+
+// ignoredFields are set by the API server and the controllers, they are never compared with the signed manifest
+var ignoredFields = []string{
+	"status",
+	"metadata.uid",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.creationTimestamp",
+	"metadata.deletionTimestamp",
+	"metadata.deletionGracePeriodSeconds",
+	"metadata.managedFields",
+	"metadata.selfLink",
+	"metadata.finalizers",
+	"metadata.ownerReferences",
+}
+
+// bundle holds the signed manifest and its signature in a single annotation
+type bundle struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// Verify checks that the resource carries a signature of its manifest from one of the keys of the
+// verification, and that the resource matches the signed manifest
+func Verify(resource unstructured.Unstructured, verification kyverno.ManifestVerification) error {
+	domain := verification.AnnotationDomain
+	if domain == "" {
+		domain = DefaultAnnotationDomain
+	}
+	message, signature, err := getSignature(resource.GetAnnotations(), domain)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(verification.Keys, message, signature); err != nil {
+		return err
+	}
+	signed, err := findManifest(message, resource)
+	if err != nil {
+		return err
+	}
+
+	ignored := map[string]bool{}
+	for _, field := range append(ignoredFields, verification.IgnoreFields...) {
+		ignored[field] = true
+	}
+	// the signature annotations are added after signing
+	actual := resource.DeepCopy().Object
+	for _, object := range []map[string]interface{}{signed, actual} {
+		if err := removeSignatureAnnotations(object, domain); err != nil {
+			return err
+		}
+	}
+	return compare(signed, actual, "", ignored, defaultedFields(resource.GetKind()))
+}
+
+// removeSignatureAnnotations removes the annotations of the domain from the object
+func removeSignatureAnnotations(object map[string]interface{}, domain string) error {
+	annotations, ok, _ := unstructured.NestedMap(object, "metadata", "annotations")
+	if !ok {
+		return nil
+	}
+	for key := range annotations {
+		if strings.HasPrefix(key, domain+"/") {
+			delete(annotations, key)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(object, "metadata", "annotations")
+		return nil
+	}
+	return unstructured.SetNestedMap(object, annotations, "metadata", "annotations")
+}
+
+// ValidateKey returns an error if the key is neither a PEM encoded ECDSA public key nor an armored GPG public key
+func ValidateKey(key string) error {
+	if strings.Contains(key, pgpKeyHeader) {
+		_, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+		return err
+	}
+	return cosign.ValidateKey(key)
+}
+
+// getSignature returns the decoded message and signature from the annotations
+func getSignature(annotations map[string]string, domain string) ([]byte, []byte, error) {
+	encodedMessage := annotations[domain+"/"+messageAnnotation]
+	encodedSignature := annotations[domain+"/"+signatureAnnotation]
+	if encodedBundle, ok := annotations[domain+"/"+bundleAnnotation]; ok {
+		rawBundle, err := base64.StdEncoding.DecodeString(encodedBundle)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the signature bundle: %v", err)
+		}
+		var b bundle
+		if err := json.Unmarshal(rawBundle, &b); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the signature bundle: %v", err)
+		}
+		encodedMessage, encodedSignature = b.Message, b.Signature
+	}
+	if encodedMessage == "" || encodedSignature == "" {
+		return nil, nil, fmt.Errorf("manifest is not signed, the annotations %s/%s and %s/%s are required",
+			domain, messageAnnotation, domain, signatureAnnotation)
+	}
+
+	message, err := base64.StdEncoding.DecodeString(encodedMessage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the signed message: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the signature: %v", err)
+	}
+	return message, signature, nil
+}
+
+// verifySignature checks that the message is signed with one of the keys
+func verifySignature(keys []string, message []byte, signature []byte) error {
+	var errs []string
+	for _, key := range keys {
+		var err error
+		if strings.Contains(key, pgpKeyHeader) {
+			err = verifyPGPSignature(key, message, signature)
+		} else {
+			err = cosign.VerifyBlob(key, message, signature)
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return fmt.Errorf("no valid signature found for the allowed signers: %s", strings.Join(errs, "; "))
+}
+
+func verifyPGPSignature(key string, message []byte, signature []byte) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	if err != nil {
+		return fmt.Errorf("failed to parse GPG key: %v", err)
+	}
+	if bytes.Contains(signature, []byte(pgpSignatureHeader)) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(message), bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(message), bytes.NewReader(signature))
+	}
+	return err
+}
+
+// findManifest returns the manifest of the resource in the signed message, which may be gzip compressed
+// and hold several YAML or JSON documents. Compressed messages larger than maxMessageSize are rejected
+func findManifest(message []byte, resource unstructured.Unstructured) (map[string]interface{}, error) {
+	if len(message) > 2 && message[0] == 0x1f && message[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(message))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress the signed message: %v", err)
+		}
+		if message, err = ioutil.ReadAll(io.LimitReader(reader, maxMessageSize+1)); err != nil {
+			return nil, fmt.Errorf("failed to decompress the signed message: %v", err)
+		}
+		if len(message) > maxMessageSize {
+			return nil, fmt.Errorf("the decompressed signed message is larger than %d bytes", maxMessageSize)
+		}
+	}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(message), 4096)
+	for {
+		var manifest unstructured.Unstructured
+		if err := decoder.Decode(&manifest.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse the signed message: %v", err)
+		}
+		if manifest.Object == nil {
+			continue
+		}
+		if manifest.GetKind() == resource.GetKind() && manifest.GetName() == resource.GetName() &&
+			(manifest.GetNamespace() == "" || manifest.GetNamespace() == resource.GetNamespace()) {
+			return manifest.Object, nil
+		}
+	}
+	return nil, fmt.Errorf("the signed message does not contain the manifest of %s %s", resource.GetKind(), resource.GetName())
+}
+
+// compare checks that the resource has the fields of the signed manifest, with the same values, and no
+// other field. Only the ignored fields, and the defaulted fields with their default value, may differ.
+// Lists must have the same elements
+func compare(signed, actual interface{}, path string, ignored map[string]bool, defaulted map[string]interface{}) error {
+	if ignored[path] {
+		return nil
+	}
+	switch typedSigned := signed.(type) {
+	case map[string]interface{}:
+		typedActual, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field %s differs from the signed manifest", path)
+		}
+		for key, value := range typedSigned {
+			fieldPath := joinPath(path, key)
+			actualValue, ok := typedActual[key]
+			if !ok {
+				if value == nil || ignored[fieldPath] {
+					continue
+				}
+				return fmt.Errorf("field %s of the signed manifest is missing", fieldPath)
+			}
+			if err := compare(value, actualValue, fieldPath, ignored, defaulted); err != nil {
+				return err
+			}
+		}
+		for key, value := range typedActual {
+			if _, ok := typedSigned[key]; ok {
+				continue
+			}
+			fieldPath := joinPath(path, key)
+			if value == nil || ignored[fieldPath] || isDefault(defaulted, fieldPath, value) {
+				continue
+			}
+			return fmt.Errorf("field %s is not in the signed manifest", fieldPath)
+		}
+		return nil
+	case []interface{}:
+		typedActual, ok := actual.([]interface{})
+		if !ok || len(typedActual) != len(typedSigned) {
+			return fmt.Errorf("field %s differs from the signed manifest", path)
+		}
+		for i := range typedSigned {
+			if err := compare(typedSigned[i], typedActual[i], path, ignored, defaulted); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if !equal(signed, actual) {
+			return fmt.Errorf("field %s differs from the signed manifest", path)
+		}
+		return nil
+	}
+}
+
+// isDefault returns true if the field missing from the signed manifest was set to its default value
+func isDefault(defaulted map[string]interface{}, path string, value interface{}) bool {
+	defaultValue, ok := defaulted[path]
+	if !ok {
+		return false
+	}
+	if _, ok := defaultValue.(anyValue); ok {
+		return true
+	}
+	return equal(defaultValue, value)
+}
+
+// equal compares the values through their JSON encoding, numbers are decoded as float64
+// from the message and as int64 in the resource
+func equal(expected, actual interface{}) bool {
+	expectedValue, _ := json.Marshal(expected)
+	actualValue, _ := json.Marshal(actual)
+	return bytes.Equal(expectedValue, actualValue)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
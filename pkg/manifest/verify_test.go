@@ -0,0 +1,277 @@
+package manifest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var signedManifest = []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+data:
+  key: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+  labels:
+    team: platform
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get", "list"]
+`)
+
+func newResource(t *testing.T, annotations map[string]string) unstructured.Unstructured {
+	var resource unstructured.Unstructured
+	err := json.Unmarshal([]byte(`{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind": "ClusterRole",
+		"metadata": {
+			"name": "reader",
+			"uid": "5b6a2b3c",
+			"resourceVersion": "42",
+			"labels": {"team": "platform"}
+		},
+		"rules": [{"apiGroups": [""], "resources": ["pods"], "verbs": ["get", "list"]}]
+	}`), &resource.Object)
+	assert.NilError(t, err)
+	resource.SetAnnotations(annotations)
+	return resource
+}
+
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, message []byte) []byte {
+	hash := sha256.Sum256(message)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	assert.NilError(t, err)
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.NilError(t, err)
+	return sig
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NilError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func signatureAnnotations(message, signature []byte) map[string]string {
+	return map[string]string{
+		DefaultAnnotationDomain + "/message":   base64.StdEncoding.EncodeToString(message),
+		DefaultAnnotationDomain + "/signature": base64.StdEncoding.EncodeToString(signature),
+	}
+}
+
+func Test_Verify_ECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	verification := kyverno.ManifestVerification{Keys: []string{publicKeyPEM(t, otherKey), publicKeyPEM(t, key)}}
+	signature := signECDSA(t, key, signedManifest)
+
+	// signed with one of the keys
+	resource := newResource(t, signatureAnnotations(signedManifest, signature))
+	assert.NilError(t, Verify(resource, verification))
+
+	// not signed
+	err = Verify(newResource(t, nil), verification)
+	assert.ErrorContains(t, err, "manifest is not signed")
+
+	// signed with another key
+	resource = newResource(t, signatureAnnotations(signedManifest, signature))
+	err = Verify(resource, kyverno.ManifestVerification{Keys: []string{publicKeyPEM(t, otherKey)}})
+	assert.ErrorContains(t, err, "no valid signature found")
+
+	// changed after signing
+	resource = newResource(t, signatureAnnotations(signedManifest, signature))
+	assert.NilError(t, unstructured.SetNestedField(resource.Object, "platform-admins", "metadata", "labels", "team"))
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "field metadata.labels.team differs")
+
+	// the change is ignored
+	verification.IgnoreFields = []string{"metadata.labels"}
+	assert.NilError(t, Verify(resource, verification))
+
+	// verb added after signing
+	resource = newResource(t, signatureAnnotations(signedManifest, signature))
+	rules, _, _ := unstructured.NestedSlice(resource.Object, "rules")
+	rules[0].(map[string]interface{})["verbs"] = []interface{}{"get", "list", "delete"}
+	assert.NilError(t, unstructured.SetNestedSlice(resource.Object, rules, "rules"))
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "field rules.verbs differs")
+
+	// the manifest is not part of the message
+	resource = newResource(t, signatureAnnotations(signedManifest, signature))
+	resource.SetName("writer")
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "does not contain the manifest")
+}
+
+func Test_Verify_Bundle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	rawBundle, err := json.Marshal(bundle{
+		Message:   base64.StdEncoding.EncodeToString(signedManifest),
+		Signature: base64.StdEncoding.EncodeToString(signECDSA(t, key, signedManifest)),
+	})
+	assert.NilError(t, err)
+
+	domain := "signatures.example.com"
+	resource := newResource(t, map[string]string{domain + "/bundle": base64.StdEncoding.EncodeToString(rawBundle)})
+	verification := kyverno.ManifestVerification{Keys: []string{publicKeyPEM(t, key)}, AnnotationDomain: domain}
+	assert.NilError(t, Verify(resource, verification))
+
+	// the default domain is not used
+	verification.AnnotationDomain = ""
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "manifest is not signed")
+}
+
+func gzipMessage(t *testing.T, message []byte) []byte {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(message)
+	assert.NilError(t, err)
+	assert.NilError(t, writer.Close())
+	return compressed.Bytes()
+}
+
+func Test_FindManifest_Gzip(t *testing.T) {
+	resource := newResource(t, nil)
+	manifest, err := findManifest(gzipMessage(t, signedManifest), resource)
+	assert.NilError(t, err)
+	assert.Equal(t, manifest["kind"], "ClusterRole")
+
+	// the compressed message is small, the decompressed message is over the limit
+	large := append(bytes.Repeat([]byte("#"), maxMessageSize), signedManifest...)
+	_, err = findManifest(gzipMessage(t, large), resource)
+	assert.ErrorContains(t, err, "larger than")
+}
+
+func Test_Verify_GPG(t *testing.T) {
+	entity, err := openpgp.NewEntity("signer", "", "signer@example.com", nil)
+	assert.NilError(t, err)
+	var publicKey bytes.Buffer
+	writer, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	assert.NilError(t, err)
+	assert.NilError(t, entity.Serialize(writer))
+	assert.NilError(t, writer.Close())
+
+	var signature bytes.Buffer
+	assert.NilError(t, openpgp.DetachSign(&signature, entity, bytes.NewReader(signedManifest), nil))
+
+	verification := kyverno.ManifestVerification{Keys: []string{publicKey.String()}}
+	assert.NilError(t, ValidateKey(publicKey.String()))
+	resource := newResource(t, signatureAnnotations(signedManifest, signature.Bytes()))
+	assert.NilError(t, Verify(resource, verification))
+
+	resource = newResource(t, signatureAnnotations(bytes.Replace(signedManifest, []byte("list"), []byte("watch"), 1), signature.Bytes()))
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "no valid signature found")
+}
+
+func Test_ValidateKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	assert.NilError(t, ValidateKey(publicKeyPEM(t, key)))
+	assert.Assert(t, ValidateKey("not a key") != nil)
+	assert.Assert(t, ValidateKey(pgpKeyHeader+"\ninvalid") != nil)
+}
+
+func Test_Verify_AddedFields(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	verification := kyverno.ManifestVerification{Keys: []string{publicKeyPEM(t, key)}}
+	message := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.19
+`)
+	annotations := signatureAnnotations(message, signECDSA(t, key, message))
+
+	newDeployment := func(containerFields string) unstructured.Unstructured {
+		var resource unstructured.Unstructured
+		err := json.Unmarshal([]byte(`{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {"name": "app", "namespace": "default", "generation": 1},
+			"spec": {
+				"replicas": 1,
+				"revisionHistoryLimit": 10,
+				"progressDeadlineSeconds": 600,
+				"strategy": {"type": "RollingUpdate", "rollingUpdate": {"maxSurge": "25%", "maxUnavailable": "25%"}},
+				"selector": {"matchLabels": {"app": "app"}},
+				"template": {
+					"metadata": {"creationTimestamp": null, "labels": {"app": "app"}},
+					"spec": {
+						"dnsPolicy": "ClusterFirst",
+						"restartPolicy": "Always",
+						"schedulerName": "default-scheduler",
+						"securityContext": {},
+						"terminationGracePeriodSeconds": 30,
+						"containers": [{
+							"name": "app",
+							"image": "nginx:1.19",
+							"imagePullPolicy": "IfNotPresent",
+							"terminationMessagePath": "/dev/termination-log",
+							"terminationMessagePolicy": "File"`+containerFields+`
+						}]
+					}
+				}
+			},
+			"status": {"replicas": 1}
+		}`), &resource.Object)
+		assert.NilError(t, err)
+		resource.SetAnnotations(annotations)
+		return resource
+	}
+
+	// the defaults set by the API server are accepted
+	assert.NilError(t, Verify(newDeployment(""), verification))
+
+	// fields added after signing are rejected
+	err = Verify(newDeployment(`, "securityContext": {"privileged": true}`), verification)
+	assert.ErrorContains(t, err, "field spec.template.spec.containers.securityContext is not in the signed manifest")
+
+	// defaulted fields must have their default value
+	resource := newDeployment("")
+	assert.NilError(t, unstructured.SetNestedField(resource.Object, int64(5), "spec", "replicas"))
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "field spec.replicas is not in the signed manifest")
+	assert.NilError(t, unstructured.SetNestedField(resource.Object, map[string]interface{}{"runAsUser": int64(0)}, "spec", "template", "spec", "securityContext"))
+	verification.IgnoreFields = []string{"spec.replicas"}
+	err = Verify(resource, verification)
+	assert.ErrorContains(t, err, "field spec.template.spec.securityContext is not in the signed manifest")
+}
@@ -1,7 +1,11 @@
 package openapi
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -16,6 +20,7 @@ import (
 
 	client "github.com/nirmata/kyverno/pkg/dclient"
 	"k8s.io/apimachinery/pkg/util/wait"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 )
 
 type crdDefinition struct {
@@ -59,19 +64,28 @@ func (c *crdSync) Run(workers int, stopCh <-chan struct{}) {
 }
 
 func (c *crdSync) sync() {
-	openApiGlobalState.mutex.Lock()
-	defer openApiGlobalState.mutex.Unlock()
-
-	crds, err := c.client.ListResource("CustomResourceDefinition", "", nil)
+	// the schemas are collected before the state is changed, so that a failed sync keeps
+	// the definitions of the previous sync instead of a part of the CRDs
+	crdDefinitions := make(map[string]*openapi_v2.Schema)
+	err := c.client.ListResourceInPages(context.TODO(), "CustomResourceDefinition", "", client.ListOptions{}, func(crds *unstructured.UnstructuredList) error {
+		for _, crd := range crds.Items {
+			if crdName, schema, ok := parseCRD(crd); ok {
+				crdDefinitions[crdName] = schema
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		glog.V(4).Infof("could not fetch crd's from server: %v", err)
 		return
 	}
 
-	deleteCRDFromPreviousSync()
+	openApiGlobalState.mutex.Lock()
+	defer openApiGlobalState.mutex.Unlock()
 
-	for _, crd := range crds.Items {
-		parseCRD(crd)
+	deleteCRDFromPreviousSync()
+	for crdName, schema := range crdDefinitions {
+		addCRD(crdName, schema)
 	}
 }
 
@@ -84,7 +98,8 @@ func deleteCRDFromPreviousSync() {
 	openApiGlobalState.crdList = []string{}
 }
 
-func parseCRD(crd unstructured.Unstructured) {
+// parseCRD returns the kind and the schema of the first version of the CRD
+func parseCRD(crd unstructured.Unstructured) (string, *openapi_v2.Schema, bool) {
 	var crdDefinition crdDefinition
 	crdRaw, _ := json.Marshal(crd.Object)
 	_ = json.Unmarshal(crdRaw, &crdDefinition)
@@ -92,7 +107,7 @@ func parseCRD(crd unstructured.Unstructured) {
 	crdName := crdDefinition.Spec.Names.Kind
 	if len(crdDefinition.Spec.Versions) < 1 {
 		glog.V(4).Infof("could not parse crd schema, no versions present")
-		return
+		return "", nil, false
 	}
 
 	var schema yaml.MapSlice
@@ -102,11 +117,47 @@ func parseCRD(crd unstructured.Unstructured) {
 	parsedSchema, err := openapi_v2.NewSchema(schema, compiler.NewContext("schema", nil))
 	if err != nil {
 		glog.V(4).Infof("could not parse crd schema:%v", err)
-		return
+		return "", nil, false
 	}
+	return crdName, parsedSchema, true
+}
 
+// addCRD adds the schema of a CRD, the caller must hold the lock of the state
+func addCRD(crdName string, schema *openapi_v2.Schema) {
 	openApiGlobalState.crdList = append(openApiGlobalState.crdList, crdName)
 
 	openApiGlobalState.kindToDefinitionName[crdName] = crdName
-	openApiGlobalState.definitions[crdName] = parsedSchema
+	openApiGlobalState.definitions[crdName] = schema
+}
+
+// LoadCRDs adds the schemas of the custom resource definitions in the files, so that
+// policies on custom resources can be validated without access to a cluster
+func LoadCRDs(paths []string) error {
+	openApiGlobalState.mutex.Lock()
+	defer openApiGlobalState.mutex.Unlock()
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		decoder := k8syaml.NewYAMLOrJSONDecoder(file, 4096)
+		for {
+			var crd unstructured.Unstructured
+			if err := decoder.Decode(&crd.Object); err != nil {
+				file.Close()
+				if err == io.EOF {
+					break
+				}
+				return fmt.Errorf("failed to parse %s: %v", path, err)
+			}
+			if crd.GetKind() != "CustomResourceDefinition" {
+				continue
+			}
+			if crdName, schema, ok := parseCRD(crd); ok {
+				addCRD(crdName, schema)
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_LoadCRDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crds")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "crds.yaml")
+	err = ioutil.WriteFile(path, []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: widgets
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+`), 0644)
+	assert.NilError(t, err)
+	defer func() {
+		openApiGlobalState.mutex.Lock()
+		deleteCRDFromPreviousSync()
+		openApiGlobalState.mutex.Unlock()
+	}()
+
+	assert.NilError(t, LoadCRDs([]string{path}))
+	assert.Equal(t, GetDefinitionNameFromKind("Widget"), "Widget")
+	assert.Equal(t, GetDefinitionNameFromKind("Namespace"), "io.k8s.api.core.v1.Namespace")
+
+	assert.Assert(t, LoadCRDs([]string{filepath.Join(dir, "missing.yaml")}) != nil)
+}
@@ -51,14 +51,22 @@ func ValidatePolicyMutation(policy v1.ClusterPolicy) error {
 	var kindToRules = make(map[string][]v1.Rule)
 	for _, rule := range policy.Spec.Rules {
 		if rule.HasMutate() {
+			kinds := rule.MatchResources.Kinds
+			// the targets are mutated instead of the matched resources
+			if rule.HasMutateExisting() {
+				kinds = nil
+				for _, target := range rule.Mutation.Targets {
+					kinds = append(kinds, target.Kind)
+				}
+			}
 			rule.MatchResources = v1.MatchResources{
 				UserInfo: v1.UserInfo{},
 				ResourceDescription: v1.ResourceDescription{
-					Kinds: rule.MatchResources.Kinds,
+					Kinds: kinds,
 				},
 			}
 			rule.ExcludeResources = v1.ExcludeResources{}
-			for _, kind := range rule.MatchResources.Kinds {
+			for _, kind := range kinds {
 				kindToRules[kind] = append(kindToRules[kind], rule)
 			}
 		}
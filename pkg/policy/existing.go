@@ -1,7 +1,9 @@
 package policy
 
 import (
+	"context"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -9,49 +11,53 @@ import (
 	"github.com/minio/minio/pkg/wildcard"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/config"
-	client "github.com/nirmata/kyverno/pkg/dclient"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/engine"
 	"github.com/nirmata/kyverno/pkg/engine/response"
 	"github.com/nirmata/kyverno/pkg/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
-func (pc *PolicyController) processExistingResources(policy kyverno.ClusterPolicy) []response.EngineResponse {
+func (pc *PolicyController) processExistingResources(policy kyverno.ClusterPolicy) {
 	// Parse through all the resources
 	// drops the cache after configured rebuild time
 	pc.rm.Drop()
-	var engineResponses []response.EngineResponse
 	// get resource that are satisfy the resource description defined in the rules
-	resourceMap := listResources(pc.client, policy, pc.configHandler)
-	for _, resource := range resourceMap {
-		// pre-processing, check if the policy and resource version has been processed before
-		if !pc.rm.ProcessResource(policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion()) {
-			glog.V(4).Infof("policy %s with resource version %s already processed on resource %s/%s/%s with resource version %s", policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
-			continue
-		}
+	// resources are listed in pages, the results of each page are reported before
+	// the next page is fetched to keep memory usage independent of the cluster size
+	listResources(pc.client, policy, pc.configHandler, func(resourceMap map[string]unstructured.Unstructured) {
+		var engineResponses []response.EngineResponse
+		for _, resource := range resourceMap {
+			// pre-processing, check if the policy and resource version has been processed before
+			if !pc.rm.ProcessResource(policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion()) {
+				glog.V(4).Infof("policy %s with resource version %s already processed on resource %s/%s/%s with resource version %s", policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
+				continue
+			}
 
-		// skip reporting violation on pod which has annotation pod-policies.kyverno.io/autogen-applied
-		if skipPodApplication(resource) {
-			continue
-		}
+			// skip reporting violation on pod which has annotation pod-policies.kyverno.io/autogen-applied
+			if skipPodApplication(resource) {
+				continue
+			}
 
-		// apply the policy on each
-		glog.V(4).Infof("apply policy %s with resource version %s on resource %s/%s/%s with resource version %s", policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
-		engineResponse := applyPolicy(policy, resource)
-		// get engine response for mutation & validation independently
-		engineResponses = append(engineResponses, engineResponse...)
-		// post-processing, register the resource as processed
-		pc.rm.RegisterResource(policy.GetName(), policy.GetResourceVersion(), resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
-	}
-	return engineResponses
+			// apply the policy on each
+			glog.V(4).Infof("apply policy %s with resource version %s on resource %s/%s/%s with resource version %s", policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
+			engineResponse := applyPolicy(policy, resource, pc.exceptions)
+			// get engine response for mutation & validation independently
+			engineResponses = append(engineResponses, engineResponse...)
+			// post-processing, register the resource as processed
+			pc.rm.RegisterResource(policy.GetName(), policy.GetResourceVersion(), resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
+		}
+		// report errors
+		pc.cleanupAndReport(engineResponses)
+	})
 }
 
-func listResources(client *client.Client, policy kyverno.ClusterPolicy, configHandler config.Interface) map[string]unstructured.Unstructured {
-	// key uid
-	resourceMap := map[string]unstructured.Unstructured{}
-
+// listResources calls the handler with the resources of each listed page that match the rules of the policy
+// resources that match more than one rule are deduplicated by the resource manager
+func listResources(client *dclient.Client, policy kyverno.ClusterPolicy, configHandler config.Interface, handler func(map[string]unstructured.Unstructured)) {
 	for _, rule := range policy.Spec.Rules {
 		// resources that match
 		for _, k := range rule.MatchResources.Kinds {
@@ -77,50 +83,54 @@ func listResources(client *client.Client, policy kyverno.ClusterPolicy, configHa
 
 			// get resources in the namespaces
 			for _, ns := range namespaces {
-				rMap := getResourcesPerNamespace(k, client, ns, rule, configHandler)
-				mergeresources(resourceMap, rMap)
+				getResourcesPerNamespace(k, client, ns, rule, configHandler, handler)
 			}
 
 		}
 	}
-	return resourceMap
 }
 
-func getResourcesPerNamespace(kind string, client *client.Client, namespace string, rule kyverno.Rule, configHandler config.Interface) map[string]unstructured.Unstructured {
-	resourceMap := map[string]unstructured.Unstructured{}
+func getResourcesPerNamespace(kind string, client *dclient.Client, namespace string, rule kyverno.Rule, configHandler config.Interface, handler func(map[string]unstructured.Unstructured)) {
 	// merge include and exclude label selector values
 	ls := rule.MatchResources.Selector
 	//	ls := mergeLabelSectors(rule.MatchResources.Selector, rule.ExcludeResources.Selector)
 	// list resources
 	glog.V(4).Infof("get resources for kind %s, namespace %s, selector %v", kind, namespace, rule.MatchResources.Selector)
-	list, err := client.ListResource(kind, namespace, ls)
-	if err != nil {
-		glog.Infof("unable to get resources: err %v", err)
-		return nil
+	options := dclient.ListOptions{LabelSelector: ls}
+	// a name without wildcards is matched by the API server
+	if rule.MatchResources.Name != "" && !strings.ContainsAny(rule.MatchResources.Name, "*?") {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", rule.MatchResources.Name)
 	}
-	// filter based on name
-	for _, r := range list.Items {
-		// match name
-		if rule.MatchResources.Name != "" {
-			if !wildcard.Match(rule.MatchResources.Name, r.GetName()) {
-				glog.V(4).Infof("skipping resource %s/%s due to include condition name=%s mistatch", r.GetNamespace(), r.GetName(), rule.MatchResources.Name)
+	err := client.ListResourceInPages(context.TODO(), kind, namespace, options, func(list *unstructured.UnstructuredList) error {
+		resourceMap := map[string]unstructured.Unstructured{}
+		// filter based on name
+		for _, r := range list.Items {
+			// match name
+			if rule.MatchResources.Name != "" {
+				if !wildcard.Match(rule.MatchResources.Name, r.GetName()) {
+					glog.V(4).Infof("skipping resource %s/%s due to include condition name=%s mistatch", r.GetNamespace(), r.GetName(), rule.MatchResources.Name)
+					continue
+				}
+			}
+			// Skip the filtered resources
+			if configHandler.ToFilter(r.GetKind(), r.GetNamespace(), r.GetName()) {
 				continue
 			}
-		}
-		// Skip the filtered resources
-		if configHandler.ToFilter(r.GetKind(), r.GetNamespace(), r.GetName()) {
-			continue
+
+			//TODO check if the group version kind is present or not
+			resourceMap[string(r.GetUID())] = r
 		}
 
-		//TODO check if the group version kind is present or not
-		resourceMap[string(r.GetUID())] = r
+		// exclude the resources
+		// skip resources to be filtered
+		excludeResources(resourceMap, rule.ExcludeResources.ResourceDescription, configHandler)
+		//	glog.V(4).Infof("resource map: %v", resourceMap)
+		handler(resourceMap)
+		return nil
+	})
+	if err != nil {
+		glog.Infof("unable to get resources: err %v", err)
 	}
-
-	// exclude the resources
-	// skip resources to be filtered
-	excludeResources(resourceMap, rule.ExcludeResources.ResourceDescription, configHandler)
-	//	glog.V(4).Infof("resource map: %v", resourceMap)
-	return resourceMap
 }
 
 func excludeResources(included map[string]unstructured.Unstructured, exclude kyverno.ResourceDescription, configHandler config.Interface) {
@@ -237,23 +247,17 @@ const (
 	Skip Condition = 2
 )
 
-// merge b into a map
-func mergeresources(a, b map[string]unstructured.Unstructured) {
-	for k, v := range b {
-		a[k] = v
-	}
-}
-
-func getAllNamespaces(client *client.Client) []string {
+func getAllNamespaces(client *dclient.Client) []string {
 	var namespaces []string
 	// get all namespaces
-	nsList, err := client.ListResource("Namespace", "", nil)
+	err := client.ListResourceInPages(context.TODO(), "Namespace", "", dclient.ListOptions{}, func(nsList *unstructured.UnstructuredList) error {
+		for _, ns := range nsList.Items {
+			namespaces = append(namespaces, ns.GetName())
+		}
+		return nil
+	})
 	if err != nil {
 		glog.Error(err)
-		return namespaces
-	}
-	for _, ns := range nsList.Items {
-		namespaces = append(namespaces, ns.GetName())
 	}
 	return namespaces
 }
@@ -7,11 +7,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nirmata/kyverno/pkg/openapi"
 
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/cosign"
 	"github.com/nirmata/kyverno/pkg/engine/anchor"
+	"github.com/nirmata/kyverno/pkg/manifest"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -20,6 +23,9 @@ import (
 // - One operation per rule
 // - ResourceDescription mandatory checks
 func Validate(p kyverno.ClusterPolicy) error {
+	if path, err := validateRolloutStrategy(p.Spec); err != nil {
+		return fmt.Errorf("path: spec.rolloutStrategy%s: %v", path, err)
+	}
 	if path, err := validateUniqueRuleName(p); err != nil {
 		return fmt.Errorf("path: spec.%s: %v", path, err)
 	}
@@ -71,6 +77,18 @@ func Validate(p kyverno.ClusterPolicy) error {
 				return fmt.Errorf("path: spec.rules[%d].generate.%s.: %v", i, path, err)
 			}
 		}
+		// Image verification
+		if rule.HasVerifyImages() {
+			if path, err := validateVerifyImages(rule.VerifyImages); err != nil {
+				return fmt.Errorf("path: spec.rules[%d].verifyImages%s.: %v", i, path, err)
+			}
+		}
+		// Manifest verification
+		if rule.HasVerifyManifests() {
+			if path, err := validateVerifyManifests(rule.VerifyManifests); err != nil {
+				return fmt.Errorf("path: spec.rules[%d].verifyManifests.%s.: %v", i, path, err)
+			}
+		}
 
 		// If a rules match block does not match any kind,
 		// we should only allow such rules to have metadata in its overlay
@@ -154,7 +172,7 @@ func validateUniqueRuleName(p kyverno.ClusterPolicy) (string, error) {
 
 // validateRuleType checks only one type of rule is defined per rule
 func validateRuleType(r kyverno.Rule) error {
-	ruleTypes := []bool{r.HasMutate(), r.HasValidate(), r.HasGenerate()}
+	ruleTypes := []bool{r.HasMutate(), r.HasValidate(), r.HasGenerate(), r.HasVerifyImages(), r.HasVerifyManifests()}
 
 	operationCount := func() int {
 		count := 0
@@ -167,7 +185,7 @@ func validateRuleType(r kyverno.Rule) error {
 	}()
 
 	if operationCount == 0 {
-		return fmt.Errorf("no operation defined in the rule '%s'.(supported operations: mutation,validation,generation)", r.Name)
+		return fmt.Errorf("no operation defined in the rule '%s'.(supported operations: mutation,validation,generation,verifyImages,verifyManifests)", r.Name)
 	} else if operationCount != 1 {
 		return fmt.Errorf("multiple operations defined in the rule '%s', only one type of operation is allowed per rule", r.Name)
 	}
@@ -285,6 +303,17 @@ func validateMutation(m kyverno.Mutation) (string, error) {
 			return path, err
 		}
 	}
+	// Targets
+	if len(m.Targets) != 0 {
+		if m.Overlay == nil && len(m.Patches) == 0 {
+			return "targets", errors.New("an overlay or patches are required to mutate the targets")
+		}
+		for i, target := range m.Targets {
+			if target.Kind == "" || target.Name == "" {
+				return fmt.Sprintf("targets[%d]", i), errors.New("kind and name are required")
+			}
+		}
+	}
 	return "", nil
 }
 
@@ -325,19 +354,44 @@ func validateValidation(v kyverno.Validation) (string, error) {
 			}
 		}
 	}
+
+	if v.Count != nil {
+		if path, err := validateCount(*v.Count); err != nil {
+			return fmt.Sprintf("count.%s", path), err
+		}
+	}
 	return "", nil
 }
 
-// validateOverlayPattern checks one of pattern/anyPattern must exist
+func validateCount(count kyverno.ResourceCount) (string, error) {
+	if count.Limit < 0 {
+		return "limit", fmt.Errorf("limit cannot be negative")
+	}
+	if count.Scope != "" && count.Scope != kyverno.NamespaceScope && count.Scope != kyverno.ClusterScope {
+		return "scope", fmt.Errorf("scope must be %s or %s", kyverno.NamespaceScope, kyverno.ClusterScope)
+	}
+	if count.Pattern != nil {
+		if path, err := validatePattern(count.Pattern, "/", []anchor.IsAnchor{anchor.IsConditionAnchor, anchor.IsExistenceAnchor, anchor.IsEqualityAnchor, anchor.IsNegationAnchor}); err != nil {
+			return fmt.Sprintf("pattern.%s", path), err
+		}
+	}
+	return "", nil
+}
+
+// validateOverlayPattern checks one of pattern/anyPattern/count must exist
 func validateOverlayPattern(v kyverno.Validation) error {
-	if v.Pattern == nil && len(v.AnyPattern) == 0 {
-		return fmt.Errorf("a pattern or anyPattern must be specified")
+	if v.Pattern == nil && len(v.AnyPattern) == 0 && v.Count == nil {
+		return fmt.Errorf("a pattern, anyPattern or count must be specified")
 	}
 
 	if v.Pattern != nil && len(v.AnyPattern) != 0 {
 		return fmt.Errorf("only one operation allowed per validation rule(pattern or anyPattern)")
 	}
 
+	if v.Count != nil && (v.Pattern != nil || len(v.AnyPattern) != 0) {
+		return fmt.Errorf("only one operation allowed per validation rule(pattern, anyPattern or count)")
+	}
+
 	return nil
 }
 
@@ -363,6 +417,14 @@ func validateGeneration(gen kyverno.Generation) (string, error) {
 			return fmt.Sprintf("clone.%s", path), err
 		}
 	}
+	if gen.ServiceAccount != (kyverno.ServiceAccountReference{}) {
+		if gen.ServiceAccount.Namespace == "" {
+			return "serviceAccount.namespace", fmt.Errorf("namespace cannot be empty")
+		}
+		if gen.ServiceAccount.Name == "" {
+			return "serviceAccount.name", fmt.Errorf("name cannot be empty")
+		}
+	}
 	if gen.Data != nil {
 		//TODO: is this required ?? as anchors can only be on pattern and not resource
 		// we can add this check by not sure if its needed here
@@ -373,6 +435,58 @@ func validateGeneration(gen kyverno.Generation) (string, error) {
 	return "", nil
 }
 
+func validateVerifyImages(imageVerifications []kyverno.ImageVerification) (string, error) {
+	for i, iv := range imageVerifications {
+		if iv.Image == "" {
+			return fmt.Sprintf("[%d].image", i), fmt.Errorf("image cannot be empty")
+		}
+		if err := cosign.ValidateOptions(cosign.Options{
+			Key:      iv.Key,
+			Roots:    iv.Roots,
+			Subject:  iv.Subject,
+			Issuer:   iv.Issuer,
+			RekorKey: iv.RekorKey,
+		}); err != nil {
+			return fmt.Sprintf("[%d]", i), err
+		}
+		for j, predicateType := range iv.Attestations {
+			if predicateType == "" {
+				return fmt.Sprintf("[%d].attestations[%d]", i, j), fmt.Errorf("predicate type cannot be empty")
+			}
+		}
+	}
+	return "", nil
+}
+
+func validateVerifyManifests(manifestVerification kyverno.ManifestVerification) (string, error) {
+	if len(manifestVerification.Keys) == 0 {
+		return "keys", fmt.Errorf("at least one key is required")
+	}
+	for i, key := range manifestVerification.Keys {
+		if err := manifest.ValidateKey(key); err != nil {
+			return fmt.Sprintf("keys[%d]", i), err
+		}
+	}
+	return "", nil
+}
+
+func validateRolloutStrategy(spec kyverno.Spec) (string, error) {
+	strategy := spec.RolloutStrategy
+	if strategy == nil {
+		return "", nil
+	}
+	if spec.ValidationFailureAction != "enforce" {
+		return "", fmt.Errorf("a rollout strategy requires validationFailureAction enforce")
+	}
+	if window, err := time.ParseDuration(strategy.ObservationWindow); err != nil || window <= 0 {
+		return ".observationWindow", fmt.Errorf("must be a positive duration, e.g. 168h")
+	}
+	if strategy.MaxFailurePercentage < 0 || strategy.MaxFailurePercentage > 100 {
+		return ".maxFailurePercentage", fmt.Errorf("must be between 0 and 100")
+	}
+	return "", nil
+}
+
 func validateClone(c kyverno.CloneFrom) (string, error) {
 	if c.Name == "" {
 		return "name", fmt.Errorf("name cannot be empty")
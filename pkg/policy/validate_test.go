@@ -955,6 +955,48 @@ func Test_Validate_Generate_HasAnchors(t *testing.T) {
 	}
 }
 
+func Test_Validate_Generate_ServiceAccount(t *testing.T) {
+	rawGenerate := []byte(`
+	{
+		"kind": "ConfigMap",
+		"name": "copied-cm",
+		"clone": {
+		   "namespace": "default",
+		   "name": "game"
+		},
+		"serviceAccount": {
+		   "namespace": "kyverno"
+		}
+	 }`)
+
+	var generate kyverno.Generation
+	assert.NilError(t, json.Unmarshal(rawGenerate, &generate))
+	path, err := validateGeneration(generate)
+	assert.Error(t, err, "name cannot be empty")
+	assert.Equal(t, path, "serviceAccount.name")
+
+	generate.ServiceAccount.Name = "generator"
+	_, err = validateGeneration(generate)
+	assert.NilError(t, err)
+}
+
+func Test_Validate_VerifyImages_Keyless(t *testing.T) {
+	rawVerifyImages := []byte(`
+	[
+		{
+		   "image": "ghcr.io/myorg/*",
+		   "subject": "*@myorg.com",
+		   "attestations": ["https://slsa.dev/provenance/v0.2"]
+		}
+	 ]`)
+
+	var imageVerifications []kyverno.ImageVerification
+	assert.NilError(t, json.Unmarshal(rawVerifyImages, &imageVerifications))
+	path, err := validateVerifyImages(imageVerifications)
+	assert.Error(t, err, "either a key or the roots of keyless signatures are required")
+	assert.Equal(t, path, "[0]")
+}
+
 func Test_Validate_ErrorFormat(t *testing.T) {
 	rawPolicy := []byte(`
 	{
@@ -0,0 +1,65 @@
+package policyexception
+
+import (
+	"fmt"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// policyIndex indexes the policy exceptions by the names of the policies they exempt rules of
+const policyIndex = "policy"
+
+// Lister looks up the policy exceptions of a policy in the informer's store,
+// so that the exceptions are not copied on every admission request
+type Lister struct {
+	indexer cache.Indexer
+}
+
+// NewLister returns a lister of the policy exceptions of the informer, the index is added
+// to the informer the first time, it must be called before the informer is started
+func NewLister(informer kyvernoinformer.PolicyExceptionInformer) (*Lister, error) {
+	indexer := informer.Informer().GetIndexer()
+	if _, ok := indexer.GetIndexers()[policyIndex]; !ok {
+		if err := informer.Informer().AddIndexers(cache.Indexers{policyIndex: indexByPolicy}); err != nil {
+			return nil, err
+		}
+	}
+	return &Lister{indexer: indexer}, nil
+}
+
+// ListExceptions returns the policy exceptions exempting rules of the policy, in all namespaces
+func (l *Lister) ListExceptions(policyName string) ([]*kyverno.PolicyException, error) {
+	objects, err := l.indexer.ByIndex(policyIndex, policyName)
+	if err != nil {
+		return nil, err
+	}
+	exceptions := make([]*kyverno.PolicyException, 0, len(objects))
+	for _, object := range objects {
+		exception, ok := object.(*kyverno.PolicyException)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T in the policy exception store", object)
+		}
+		exceptions = append(exceptions, exception)
+	}
+	return exceptions, nil
+}
+
+// indexByPolicy returns the names of the policies the exception exempts rules of,
+// rule names are matched by the engine as they can contain wildcards
+func indexByPolicy(obj interface{}) ([]string, error) {
+	exception, ok := obj.(*kyverno.PolicyException)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	var policies []string
+	seen := map[string]bool{}
+	for _, e := range exception.Spec.Exceptions {
+		if !seen[e.PolicyName] {
+			seen[e.PolicyName] = true
+			policies = append(policies, e.PolicyName)
+		}
+	}
+	return policies, nil
+}
@@ -0,0 +1,46 @@
+package policyexception
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/client/clientset/versioned/fake"
+	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newException(namespace, name string, policies ...string) *kyverno.PolicyException {
+	exception := &kyverno.PolicyException{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	for _, policy := range policies {
+		exception.Spec.Exceptions = append(exception.Spec.Exceptions, kyverno.Exception{PolicyName: policy, RuleNames: []string{"*"}})
+	}
+	return exception
+}
+
+func TestListExceptions(t *testing.T) {
+	factory := kyvernoinformer.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	informer := factory.Kyverno().V1().PolicyExceptions()
+	lister, err := NewLister(informer)
+	assert.NilError(t, err)
+	// the index is only added once, the lister can be created by several controllers
+	_, err = NewLister(informer)
+	assert.NilError(t, err)
+
+	store := informer.Informer().GetStore()
+	assert.NilError(t, store.Add(newException("monitoring", "node-exporter", "disallow-host-network", "disallow-host-path")))
+	assert.NilError(t, store.Add(newException("kyverno", "system", "disallow-host-path")))
+
+	exceptions, err := lister.ListExceptions("disallow-host-network")
+	assert.NilError(t, err)
+	assert.Equal(t, len(exceptions), 1)
+	assert.Equal(t, exceptions[0].Name, "node-exporter")
+
+	exceptions, err = lister.ListExceptions("disallow-host-path")
+	assert.NilError(t, err)
+	assert.Equal(t, len(exceptions), 2)
+
+	exceptions, err = lister.ListExceptions("require-labels")
+	assert.NilError(t, err)
+	assert.Equal(t, len(exceptions), 0)
+}
@@ -0,0 +1,154 @@
+package policystatus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RolloutController drives the rollout strategies of the policies. A policy is audited
+// during the observation window, then promoted to enforce if the percentage of failed
+// applications of its validation rules during the window does not exceed the maximum of the strategy.
+// The decisions are recorded in the policy status through the status listener, so that
+// they are not overwritten by the status sync
+type RolloutController struct {
+	pLister  kyvernolister.ClusterPolicyLister
+	listener Listener
+}
+
+// NewRolloutController returns a controller checking the rollouts of the listed policies
+func NewRolloutController(pLister kyvernolister.ClusterPolicyLister, listener Listener) *RolloutController {
+	return &RolloutController{
+		pLister:  pLister,
+		listener: listener,
+	}
+}
+
+// Run checks the rollouts every minute
+func (c *RolloutController) Run(stopCh <-chan struct{}) {
+	wait.Until(c.sync, time.Minute, stopCh)
+}
+
+func (c *RolloutController) sync() {
+	policies, err := c.pLister.List(labels.Everything())
+	if err != nil {
+		glog.V(4).Infof("failed to list policies: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, policy := range policies {
+		if policy.Spec.RolloutStrategy == nil || policy.Spec.ValidationFailureAction != "enforce" {
+			continue
+		}
+		c.listener.Send(rolloutUpdater{
+			policyName: policy.Name,
+			generation: policy.Generation,
+			rules:      validationRules(*policy),
+			strategy:   *policy.Spec.RolloutStrategy,
+			now:        now,
+		})
+	}
+}
+
+// validationRules returns the names of the rules that are enforced by the rollout,
+// the mutate and generate rules of the policy do not block resources
+func validationRules(policy v1.ClusterPolicy) []string {
+	var rules []string
+	for _, rule := range policy.Spec.Rules {
+		if rule.HasValidate() || rule.HasVerifyImages() || rule.HasVerifyManifests() {
+			rules = append(rules, rule.Name)
+		}
+	}
+	return rules
+}
+
+// rolloutUpdater starts the rollout of a policy, and decides the promotion at the end of the observation window
+type rolloutUpdater struct {
+	policyName string
+	generation int64
+	// rules are the names of the validation rules of the policy
+	rules    []string
+	strategy v1.RolloutStrategy
+	now      time.Time
+}
+
+func (u rolloutUpdater) PolicyName() string {
+	return u.policyName
+}
+
+// counts returns the applied and failed counts of the validation rules
+func (u rolloutUpdater) counts(status v1.PolicyStatus) (applied, failed int) {
+	for _, stats := range status.Rules {
+		for _, rule := range u.rules {
+			if stats.Name == rule {
+				applied += stats.AppliedCount
+				failed += stats.FailedCount
+			}
+		}
+	}
+	return applied, failed
+}
+
+func (u rolloutUpdater) UpdateStatus(status v1.PolicyStatus) v1.PolicyStatus {
+	rollout := status.Rollout
+	applied, failed := u.counts(status)
+	evaluated := applied + failed
+	// the rollout restarts when the policy changes, or when the counts were reset
+	if rollout == nil || rollout.ObservedGeneration != u.generation ||
+		evaluated < rollout.StartRulesAppliedCount+rollout.StartRulesFailedCount {
+		glog.V(4).Infof("starting rollout of policy %s", u.policyName)
+		status.Rollout = &v1.RolloutStatus{
+			Phase:                  v1.RolloutObserving,
+			ObservedGeneration:     u.generation,
+			StartTime:              metav1.NewTime(u.now),
+			StartRulesAppliedCount: applied,
+			StartRulesFailedCount:  failed,
+			Message:                fmt.Sprintf("policy is audited for %s before it is enforced", u.strategy.ObservationWindow),
+		}
+		return status
+	}
+	if rollout.Phase != v1.RolloutObserving {
+		return status
+	}
+
+	window, err := time.ParseDuration(u.strategy.ObservationWindow)
+	if err != nil {
+		glog.V(4).Infof("invalid observation window of policy %s: %v", u.policyName, err)
+		return status
+	}
+	if u.now.Before(rollout.StartTime.Add(window)) {
+		return status
+	}
+
+	rollout = rollout.DeepCopy()
+	applications := evaluated - rollout.StartRulesAppliedCount - rollout.StartRulesFailedCount
+	failures := failed - rollout.StartRulesFailedCount
+	// the policy cannot be promoted without evidence, it is observed until its rules are applied
+	if applications == 0 {
+		rollout.Message = fmt.Sprintf("policy is audited until its validation rules are applied, no rule applications since %s",
+			rollout.StartTime.UTC().Format(time.RFC3339))
+		status.Rollout = rollout
+		return status
+	}
+	percentage := float64(failures) * 100 / float64(applications)
+	decisionTime := metav1.NewTime(u.now)
+	rollout.DecisionTime = &decisionTime
+	if percentage <= float64(u.strategy.MaxFailurePercentage) {
+		rollout.Phase = v1.RolloutEnforced
+		rollout.Message = fmt.Sprintf("promoted to enforce, %d of %d validation rule applications failed (%.2f%%) during the observation window",
+			failures, applications, percentage)
+	} else {
+		rollout.Phase = v1.RolloutRejected
+		rollout.Message = fmt.Sprintf("kept in audit, %d of %d validation rule applications failed (%.2f%%) during the observation window, more than %d%%",
+			failures, applications, percentage, u.strategy.MaxFailurePercentage)
+	}
+	glog.V(2).Infof("rollout of policy %s: %s", u.policyName, rollout.Message)
+	status.Rollout = rollout
+	return status
+}
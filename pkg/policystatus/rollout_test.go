@@ -0,0 +1,99 @@
+package policystatus
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+// ruleStats returns the status of a validate rule and of a mutate rule with the counts
+func ruleStats(applied, failed, mutated int) []v1.RuleStats {
+	return []v1.RuleStats{
+		{Name: "validate", AppliedCount: applied, FailedCount: failed},
+		{Name: "mutate", AppliedCount: mutated},
+	}
+}
+
+func Test_RolloutUpdater(t *testing.T) {
+	start := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	strategy := v1.RolloutStrategy{ObservationWindow: "168h", MaxFailurePercentage: 10}
+	updater := rolloutUpdater{policyName: "policy1", generation: 1, rules: []string{"validate"}, strategy: strategy, now: start}
+
+	status := updater.UpdateStatus(v1.PolicyStatus{Rules: ruleStats(5, 5, 50)})
+	assert.Assert(t, status.Rollout != nil)
+	assert.Equal(t, status.Rollout.Phase, v1.RolloutObserving)
+	assert.Equal(t, status.Rollout.StartRulesAppliedCount, 5)
+	assert.Equal(t, status.Rollout.StartRulesFailedCount, 5)
+
+	// the window is not over
+	status.Rules = ruleStats(100, 5, 50)
+	updater.now = start.Add(24 * time.Hour)
+	status = updater.UpdateStatus(status)
+	assert.Equal(t, status.Rollout.Phase, v1.RolloutObserving)
+
+	// 5 of 100 applications of the validate rule failed during the window, the mutate rule is not counted
+	status.Rules = ruleStats(100, 10, 1000)
+	updater.now = start.Add(168 * time.Hour)
+	promoted := updater.UpdateStatus(status)
+	assert.Equal(t, promoted.Rollout.Phase, v1.RolloutEnforced)
+	assert.Assert(t, promoted.Rollout.DecisionTime != nil)
+
+	// 20 of 115 applications of the validate rule failed during the window
+	status.Rules = ruleStats(100, 25, 50)
+	rejected := updater.UpdateStatus(status)
+	assert.Equal(t, rejected.Rollout.Phase, v1.RolloutRejected)
+
+	// a new generation restarts the rollout
+	updater.generation = 2
+	restarted := updater.UpdateStatus(rejected)
+	assert.Equal(t, restarted.Rollout.Phase, v1.RolloutObserving)
+	assert.Equal(t, restarted.Rollout.ObservedGeneration, int64(2))
+	assert.Equal(t, restarted.Rollout.StartRulesFailedCount, 25)
+}
+
+func Test_RolloutUpdater_NoApplications(t *testing.T) {
+	start := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	strategy := v1.RolloutStrategy{ObservationWindow: "1h", MaxFailurePercentage: 10}
+	updater := rolloutUpdater{policyName: "policy1", generation: 1, rules: []string{"validate"}, strategy: strategy, now: start}
+	status := updater.UpdateStatus(v1.PolicyStatus{Rules: ruleStats(5, 5, 0)})
+
+	// only the mutate rule was applied during the window
+	status.Rules = ruleStats(5, 5, 10)
+	updater.now = start.Add(2 * time.Hour)
+	status = updater.UpdateStatus(status)
+	assert.Equal(t, status.Rollout.Phase, v1.RolloutObserving)
+	assert.Assert(t, status.Rollout.DecisionTime == nil)
+
+	// the policy is promoted once the validate rule was applied
+	status.Rules = ruleStats(6, 5, 10)
+	updater.now = start.Add(3 * time.Hour)
+	status = updater.UpdateStatus(status)
+	assert.Equal(t, status.Rollout.Phase, v1.RolloutEnforced)
+}
+
+func Test_ValidationRules(t *testing.T) {
+	policy := v1.ClusterPolicy{}
+	policy.Spec.Rules = []v1.Rule{
+		{Name: "validate", Validation: v1.Validation{Pattern: map[string]interface{}{"a": "b"}}},
+		{Name: "mutate", Mutation: v1.Mutation{Overlay: map[string]interface{}{"a": "b"}}},
+	}
+	assert.DeepEqual(t, validationRules(policy), []string{"validate"})
+}
+
+func Test_GetValidationFailureAction(t *testing.T) {
+	policy := v1.ClusterPolicy{}
+	policy.Generation = 1
+	policy.Spec.ValidationFailureAction = "enforce"
+	assert.Equal(t, policy.GetValidationFailureAction(), "enforce")
+
+	policy.Spec.RolloutStrategy = &v1.RolloutStrategy{ObservationWindow: "1h"}
+	assert.Equal(t, policy.GetValidationFailureAction(), "audit")
+
+	policy.Status.Rollout = &v1.RolloutStatus{Phase: v1.RolloutEnforced, ObservedGeneration: 1}
+	assert.Equal(t, policy.GetValidationFailureAction(), "enforce")
+
+	policy.Generation = 2
+	assert.Equal(t, policy.GetValidationFailureAction(), "audit")
+}
@@ -1,6 +1,7 @@
 package policyviolation
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -116,10 +117,11 @@ func (cpv *clusterPV) updatePV(newPv, oldPv *kyverno.ClusterPolicyViolation) err
 	}
 	// set name
 	newPv.SetName(oldPv.Name)
-	newPv.SetResourceVersion(oldPv.ResourceVersion)
+	// keep the owner reference set on creation
+	newPv.SetOwnerReferences(oldPv.GetOwnerReferences())
 
-	// update resource
-	_, err = cpv.kyvernoInterface.ClusterPolicyViolations().Update(newPv)
+	// update resource with server-side apply, the violation is owned by kyverno
+	_, err = cpv.dclient.ApplyResource(context.TODO(), "ClusterPolicyViolation", "", newPv.Name, newPv, true, false)
 	if err != nil {
 		return fmt.Errorf("failed to update cluster policy violation: %v", err)
 	}
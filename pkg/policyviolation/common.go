@@ -1,14 +1,13 @@
 package policyviolation
 
 import (
+	"context"
 	"fmt"
-	"time"
 
-	backoff "github.com/cenkalti/backoff"
-	"github.com/golang/glog"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	client "github.com/nirmata/kyverno/pkg/dclient"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -28,32 +27,21 @@ func createOwnerReference(resource *unstructured.Unstructured) metav1.OwnerRefer
 	return ownerRef
 }
 
-func retryGetResource(client *client.Client, rspec kyverno.ResourceSpec) (*unstructured.Unstructured, error) {
-	var i int
+// the resource may not be persisted yet when the violation is created during admission
+func retryGetResource(dclient *client.Client, rspec kyverno.ResourceSpec) (*unstructured.Unstructured, error) {
+	ctx := context.Background()
 	var obj *unstructured.Unstructured
-	var err error
 	getResource := func() error {
-		obj, err = client.GetResource(rspec.Kind, rspec.Namespace, rspec.Name)
-		glog.V(4).Infof("retry %v getting %s/%s/%s", i, rspec.Kind, rspec.Namespace, rspec.Name)
-		i++
+		var err error
+		obj, err = dclient.GetResource(ctx, rspec.Kind, rspec.Namespace, rspec.Name)
 		return err
 	}
-
-	exbackoff := &backoff.ExponentialBackOff{
-		InitialInterval:     500 * time.Millisecond,
-		RandomizationFactor: 0.5,
-		Multiplier:          1.5,
-		MaxInterval:         time.Second,
-		MaxElapsedTime:      3 * time.Second,
-		Clock:               backoff.SystemClock,
+	retriable := func(err error) bool {
+		return errors.IsNotFound(err) || client.IsRetriable(err)
 	}
-
-	exbackoff.Reset()
-	err = backoff.Retry(getResource, exbackoff)
-	if err != nil {
+	if err := client.RetryOnError(ctx, client.DefaultRetryConfig, retriable, getResource); err != nil {
 		return nil, err
 	}
-
 	return obj, nil
 }
 
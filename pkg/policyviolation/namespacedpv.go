@@ -1,6 +1,7 @@
 package policyviolation
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -114,9 +115,10 @@ func (nspv *namespacedPV) updatePV(newPv, oldPv *kyverno.PolicyViolation) error
 	}
 	// set name
 	newPv.SetName(oldPv.Name)
-	newPv.SetResourceVersion(oldPv.ResourceVersion)
-	// update resource
-	_, err = nspv.kyvernoInterface.PolicyViolations(newPv.GetNamespace()).Update(newPv)
+	// keep the owner reference set on creation
+	newPv.SetOwnerReferences(oldPv.GetOwnerReferences())
+	// update resource with server-side apply, the violation is owned by kyverno
+	_, err = nspv.dclient.ApplyResource(context.TODO(), "PolicyViolation", newPv.GetNamespace(), newPv.Name, newPv, true, false)
 	if err != nil {
 		return fmt.Errorf("failed to update namespaced policy violation: %v", err)
 	}
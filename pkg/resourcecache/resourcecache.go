@@ -0,0 +1,144 @@
+package resourcecache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
+	client "github.com/nirmata/kyverno/pkg/dclient"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Cache lists resources from informers instead of the API server, so that
+// resources can be counted on every admission request.
+// The informers of the kinds counted by policies are started when the policies are added,
+// the informer of any other kind is started the first time the kind is listed
+type Cache struct {
+	gvrFromKind func(kind string) schema.GroupVersionResource
+	factory     dynamicinformer.DynamicSharedInformerFactory
+	stopCh      <-chan struct{}
+	mutex       sync.Mutex
+	informers   map[string]*informerEntry
+}
+
+// informerEntry is the informer of a kind, synced is closed once the initial list is in the cache
+type informerEntry struct {
+	informer informers.GenericInformer
+	synced   chan struct{}
+	// err is set before synced is closed
+	err error
+}
+
+// NewCache returns a cache creating the informers with the shared factory
+func NewCache(client *client.Client, factory dynamicinformer.DynamicSharedInformerFactory, pInformer kyvernoinformer.ClusterPolicyInformer, stopCh <-chan struct{}) *Cache {
+	c := &Cache{
+		gvrFromKind: func(kind string) schema.GroupVersionResource {
+			return client.DiscoveryClient.GetGVRFromKind(kind)
+		},
+		factory:   factory,
+		stopCh:    stopCh,
+		informers: map[string]*informerEntry{},
+	}
+	pInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.addPolicy,
+		UpdateFunc: func(old, cur interface{}) {
+			c.addPolicy(cur)
+		},
+	})
+	return c
+}
+
+// addPolicy starts the informers of the kinds counted by the policy,
+// so that the first admission requests do not wait for the caches to sync
+func (c *Cache) addPolicy(obj interface{}) {
+	policy, ok := obj.(*kyverno.ClusterPolicy)
+	if !ok {
+		return
+	}
+	for _, rule := range policy.Spec.Rules {
+		if rule.Validation.Count == nil {
+			continue
+		}
+		for _, kind := range rule.MatchResources.Kinds {
+			if _, err := c.informerFor(kind); err != nil {
+				glog.V(4).Infof("failed to start the informer of kind %s counted by policy %s: %v", kind, policy.Name, err)
+			}
+		}
+	}
+}
+
+// ListResources lists the resources of the kind in the namespace, or in all namespaces if the namespace is empty.
+// It waits for the cache of the kind to sync until the context is done
+func (c *Cache) ListResources(ctx context.Context, kind, namespace string) ([]unstructured.Unstructured, error) {
+	entry, err := c.informerFor(kind)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-entry.synced:
+		if entry.err != nil {
+			return nil, entry.err
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("the cache of kind %s is not synced: %v", kind, ctx.Err())
+	}
+
+	var objects []runtime.Object
+	if namespace == "" {
+		objects, err = entry.informer.Lister().List(labels.Everything())
+	} else {
+		objects, err = entry.informer.Lister().ByNamespace(namespace).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]unstructured.Unstructured, 0, len(objects))
+	for _, object := range objects {
+		resource, ok := object.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T in the cache of kind %s", object, kind)
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// informerFor returns the informer of the kind, the informer is started if needed.
+// The cache of the informer may not be synced yet
+func (c *Cache) informerFor(kind string) (*informerEntry, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if entry, ok := c.informers[kind]; ok {
+		return entry, nil
+	}
+	gvr := c.gvrFromKind(kind)
+	if gvr.Resource == "" {
+		return nil, fmt.Errorf("resource of kind %s not found", kind)
+	}
+
+	glog.V(4).Infof("starting informer for kind %s", kind)
+	entry := &informerEntry{
+		informer: c.factory.ForResource(gvr),
+		synced:   make(chan struct{}),
+	}
+	c.factory.Start(c.stopCh)
+	go func() {
+		if !cache.WaitForCacheSync(c.stopCh, entry.informer.Informer().HasSynced) {
+			entry.err = fmt.Errorf("failed to sync the cache of kind %s", kind)
+		}
+		close(entry.synced)
+	}()
+	c.informers[kind] = entry
+	return entry, nil
+}
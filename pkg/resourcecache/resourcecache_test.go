@@ -0,0 +1,102 @@
+package resourcecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newService(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+		},
+	}
+}
+
+func newTestCache(stopCh <-chan struct{}, objects ...runtime.Object) *Cache {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+	return &Cache{
+		gvrFromKind: func(kind string) schema.GroupVersionResource {
+			if kind == "Service" {
+				return schema.GroupVersionResource{Version: "v1", Resource: "services"}
+			}
+			return schema.GroupVersionResource{}
+		},
+		factory:   dynamicinformer.NewDynamicSharedInformerFactory(client, 0),
+		stopCh:    stopCh,
+		informers: map[string]*informerEntry{},
+	}
+}
+
+func Test_ListResources(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c := newTestCache(stopCh, newService("ns1", "svc1"), newService("ns1", "svc2"), newService("ns2", "svc3"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resources, err := c.ListResources(ctx, "Service", "ns1")
+	assert.NilError(t, err)
+	assert.Equal(t, len(resources), 2)
+
+	resources, err = c.ListResources(ctx, "Service", "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(resources), 3)
+
+	_, err = c.ListResources(ctx, "Unknown", "")
+	assert.Error(t, err, "resource of kind Unknown not found")
+}
+
+func Test_ListResources_NotSynced(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c := newTestCache(stopCh)
+	// the cache of the kind never syncs
+	c.informers["Service"] = &informerEntry{synced: make(chan struct{})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.ListResources(ctx, "Service", "")
+	assert.Error(t, err, "the cache of kind Service is not synced: context deadline exceeded")
+}
+
+func Test_AddPolicy(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c := newTestCache(stopCh, newService("ns1", "svc1"))
+
+	policy := &kyverno.ClusterPolicy{}
+	policy.Spec.Rules = []kyverno.Rule{{Name: "pattern"}}
+	policy.Spec.Rules[0].MatchResources.Kinds = []string{"Pod"}
+	c.addPolicy(policy)
+	assert.Equal(t, len(c.informers), 0)
+
+	policy.Spec.Rules = append(policy.Spec.Rules, kyverno.Rule{Name: "count"})
+	policy.Spec.Rules[1].MatchResources.Kinds = []string{"Service", "Unknown"}
+	policy.Spec.Rules[1].Validation.Count = &kyverno.ResourceCount{Limit: 1}
+	c.addPolicy(policy)
+	assert.Equal(t, len(c.informers), 1)
+	entry, ok := c.informers["Service"]
+	assert.Assert(t, ok)
+
+	select {
+	case <-entry.synced:
+		assert.NilError(t, entry.err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the cache of kind Service did not sync")
+	}
+}
@@ -0,0 +1,161 @@
+package resultcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+	lru "github.com/hashicorp/golang-lru"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Cache stores the validation results of admission requests.
+// Controllers often resubmit byte-identical objects on resync, the results
+// of validate-only policies can be reused as long as the policies do not change
+type Cache struct {
+	lru *lru.Cache
+}
+
+// NewCache returns a cache holding the results of at most size requests
+// the cache is purged whenever a policy or a policy exception is added, updated or deleted
+func NewCache(size int, pInformer kyvernoinformer.ClusterPolicyInformer, peInformer kyvernoinformer.PolicyExceptionInformer) (*Cache, error) {
+	l, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{lru: l}
+	pInformer.Informer().AddEventHandler(c.purgeOnChange())
+	peInformer.Informer().AddEventHandler(c.purgeOnChange())
+	return c, nil
+}
+
+func (c *Cache) purgeOnChange() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.Purge()
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldObj, err := meta.Accessor(old)
+			if err != nil {
+				c.Purge()
+				return
+			}
+			curObj, err := meta.Accessor(cur)
+			if err != nil {
+				c.Purge()
+				return
+			}
+			// informer resync does not change the object
+			if oldObj.GetResourceVersion() == curObj.GetResourceVersion() {
+				return
+			}
+			// the status of the policies is updated on every admission, only changes
+			// of the evaluation invalidate the results
+			oldPolicy, oldOk := old.(*kyverno.ClusterPolicy)
+			curPolicy, curOk := cur.(*kyverno.ClusterPolicy)
+			if oldOk && curOk && policyVersion(*oldPolicy) == policyVersion(*curPolicy) {
+				return
+			}
+			c.Purge()
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.Purge()
+		},
+	}
+}
+
+// Get returns the cached engine responses for the key
+func (c *Cache) Get(key string) ([]response.EngineResponse, bool) {
+	value, ok := c.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.([]response.EngineResponse), true
+}
+
+// Add stores the engine responses for the key
+func (c *Cache) Add(key string, engineResponses []response.EngineResponse) {
+	c.lru.Add(key, engineResponses)
+}
+
+// Purge removes all the cached results
+func (c *Cache) Purge() {
+	glog.V(4).Info("purging admission result cache")
+	c.lru.Purge()
+}
+
+// Len returns the number of cached results
+func (c *Cache) Len() int {
+	return c.lru.Len()
+}
+
+// Key returns the cache key for the validation of the resource in the admission request
+// against the policies. The key combines the hash of the admitted object, the requesting
+// user as it can be matched and referenced in variables, and the hash of the policy set
+func Key(request *v1beta1.AdmissionRequest, resource []byte, userRequestInfo kyverno.RequestInfo, policies []kyverno.ClusterPolicy) (string, error) {
+	userInfo, err := json.Marshal(userRequestInfo)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, data := range [][]byte{
+		[]byte(request.Operation),
+		request.Object.Raw,
+		request.OldObject.Raw,
+		resource,
+		userInfo,
+	} {
+		h.Write(data)
+		// separator, so that the concatenation of the fields is unambiguous
+		h.Write([]byte{0})
+	}
+	objectHash := hex.EncodeToString(h.Sum(nil))
+	return objectHash + "/" + policySetHash(policies), nil
+}
+
+// policySetHash identifies the version of all the policies
+func policySetHash(policies []kyverno.ClusterPolicy) string {
+	// the lister does not guarantee the order of the policies
+	versions := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		versions = append(versions, policyVersion(policy))
+	}
+	sort.Strings(versions)
+
+	h := sha256.New()
+	for _, version := range versions {
+		h.Write([]byte(version))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// policyVersion changes when the evaluation of the policy changes. The generation is only
+// incremented by spec changes, unlike the resource version that also changes with the status
+// counters. The validation failure action depends on the rollout in the status
+func policyVersion(policy kyverno.ClusterPolicy) string {
+	return fmt.Sprintf("%s/%d/%s", policy.Name, policy.Generation, policy.GetValidationFailureAction())
+}
+
+// IsValidateOnly returns true if the policy only contains validate rules
+func IsValidateOnly(policy kyverno.ClusterPolicy) bool {
+	if len(policy.Spec.Rules) == 0 {
+		return false
+	}
+	for _, rule := range policy.Spec.Rules {
+		// counted resources change without policy changes
+		if rule.HasMutate() || rule.HasGenerate() || !rule.HasValidate() || rule.Validation.Count != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package resultcache
+
+import (
+	"testing"
+
+	lru "github.com/hashicorp/golang-lru"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newPolicy(name string, generation int64) kyverno.ClusterPolicy {
+	return kyverno.ClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Generation: generation},
+	}
+}
+
+func newRequest(raw string) *v1beta1.AdmissionRequest {
+	return &v1beta1.AdmissionRequest{
+		Operation: v1beta1.Create,
+		Object:    runtime.RawExtension{Raw: []byte(raw)},
+	}
+}
+
+func TestKey_PolicyOrder(t *testing.T) {
+	request := newRequest(`{"kind":"Pod"}`)
+	a, b := newPolicy("a", 1), newPolicy("b", 1)
+	key1, err := Key(request, nil, kyverno.RequestInfo{}, []kyverno.ClusterPolicy{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, err := Key(request, nil, kyverno.RequestInfo{}, []kyverno.ClusterPolicy{b, a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key1 != key2 {
+		t.Errorf("expected the key to be independent of the policy order")
+	}
+}
+
+func TestKey_Changes(t *testing.T) {
+	policies := []kyverno.ClusterPolicy{newPolicy("a", 1)}
+	key, _ := Key(newRequest(`{"kind":"Pod"}`), nil, kyverno.RequestInfo{}, policies)
+
+	updatedPolicy, _ := Key(newRequest(`{"kind":"Pod"}`), nil, kyverno.RequestInfo{}, []kyverno.ClusterPolicy{newPolicy("a", 2)})
+	if key == updatedPolicy {
+		t.Errorf("expected the key to change when a policy changes")
+	}
+	updatedObject, _ := Key(newRequest(`{"kind":"Pod","metadata":{}}`), nil, kyverno.RequestInfo{}, policies)
+	if key == updatedObject {
+		t.Errorf("expected the key to change when the object changes")
+	}
+	otherUser, _ := Key(newRequest(`{"kind":"Pod"}`), nil, kyverno.RequestInfo{Roles: []string{"ns:admin"}}, policies)
+	if key == otherUser {
+		t.Errorf("expected the key to change when the user changes")
+	}
+}
+
+func TestKey_StatusChanges(t *testing.T) {
+	policy := newPolicy("a", 1)
+	policy.ResourceVersion = "1"
+	policy.Spec.ValidationFailureAction = "enforce"
+	policy.Spec.RolloutStrategy = &kyverno.RolloutStrategy{ObservationWindow: "1h"}
+	key, _ := Key(newRequest(`{"kind":"Pod"}`), nil, kyverno.RequestInfo{}, []kyverno.ClusterPolicy{policy})
+
+	// the status sync updates the counters
+	counted := *policy.DeepCopy()
+	counted.ResourceVersion = "2"
+	counted.Status.RulesAppliedCount = 10
+	countedKey, _ := Key(newRequest(`{"kind":"Pod"}`), nil, kyverno.RequestInfo{}, []kyverno.ClusterPolicy{counted})
+	if key != countedKey {
+		t.Errorf("expected the key not to change when only the status counters change")
+	}
+
+	// the policy is promoted to enforce
+	promoted := *counted.DeepCopy()
+	promoted.Status.Rollout = &kyverno.RolloutStatus{Phase: kyverno.RolloutEnforced, ObservedGeneration: 1}
+	promotedKey, _ := Key(newRequest(`{"kind":"Pod"}`), nil, kyverno.RequestInfo{}, []kyverno.ClusterPolicy{promoted})
+	if key == promotedKey {
+		t.Errorf("expected the key to change when the rollout changes the validation failure action")
+	}
+}
+
+func TestCache_StatusUpdate(t *testing.T) {
+	l, err := lru.New(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Cache{lru: l}
+	handler := c.purgeOnChange()
+	old := newPolicy("a", 1)
+	old.ResourceVersion = "1"
+	c.Add("a", nil)
+
+	counted := old.DeepCopy()
+	counted.ResourceVersion = "2"
+	counted.Status.RulesAppliedCount = 10
+	handler.OnUpdate(&old, counted)
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected status updates not to purge the cache")
+	}
+
+	changed := counted.DeepCopy()
+	changed.ResourceVersion = "3"
+	changed.Generation = 2
+	handler.OnUpdate(counted, changed)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected spec changes to purge the cache")
+	}
+}
+
+func TestCache_GetAdd(t *testing.T) {
+	l, err := lru.New(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Cache{lru: l}
+	c.Add("a", []response.EngineResponse{{}})
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected key a to be cached")
+	}
+	c.Add("b", nil)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected key a to be evicted")
+	}
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("expected the cache to be empty after purge")
+	}
+}
+
+func TestIsValidateOnly(t *testing.T) {
+	validate := kyverno.Rule{Validation: kyverno.Validation{Pattern: map[string]interface{}{"a": "b"}}}
+	mutate := kyverno.Rule{Mutation: kyverno.Mutation{Overlay: map[string]interface{}{"a": "b"}}}
+
+	policy := kyverno.ClusterPolicy{Spec: kyverno.Spec{Rules: []kyverno.Rule{validate}}}
+	if !IsValidateOnly(policy) {
+		t.Errorf("expected policy to be validate-only")
+	}
+	policy.Spec.Rules = append(policy.Spec.Rules, mutate)
+	if IsValidateOnly(policy) {
+		t.Errorf("expected policy with a mutate rule not to be validate-only")
+	}
+
+	count := kyverno.Rule{Validation: kyverno.Validation{Count: &kyverno.ResourceCount{Limit: 5}}}
+	policy.Spec.Rules = []kyverno.Rule{count}
+	if IsValidateOnly(policy) {
+		t.Errorf("expected policy with a count rule not to be validate-only")
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/golang/glog"
@@ -80,7 +81,7 @@ func CRDInstalled(discovery client.IDiscovery) bool {
 func CleanupOldCrd(client *dclient.Client) {
 	gvr := client.DiscoveryClient.GetGVRFromKind("NamespacedPolicyViolation")
 	if !reflect.DeepEqual(gvr, (schema.GroupVersionResource{})) {
-		if err := client.DeleteResource("CustomResourceDefinition", "", "namespacedpolicyviolations.kyverno.io", false); err != nil {
+		if err := client.DeleteResource(context.TODO(), "CustomResourceDefinition", "", "namespacedpolicyviolations.kyverno.io", false); err != nil {
 			glog.Infof("Failed to remove previous CRD namespacedpolicyviolations: %v", err)
 		}
 	}
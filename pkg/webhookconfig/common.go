@@ -1,6 +1,7 @@
 package webhookconfig
 
 import (
+	"context"
 	"io/ioutil"
 
 	"github.com/golang/glog"
@@ -46,7 +47,7 @@ func extractCA(config *rest.Config) (result []byte) {
 }
 
 func (wrc *WebhookRegistrationClient) constructOwner() v1.OwnerReference {
-	kubePolicyDeployment, err := wrc.client.GetKubePolicyDeployment()
+	kubePolicyDeployment, err := wrc.client.GetKubePolicyDeployment(context.TODO())
 
 	if err != nil {
 		glog.Errorf("Error when constructing OwnerReference, err: %v\n", err)
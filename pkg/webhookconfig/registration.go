@@ -1,6 +1,7 @@
 package webhookconfig
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -106,7 +107,7 @@ func (wrc *WebhookRegistrationClient) CreateResourceMutatingWebhookConfiguration
 		// clientConfig - service
 		config = wrc.constructMutatingWebhookConfig(caData)
 	}
-	_, err := wrc.client.CreateResource(MutatingWebhookConfigurationKind, "", *config, false)
+	_, err := wrc.client.CreateResource(context.TODO(), MutatingWebhookConfigurationKind, "", *config, false)
 	if errorsapi.IsAlreadyExists(err) {
 		glog.V(4).Infof("resource mutating webhook configuration %s, already exists. not creating one", config.Name)
 		return nil
@@ -135,7 +136,7 @@ func (wrc *WebhookRegistrationClient) CreateResourceValidatingWebhookConfigurati
 		config = wrc.constructValidatingWebhookConfig(caData)
 	}
 
-	_, err := wrc.client.CreateResource(ValidatingWebhookConfigurationKind, "", *config, false)
+	_, err := wrc.client.CreateResource(context.TODO(), ValidatingWebhookConfigurationKind, "", *config, false)
 	if errorsapi.IsAlreadyExists(err) {
 		glog.V(4).Infof("resource validating webhook configuration %s, already exists. not creating one", config.Name)
 		return nil
@@ -170,7 +171,7 @@ func (wrc *WebhookRegistrationClient) createPolicyValidatingWebhookConfiguration
 	}
 
 	// create validating webhook configuration resource
-	if _, err := wrc.client.CreateResource(ValidatingWebhookConfigurationKind, "", *config, false); err != nil {
+	if _, err := wrc.client.CreateResource(context.TODO(), ValidatingWebhookConfigurationKind, "", *config, false); err != nil {
 		return err
 	}
 
@@ -200,7 +201,7 @@ func (wrc *WebhookRegistrationClient) createPolicyMutatingWebhookConfiguration()
 	}
 
 	// create mutating webhook configuration resource
-	if _, err := wrc.client.CreateResource(MutatingWebhookConfigurationKind, "", *config, false); err != nil {
+	if _, err := wrc.client.CreateResource(context.TODO(), MutatingWebhookConfigurationKind, "", *config, false); err != nil {
 		return err
 	}
 
@@ -230,7 +231,7 @@ func (wrc *WebhookRegistrationClient) createVerifyMutatingWebhookConfiguration()
 	}
 
 	// create mutating webhook configuration resource
-	if _, err := wrc.client.CreateResource(MutatingWebhookConfigurationKind, "", *config, false); err != nil {
+	if _, err := wrc.client.CreateResource(context.TODO(), MutatingWebhookConfigurationKind, "", *config, false); err != nil {
 		return err
 	}
 
@@ -292,7 +293,7 @@ func (wrc *WebhookRegistrationClient) removePolicyMutatingWebhookConfiguration(w
 	}
 
 	glog.V(4).Infof("removing webhook configuration %s", mutatingConfig)
-	err := wrc.client.DeleteResource(MutatingWebhookConfigurationKind, "", mutatingConfig, false)
+	err := wrc.client.DeleteResource(context.TODO(), MutatingWebhookConfigurationKind, "", mutatingConfig, false)
 	if errorsapi.IsNotFound(err) {
 		glog.V(4).Infof("policy webhook configuration %s, does not exits. not deleting", mutatingConfig)
 	} else if err != nil {
@@ -314,7 +315,7 @@ func (wrc *WebhookRegistrationClient) removePolicyValidatingWebhookConfiguration
 		validatingConfig = config.PolicyValidatingWebhookConfigurationName
 	}
 	glog.V(4).Infof("removing webhook configuration %s", validatingConfig)
-	err := wrc.client.DeleteResource(ValidatingWebhookConfigurationKind, "", validatingConfig, false)
+	err := wrc.client.DeleteResource(context.TODO(), ValidatingWebhookConfigurationKind, "", validatingConfig, false)
 	if errorsapi.IsNotFound(err) {
 		glog.V(4).Infof("policy webhook configuration %s, does not exits. not deleting", validatingConfig)
 	} else if err != nil {
@@ -1,6 +1,7 @@
 package webhookconfig
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/golang/glog"
@@ -71,7 +72,7 @@ func (wrc *WebhookRegistrationClient) RemoveResourceMutatingWebhookConfiguration
 
 	configName := wrc.GetResourceMutatingWebhookConfigName()
 	// delete webhook configuration
-	err := wrc.client.DeleteResource(MutatingWebhookConfigurationKind, "", configName, false)
+	err := wrc.client.DeleteResource(context.TODO(), MutatingWebhookConfigurationKind, "", configName, false)
 	if errors.IsNotFound(err) {
 		glog.V(4).Infof("resource webhook configuration %s does not exits, so not deleting", configName)
 		return nil
@@ -141,7 +142,7 @@ func (wrc *WebhookRegistrationClient) GetResourceValidatingWebhookConfigName() s
 
 func (wrc *WebhookRegistrationClient) RemoveResourceValidatingWebhookConfiguration() error {
 	configName := wrc.GetResourceValidatingWebhookConfigName()
-	err := wrc.client.DeleteResource(ValidatingWebhookConfigurationKind, "", configName, false)
+	err := wrc.client.DeleteResource(context.TODO(), ValidatingWebhookConfigurationKind, "", configName, false)
 	if errors.IsNotFound(err) {
 		glog.V(4).Infof("resource webhook configuration %s does not exits, so not deleting", configName)
 		return nil
@@ -1,6 +1,7 @@
 package webhooks
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -111,6 +112,24 @@ func processResourceWithPatches(patch []byte, resource []byte) []byte {
 	return resource
 }
 
+// mergePatches appends the operations of the JSON patch next to the operations of patch
+func mergePatches(patch, next []byte) ([]byte, error) {
+	if patch == nil {
+		return next, nil
+	}
+	if next == nil {
+		return patch, nil
+	}
+	var operations, nextOperations []json.RawMessage
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(next, &nextOperations); err != nil {
+		return nil, err
+	}
+	return json.Marshal(append(operations, nextOperations...))
+}
+
 func containRBACinfo(policies []kyverno.ClusterPolicy) bool {
 	for _, policy := range policies {
 		for _, rule := range policy.Spec.Rules {
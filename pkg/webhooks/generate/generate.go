@@ -1,59 +1,60 @@
 package generate
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	backoff "github.com/cenkalti/backoff"
 	"github.com/golang/glog"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	kyvernoclient "github.com/nirmata/kyverno/pkg/client/clientset/versioned"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
-//GenerateRequests provides interface to manage generate requests
-type GenerateRequests interface {
-	Create(gr kyverno.GenerateRequestSpec) error
+//UpdateRequests provides interface to manage update requests
+type UpdateRequests interface {
+	Create(ur kyverno.UpdateRequestSpec) error
 }
 
-// Generator defines the implmentation to mange generate request resource
+// Generator defines the implmentation to mange update request resource
 type Generator struct {
 	// channel to receive request
-	ch     chan kyverno.GenerateRequestSpec
+	ch     chan kyverno.UpdateRequestSpec
 	client *kyvernoclient.Clientset
 	stopCh <-chan struct{}
 }
 
-//NewGenerator returns a new instance of Generate-Request resource generator
+//NewGenerator returns a new instance of Update-Request resource generator
 func NewGenerator(client *kyvernoclient.Clientset, stopCh <-chan struct{}) *Generator {
 	gen := &Generator{
-		ch:     make(chan kyverno.GenerateRequestSpec, 1000),
+		ch:     make(chan kyverno.UpdateRequestSpec, 1000),
 		client: client,
 		stopCh: stopCh,
 	}
 	return gen
 }
 
-//Create to create generate request resoruce (blocking call if channel is full)
-func (g *Generator) Create(gr kyverno.GenerateRequestSpec) error {
-	glog.V(4).Infof("create GR %v", gr)
+//Create to create update request resoruce (blocking call if channel is full)
+func (g *Generator) Create(ur kyverno.UpdateRequestSpec) error {
+	glog.V(4).Infof("create UR %v", ur)
 	// Send to channel
 	select {
-	case g.ch <- gr:
+	case g.ch <- ur:
 		return nil
 	case <-g.stopCh:
 		glog.Info("shutting down channel")
-		return fmt.Errorf("shutting down gr create channel")
+		return fmt.Errorf("shutting down ur create channel")
 	}
 }
 
-// Run starts the generate request spec
+// Run starts the update request spec
 func (g *Generator) Run(workers int) {
 	defer utilruntime.HandleCrash()
-	glog.V(4).Info("Started generate request")
+	glog.V(4).Info("Started update request")
 	defer func() {
-		glog.V(4).Info("Shutting down generate request")
+		glog.V(4).Info("Shutting down update request")
 	}()
 	for i := 0; i < workers; i++ {
 		go wait.Until(g.process, time.Second, g.stopCh)
@@ -63,16 +64,26 @@ func (g *Generator) Run(workers int) {
 
 func (g *Generator) process() {
 	for r := range g.ch {
-		glog.V(4).Infof("received generate request %v", r)
+		glog.V(4).Infof("received update request %v", r)
 		if err := g.generate(r); err != nil {
-			glog.Errorf("Failed to create Generate Request CR: %v", err)
+			glog.Errorf("Failed to create Update Request CR: %v", err)
 		}
 	}
 }
 
-func (g *Generator) generate(grSpec kyverno.GenerateRequestSpec) error {
-	// create a generate request
-	if err := retryCreateResource(g.client, grSpec); err != nil {
+func (g *Generator) generate(urSpec kyverno.UpdateRequestSpec) error {
+	// stop retrying on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-g.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	// create an update request
+	if err := retryCreateResource(ctx, g.client, urSpec); err != nil {
 		return err
 	}
 	return nil
@@ -81,38 +92,19 @@ func (g *Generator) generate(grSpec kyverno.GenerateRequestSpec) error {
 // -> receiving channel to take requests to create request
 // use worker pattern to read and create the CR resource
 
-func retryCreateResource(client *kyvernoclient.Clientset, grSpec kyverno.GenerateRequestSpec) error {
-	var i int
-	var err error
+func retryCreateResource(ctx context.Context, client *kyvernoclient.Clientset, urSpec kyverno.UpdateRequestSpec) error {
 	createResource := func() error {
-		gr := kyverno.GenerateRequest{
-			Spec: grSpec,
+		ur := kyverno.UpdateRequest{
+			Spec: urSpec,
 		}
-		gr.SetGenerateName("gr-")
-		gr.SetNamespace("kyverno")
+		ur.SetGenerateName("ur-")
+		ur.SetNamespace("kyverno")
 		// Initial state "Pending"
 		// TODO: status is not updated
-		// gr.Status.State = kyverno.Pending
-		// generate requests created in kyverno namespace
-		_, err = client.KyvernoV1().GenerateRequests("kyverno").Create(&gr)
-		glog.V(4).Infof("retry %v create generate request", i)
-		i++
-		return err
-	}
-	exbackoff := &backoff.ExponentialBackOff{
-		InitialInterval:     500 * time.Millisecond,
-		RandomizationFactor: 0.5,
-		Multiplier:          1.5,
-		MaxInterval:         time.Second,
-		MaxElapsedTime:      3 * time.Second,
-		Clock:               backoff.SystemClock,
-	}
-
-	exbackoff.Reset()
-	err = backoff.Retry(createResource, exbackoff)
-	if err != nil {
+		// ur.Status.State = kyverno.Pending
+		// update requests created in kyverno namespace
+		_, err := client.KyvernoV1().UpdateRequests("kyverno").Create(&ur)
 		return err
 	}
-
-	return nil
+	return dclient.Retry(ctx, dclient.DefaultRetryConfig, createResource)
 }
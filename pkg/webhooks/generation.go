@@ -14,6 +14,7 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/webhooks/generate"
 	v1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 //HandleGenerate handles admission-requests for policies with generate rules
@@ -32,32 +33,8 @@ func (ws *WebhookServer) HandleGenerate(request *v1beta1.AdmissionRequest, polic
 	glog.V(4).Infof("Handle Generate: Kind=%s, Namespace=%s Name=%s UID=%s patchOperation=%s",
 		resource.GetKind(), resource.GetNamespace(), resource.GetName(), request.UID, request.Operation)
 
-	userRequestInfo := kyverno.RequestInfo{
-		Roles:             roles,
-		ClusterRoles:      clusterRoles,
-		AdmissionUserInfo: request.UserInfo}
-	// build context
-	ctx := context.NewContext()
-	// load incoming resource into the context
-	err = ctx.AddResource(request.Object.Raw)
-	if err != nil {
-		glog.Infof("Failed to load resource in context:%v", err)
-	}
-	err = ctx.AddUserInfo(userRequestInfo)
-	if err != nil {
-		glog.Infof("Failed to load userInfo in context:%v", err)
-	}
-	// load service account in context
-	err = ctx.AddSA(userRequestInfo.AdmissionUserInfo.Username)
-	if err != nil {
-		glog.Infof("Failed to load service account in context:%v", err)
-	}
-
-	policyContext := engine.PolicyContext{
-		NewResource:   *resource,
-		AdmissionInfo: userRequestInfo,
-		Context:       ctx,
-	}
+	policyContext := newPolicyContext(request, *resource, roles, clusterRoles)
+	userRequestInfo := policyContext.AdmissionInfo
 
 	// engine.Generate returns a list of rules that are applicable on this resource
 	for _, policy := range policies {
@@ -71,10 +48,10 @@ func (ws *WebhookServer) HandleGenerate(request *v1beta1.AdmissionRequest, polic
 			})
 		}
 	}
-	// Adds Generate Request to a channel(queue size 1000) to generators
-	if err := createGenerateRequest(ws.grGenerator, userRequestInfo, engineResponses...); err != nil {
+	// Adds Update Request to a channel(queue size 1000) to generators
+	if err := createUpdateRequest(ws.urGenerator, kyverno.Generate, userRequestInfo, engineResponses...); err != nil {
 		//TODO: send appropriate error
-		return false, "Kyverno blocked: failed to create Generate Requests"
+		return false, "Kyverno blocked: failed to create Update Requests"
 	}
 	// Generate Stats wont be used here, as we delegate the generate rule
 	// - Filter policies that apply on this resource
@@ -87,28 +64,60 @@ func (ws *WebhookServer) HandleGenerate(request *v1beta1.AdmissionRequest, polic
 	return true, ""
 }
 
-func createGenerateRequest(gnGenerator generate.GenerateRequests, userRequestInfo kyverno.RequestInfo, engineResponses ...response.EngineResponse) error {
+// newPolicyContext returns the context to evaluate the rules applied asynchronously
+// through update requests, on the resource of the admission request
+func newPolicyContext(request *v1beta1.AdmissionRequest, resource unstructured.Unstructured, roles, clusterRoles []string) engine.PolicyContext {
+	userRequestInfo := kyverno.RequestInfo{
+		Roles:             roles,
+		ClusterRoles:      clusterRoles,
+		AdmissionUserInfo: request.UserInfo}
+	// build context
+	ctx := context.NewContext()
+	// load incoming resource into the context
+	err := ctx.AddResource(request.Object.Raw)
+	if err != nil {
+		glog.Infof("Failed to load resource in context:%v", err)
+	}
+	err = ctx.AddUserInfo(userRequestInfo)
+	if err != nil {
+		glog.Infof("Failed to load userInfo in context:%v", err)
+	}
+	// load service account in context
+	err = ctx.AddSA(userRequestInfo.AdmissionUserInfo.Username)
+	if err != nil {
+		glog.Infof("Failed to load service account in context:%v", err)
+	}
+
+	return engine.PolicyContext{
+		NewResource:   resource,
+		AdmissionInfo: userRequestInfo,
+		Context:       ctx,
+	}
+}
+
+func createUpdateRequest(urGenerator generate.UpdateRequests, requestType kyverno.RequestType, userRequestInfo kyverno.RequestInfo, engineResponses ...response.EngineResponse) error {
 	for _, er := range engineResponses {
-		if err := gnGenerator.Create(transform(userRequestInfo, er)); err != nil {
+		if err := urGenerator.Create(transform(requestType, userRequestInfo, er)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func transform(userRequestInfo kyverno.RequestInfo, er response.EngineResponse) kyverno.GenerateRequestSpec {
-	gr := kyverno.GenerateRequestSpec{
+func transform(requestType kyverno.RequestType, userRequestInfo kyverno.RequestInfo, er response.EngineResponse) kyverno.UpdateRequestSpec {
+	ur := kyverno.UpdateRequestSpec{
+		Type:   requestType,
 		Policy: er.PolicyResponse.Policy,
 		Resource: kyverno.ResourceSpec{
 			Kind:      er.PolicyResponse.Resource.Kind,
 			Namespace: er.PolicyResponse.Resource.Namespace,
 			Name:      er.PolicyResponse.Resource.Name,
 		},
-		Context: kyverno.GenerateRequestContext{
+		Context: kyverno.UpdateRequestContext{
 			UserRequestInfo: userRequestInfo,
 		},
 	}
-	return gr
+	return ur
 }
 
 type generateStats struct {
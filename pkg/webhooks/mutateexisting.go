@@ -0,0 +1,49 @@
+package webhooks
+
+import (
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	v1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+// handleMutateExisting creates the update requests of the mutate rules on existing targets, for the
+// create and update requests that are not dry-run
+// Success -> Update Request CR created successsfully
+// Failed -> Failed to create Update Request CR
+func (ws *WebhookServer) handleMutateExisting(request *v1beta1.AdmissionRequest, policies []kyverno.ClusterPolicy, roles, clusterRoles []string) (bool, string) {
+	if request.Operation != v1beta1.Create && request.Operation != v1beta1.Update {
+		return true, ""
+	}
+	if request.DryRun != nil && *request.DryRun {
+		return true, ""
+	}
+	return ws.HandleMutateExisting(request, policies, roles, clusterRoles)
+}
+
+// HandleMutateExisting handles admission-requests for policies with mutate rules on existing targets,
+// the targets are mutated by the update request controller
+func (ws *WebhookServer) HandleMutateExisting(request *v1beta1.AdmissionRequest, policies []kyverno.ClusterPolicy, roles, clusterRoles []string) (bool, string) {
+	var engineResponses []response.EngineResponse
+
+	resource, err := utils.ConvertToUnstructured(request.Object.Raw)
+	if err != nil {
+		glog.Errorf("unable to convert raw resource to unstructured: %v", err)
+		return true, ""
+	}
+
+	policyContext := newPolicyContext(request, *resource, roles, clusterRoles)
+	for _, policy := range policies {
+		policyContext.Policy = policy
+		engineResponse := engine.MutateExisting(policyContext)
+		if len(engineResponse.PolicyResponse.Rules) > 0 {
+			engineResponses = append(engineResponses, engineResponse)
+		}
+	}
+	if err := createUpdateRequest(ws.urGenerator, kyverno.Mutate, policyContext.AdmissionInfo, engineResponses...); err != nil {
+		return false, "Kyverno blocked: failed to create Update Requests"
+	}
+	return true, ""
+}
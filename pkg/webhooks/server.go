@@ -16,11 +16,15 @@ import (
 	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
 	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/cosign"
 	client "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine"
 	"github.com/nirmata/kyverno/pkg/event"
+	"github.com/nirmata/kyverno/pkg/policyexception"
 	"github.com/nirmata/kyverno/pkg/policystatus"
 	"github.com/nirmata/kyverno/pkg/policystore"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
+	"github.com/nirmata/kyverno/pkg/resultcache"
 	tlsutils "github.com/nirmata/kyverno/pkg/tls"
 	userinfo "github.com/nirmata/kyverno/pkg/userinfo"
 	"github.com/nirmata/kyverno/pkg/webhookconfig"
@@ -42,6 +46,10 @@ type WebhookServer struct {
 	pLister kyvernolister.ClusterPolicyLister
 	// returns true if the cluster policy store has synced atleast
 	pSynced cache.InformerSynced
+	// look up the policy exceptions of a policy
+	exceptions *policyexception.Lister
+	// returns true if the policy exception store has synced atleast once
+	peSynced cache.InformerSynced
 	// list/get role binding resource
 	rbLister rbaclister.RoleBindingLister
 	// return true if role bining store has synced atleast once
@@ -67,8 +75,14 @@ type WebhookServer struct {
 	// policy violation generator
 	pvGenerator policyviolation.GeneratorInterface
 	// generate request generator
-	grGenerator            *generate.Generator
+	urGenerator            *generate.Generator
 	resourceWebhookWatcher *webhookconfig.ResourceWebhookRegister
+	// cache for the validation results of repeated requests, nil if disabled
+	resultCache *resultcache.Cache
+	// verifies the image signatures required by verifyImages rules
+	imageVerifier cosign.Verifier
+	// lists the resources counted by count rules
+	resourceCache engine.ResourceLister
 }
 
 // NewWebhookServer creates new instance of WebhookServer accordingly to given configuration
@@ -78,6 +92,7 @@ func NewWebhookServer(
 	client *client.Client,
 	tlsPair *tlsutils.TlsPemPair,
 	pInformer kyvernoinformer.ClusterPolicyInformer,
+	peInformer kyvernoinformer.PolicyExceptionInformer,
 	rbInformer rbacinformer.RoleBindingInformer,
 	crbInformer rbacinformer.ClusterRoleBindingInformer,
 	eventGen event.Interface,
@@ -86,8 +101,11 @@ func NewWebhookServer(
 	configHandler config.Interface,
 	pMetaStore policystore.LookupInterface,
 	pvGenerator policyviolation.GeneratorInterface,
-	grGenerator *generate.Generator,
+	urGenerator *generate.Generator,
 	resourceWebhookWatcher *webhookconfig.ResourceWebhookRegister,
+	resultCache *resultcache.Cache,
+	imageVerifier cosign.Verifier,
+	resourceCache engine.ResourceLister,
 	cleanUp chan<- struct{}) (*WebhookServer, error) {
 
 	if tlsPair == nil {
@@ -101,11 +119,18 @@ func NewWebhookServer(
 	}
 	tlsConfig.Certificates = []tls.Certificate{pair}
 
+	exceptions, err := policyexception.NewLister(peInformer)
+	if err != nil {
+		return nil, err
+	}
+
 	ws := &WebhookServer{
 		client:                    client,
 		kyvernoClient:             kyvernoClient,
 		pLister:                   pInformer.Lister(),
 		pSynced:                   pInformer.Informer().HasSynced,
+		exceptions:                exceptions,
+		peSynced:                  peInformer.Informer().HasSynced,
 		rbLister:                  rbInformer.Lister(),
 		rbSynced:                  rbInformer.Informer().HasSynced,
 		crbLister:                 crbInformer.Lister(),
@@ -118,8 +143,11 @@ func NewWebhookServer(
 		lastReqTime:               resourceWebhookWatcher.LastReqTime,
 		pvGenerator:               pvGenerator,
 		pMetaStore:                pMetaStore,
-		grGenerator:               grGenerator,
+		urGenerator:               urGenerator,
 		resourceWebhookWatcher:    resourceWebhookWatcher,
+		resultCache:               resultCache,
+		imageVerifier:             imageVerifier,
+		resourceCache:             resourceCache,
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc(config.MutatingWebhookServicePath, ws.serve)
@@ -156,6 +184,10 @@ func (ws *WebhookServer) serve(w http.ResponseWriter, r *http.Request) {
 		Allowed: true,
 	}
 
+	// the calls made while processing the request are bounded by its deadline
+	ctx, cancel := admissionContext(r)
+	defer cancel()
+
 	// Do not process the admission requests for kinds that are in filterKinds for filtering
 	request := admissionReview.Request
 	switch r.URL.Path {
@@ -165,11 +197,11 @@ func (ws *WebhookServer) serve(w http.ResponseWriter, r *http.Request) {
 		admissionReview.Response = ws.handleVerifyRequest(request)
 	case config.MutatingWebhookServicePath:
 		if !ws.configHandler.ToFilter(request.Kind.Kind, request.Namespace, request.Name) {
-			admissionReview.Response = ws.handleMutateAdmissionRequest(request)
+			admissionReview.Response = ws.handleMutateAdmissionRequest(ctx, request)
 		}
 	case config.ValidatingWebhookServicePath:
 		if !ws.configHandler.ToFilter(request.Kind.Kind, request.Namespace, request.Name) {
-			admissionReview.Response = ws.handleValidateAdmissionRequest(request)
+			admissionReview.Response = ws.handleValidateAdmissionRequest(ctx, request)
 		}
 	case config.PolicyValidatingWebhookServicePath:
 		if !ws.configHandler.ToFilter(request.Kind.Kind, request.Namespace, request.Name) {
@@ -194,7 +226,16 @@ func (ws *WebhookServer) serve(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (ws *WebhookServer) handleMutateAdmissionRequest(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
+// admissionContext returns the context of the admission request, with the timeout of the webhook call
+// that the API server passes in the timeout query parameter, e.g. ?timeout=10s
+func admissionContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if timeout, err := time.ParseDuration(r.URL.Query().Get("timeout")); err == nil && timeout > 0 {
+		return context.WithTimeout(r.Context(), timeout)
+	}
+	return context.WithCancel(r.Context())
+}
+
+func (ws *WebhookServer) handleMutateAdmissionRequest(ctx context.Context, request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
 	policies, err := ws.pMetaStore.ListAll()
 	if err != nil {
 		// Unable to connect to policy Lister to access policies
@@ -247,9 +288,32 @@ func (ws *WebhookServer) handleMutateAdmissionRequest(request *v1beta1.Admission
 	// patch the resource with patches before handling validation rules
 	patchedResource := processResourceWithPatches(patches, request.Object.Raw)
 
+	// VERIFY IMAGES
+	// images failing the signature verification block the request in "enforce" mode,
+	// verified images are pinned to their digest
+	imagePatches, ok, msg := ws.HandleVerifyImages(ctx, request, policies, patchedResource, roles, clusterRoles)
+	if !ok {
+		glog.V(4).Infof("Deny admission request: %v/%s/%s", request.Kind, request.Namespace, request.Name)
+		return &v1beta1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  "Failure",
+				Message: msg,
+			},
+		}
+	}
+	if imagePatches != nil {
+		if merged, err := mergePatches(patches, imagePatches); err != nil {
+			glog.Errorf("failed to merge image patches: %v", err)
+		} else {
+			patches = merged
+			patchedResource = processResourceWithPatches(imagePatches, patchedResource)
+		}
+	}
+
 	if ws.resourceWebhookWatcher != nil && ws.resourceWebhookWatcher.RunValidationInMutatingWebhook == "true" {
 		// VALIDATION
-		ok, msg := ws.HandleValidation(request, policies, patchedResource, roles, clusterRoles)
+		ok, msg := ws.HandleValidation(ctx, request, policies, patchedResource, roles, clusterRoles)
 		if !ok {
 			glog.V(4).Infof("Deny admission request: %v/%s/%s", request.Kind, request.Namespace, request.Name)
 			return &v1beta1.AdmissionResponse{
@@ -264,8 +328,8 @@ func (ws *WebhookServer) handleMutateAdmissionRequest(request *v1beta1.Admission
 
 	// GENERATE
 	// Only applied during resource creation
-	// Success -> Generate Request CR created successsfully
-	// Failed -> Failed to create Generate Request CR
+	// Success -> Update Request CR created successsfully
+	// Failed -> Failed to create Update Request CR
 	if request.Operation == v1beta1.Create {
 		ok, msg := ws.HandleGenerate(request, policies, patchedResource, roles, clusterRoles)
 		if !ok {
@@ -279,6 +343,21 @@ func (ws *WebhookServer) handleMutateAdmissionRequest(request *v1beta1.Admission
 			}
 		}
 	}
+	// MUTATE EXISTING
+	// the update requests are created by the validating webhook, once the request is admitted,
+	// unless there is no validating webhook
+	if ws.resourceWebhookWatcher != nil && ws.resourceWebhookWatcher.RunValidationInMutatingWebhook == "true" {
+		if ok, msg := ws.handleMutateExisting(request, policies, roles, clusterRoles); !ok {
+			glog.V(4).Infof("Deny admission request: %v/%s/%s", request.Kind, request.Namespace, request.Name)
+			return &v1beta1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Status:  "Failure",
+					Message: msg,
+				},
+			}
+		}
+	}
 	// Succesfful processing of mutation & validation rules in policy
 	patchType := v1beta1.PatchTypeJSONPatch
 	return &v1beta1.AdmissionResponse{
@@ -291,7 +370,7 @@ func (ws *WebhookServer) handleMutateAdmissionRequest(request *v1beta1.Admission
 	}
 }
 
-func (ws *WebhookServer) handleValidateAdmissionRequest(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
+func (ws *WebhookServer) handleValidateAdmissionRequest(ctx context.Context, request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
 	policies, err := ws.pMetaStore.ListAll()
 	if err != nil {
 		// Unable to connect to policy Lister to access policies
@@ -314,7 +393,7 @@ func (ws *WebhookServer) handleValidateAdmissionRequest(request *v1beta1.Admissi
 	glog.V(4).Infof("Time: webhook GetRoleRef %v", time.Since(startTime))
 
 	// VALIDATION
-	ok, msg := ws.HandleValidation(request, policies, nil, roles, clusterRoles)
+	ok, msg := ws.HandleValidation(ctx, request, policies, nil, roles, clusterRoles)
 	if !ok {
 		glog.V(4).Infof("Deny admission request: %v/%s/%s", request.Kind, request.Namespace, request.Name)
 		return &v1beta1.AdmissionResponse{
@@ -326,6 +405,19 @@ func (ws *WebhookServer) handleValidateAdmissionRequest(request *v1beta1.Admissi
 		}
 	}
 
+	// MUTATE EXISTING
+	// the targets of the mutate rules are only mutated for admitted requests
+	if ok, msg := ws.handleMutateExisting(request, policies, roles, clusterRoles); !ok {
+		glog.V(4).Infof("Deny admission request: %v/%s/%s", request.Kind, request.Namespace, request.Name)
+		return &v1beta1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  "Failure",
+				Message: msg,
+			},
+		}
+	}
+
 	return &v1beta1.AdmissionResponse{
 		Allowed: true,
 		Result: &metav1.Status{
@@ -336,7 +428,7 @@ func (ws *WebhookServer) handleValidateAdmissionRequest(request *v1beta1.Admissi
 
 // RunAsync TLS server in separate thread and returns control immediately
 func (ws *WebhookServer) RunAsync(stopCh <-chan struct{}) {
-	if !cache.WaitForCacheSync(stopCh, ws.pSynced, ws.rbSynced, ws.crbSynced) {
+	if !cache.WaitForCacheSync(stopCh, ws.pSynced, ws.peSynced, ws.rbSynced, ws.crbSynced) {
 		glog.Error("webhook: failed to sync informer cache")
 	}
 
@@ -0,0 +1,22 @@
+package webhooks
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func Test_AdmissionContext(t *testing.T) {
+	ctx, cancel := admissionContext(httptest.NewRequest("POST", "/mutate?timeout=10s", nil))
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.Assert(t, ok)
+	assert.Assert(t, time.Until(deadline) <= 10*time.Second)
+
+	ctx, cancel = admissionContext(httptest.NewRequest("POST", "/mutate", nil))
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.Assert(t, !ok)
+}
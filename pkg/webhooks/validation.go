@@ -1,6 +1,7 @@
 package webhooks
 
 import (
+	gocontext "context"
 	"reflect"
 	"sort"
 	"time"
@@ -12,13 +13,14 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/context"
 	"github.com/nirmata/kyverno/pkg/engine/response"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
+	"github.com/nirmata/kyverno/pkg/resultcache"
 	v1beta1 "k8s.io/api/admission/v1beta1"
 )
 
 // HandleValidation handles validating webhook admission request
 // If there are no errors in validating rule we apply generation rules
 // patchedResource is the (resource + patches) after applying mutation rules
-func (ws *WebhookServer) HandleValidation(request *v1beta1.AdmissionRequest, policies []kyverno.ClusterPolicy, patchedResource []byte, roles, clusterRoles []string) (bool, string) {
+func (ws *WebhookServer) HandleValidation(requestCtx gocontext.Context, request *v1beta1.AdmissionRequest, policies []kyverno.ClusterPolicy, patchedResource []byte, roles, clusterRoles []string) (bool, string) {
 	glog.V(4).Infof("Receive request in validating webhook: Kind=%s, Namespace=%s Name=%s UID=%s patchOperation=%s",
 		request.Kind.Kind, request.Namespace, request.Name, request.UID, request.Operation)
 
@@ -54,16 +56,35 @@ func (ws *WebhookServer) HandleValidation(request *v1beta1.AdmissionRequest, pol
 	}
 
 	policyContext := engine.PolicyContext{
-		NewResource:   newR,
-		OldResource:   oldR,
-		Context:       ctx,
-		AdmissionInfo: userRequestInfo,
+		NewResource:    newR,
+		OldResource:    oldR,
+		Context:        ctx,
+		AdmissionInfo:  userRequestInfo,
+		Exceptions:     ws.exceptions,
+		ResourceLister: ws.resourceCache,
+		RequestContext: requestCtx,
 	}
 	var engineResponses []response.EngineResponse
+	// results of validate-only policies are reused for identical requests
+	cacheKey, cachedResponses, policies := ws.lookupResultCache(request, patchedResource, userRequestInfo, policies)
+	for _, engineResponse := range cachedResponses {
+		engineResponses = append(engineResponses, engineResponse)
+		ws.statusListener.Send(validateStats{
+			resp: engineResponse,
+		})
+	}
+	var responsesToCache []response.EngineResponse
 	for _, policy := range policies {
 		glog.V(2).Infof("Handling validation for Kind=%s, Namespace=%s Name=%s UID=%s patchOperation=%s",
 			newR.GetKind(), newR.GetNamespace(), newR.GetName(), request.UID, request.Operation)
 		policyContext.Policy = policy
+		// signed manifests are verified on every request, unchanged evaluations must not skip them
+		if verifyResponse := engine.VerifyManifests(policyContext); len(verifyResponse.PolicyResponse.Rules) != 0 {
+			engineResponses = append(engineResponses, verifyResponse)
+			ws.statusListener.Send(validateStats{
+				resp: verifyResponse,
+			})
+		}
 		engineResponse := engine.Validate(policyContext)
 		if reflect.DeepEqual(engineResponse, response.EngineResponse{}) {
 			// we get an empty response if old and new resources created the same response
@@ -71,6 +92,9 @@ func (ws *WebhookServer) HandleValidation(request *v1beta1.AdmissionRequest, pol
 			continue
 		}
 		engineResponses = append(engineResponses, engineResponse)
+		if cacheKey != "" && resultcache.IsValidateOnly(policy) {
+			responsesToCache = append(responsesToCache, engineResponse)
+		}
 		ws.statusListener.Send(validateStats{
 			resp: engineResponse,
 		})
@@ -79,6 +103,9 @@ func (ws *WebhookServer) HandleValidation(request *v1beta1.AdmissionRequest, pol
 			continue
 		}
 	}
+	if cacheKey != "" {
+		ws.resultCache.Add(cacheKey, responsesToCache)
+	}
 	glog.V(4).Infof("eval: %v %s/%s/%s ", time.Since(evalTime), request.Kind, request.Namespace, request.Name)
 	// report time
 	reportTime := time.Now()
@@ -113,6 +140,34 @@ func (ws *WebhookServer) HandleValidation(request *v1beta1.AdmissionRequest, pol
 	return true, ""
 }
 
+// lookupResultCache returns the cache key for the request, the cached responses of the validate-only policies
+// and the policies that still need to be evaluated. An empty key is returned if the result cache is disabled
+// or the results are already cached
+func (ws *WebhookServer) lookupResultCache(request *v1beta1.AdmissionRequest, patchedResource []byte, userRequestInfo kyverno.RequestInfo, policies []kyverno.ClusterPolicy) (string, []response.EngineResponse, []kyverno.ClusterPolicy) {
+	if ws.resultCache == nil {
+		return "", nil, policies
+	}
+	key, err := resultcache.Key(request, patchedResource, userRequestInfo, policies)
+	if err != nil {
+		glog.V(4).Infof("failed to compute result cache key: %v", err)
+		return "", nil, policies
+	}
+	cachedResponses, ok := ws.resultCache.Get(key)
+	if !ok {
+		return key, nil, policies
+	}
+
+	glog.V(4).Infof("using cached validation results for Kind=%s, Namespace=%s Name=%s UID=%s",
+		request.Kind.Kind, request.Namespace, request.Name, request.UID)
+	var remaining []kyverno.ClusterPolicy
+	for _, policy := range policies {
+		if !resultcache.IsValidateOnly(policy) {
+			remaining = append(remaining, policy)
+		}
+	}
+	return "", cachedResponses, remaining
+}
+
 type validateStats struct {
 	resp response.EngineResponse
 }
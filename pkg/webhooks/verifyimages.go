@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/golang/glog"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/policyviolation"
+	v1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+// HandleVerifyImages verifies the image signatures required by the verifyImages rules
+// patchedResource is the (resource + patches) after applying mutation rules
+// return value: the patches pinning the verified images to their digest, and false with the
+// error message if the request is blocked
+func (ws *WebhookServer) HandleVerifyImages(requestCtx gocontext.Context, request *v1beta1.AdmissionRequest, policies []kyverno.ClusterPolicy, patchedResource []byte, roles, clusterRoles []string) ([]byte, bool, string) {
+	policies = filterVerifyImagesPolicies(policies)
+	if len(policies) == 0 {
+		return nil, true, ""
+	}
+	glog.V(4).Infof("Receive request in verifyImages: Kind=%s, Namespace=%s Name=%s UID=%s patchOperation=%s",
+		request.Kind.Kind, request.Namespace, request.Name, request.UID, request.Operation)
+
+	evalTime := time.Now()
+	newR, _, err := extractResources(patchedResource, request)
+	if err != nil {
+		// as resource cannot be parsed, we skip processing
+		glog.Error(err)
+		return nil, true, ""
+	}
+	userRequestInfo := kyverno.RequestInfo{
+		Roles:             roles,
+		ClusterRoles:      clusterRoles,
+		AdmissionUserInfo: request.UserInfo}
+	// build context
+	ctx := context.NewContext()
+	// load incoming resource into the context
+	if err := ctx.AddResource(request.Object.Raw); err != nil {
+		glog.Infof("Failed to load resource in context:%v", err)
+	}
+	if err := ctx.AddUserInfo(userRequestInfo); err != nil {
+		glog.Infof("Failed to load userInfo in context:%v", err)
+	}
+	if err := ctx.AddSA(userRequestInfo.AdmissionUserInfo.Username); err != nil {
+		glog.Infof("Failed to load service account in context:%v", err)
+	}
+
+	policyContext := engine.PolicyContext{
+		NewResource:    newR,
+		Context:        ctx,
+		AdmissionInfo:  userRequestInfo,
+		ImageVerifier:  ws.imageVerifier,
+		Exceptions:     ws.exceptions,
+		RequestContext: requestCtx,
+	}
+	var patches [][]byte
+	var engineResponses []response.EngineResponse
+	for _, policy := range policies {
+		policyContext.Policy = policy
+		engineResponse := engine.VerifyImages(policyContext)
+		if len(engineResponse.PolicyResponse.Rules) == 0 {
+			continue
+		}
+		engineResponses = append(engineResponses, engineResponse)
+		ws.statusListener.Send(validateStats{
+			resp: engineResponse,
+		})
+		if !engineResponse.IsSuccesful() {
+			glog.V(4).Infof("Failed to verify images of resource %s/%s with policy %s\n", newR.GetNamespace(), newR.GetName(), policy.Name)
+			continue
+		}
+		patches = append(patches, engineResponse.GetPatches()...)
+		policyContext.NewResource = engineResponse.PatchedResource
+	}
+	glog.V(4).Infof("eval: %v %s/%s/%s ", time.Since(evalTime), request.Kind, request.Namespace, request.Name)
+
+	// images failing the verification of a policy in "enforce" mode block the request
+	blocked := toBlockResource(engineResponses)
+	events := generateEvents(engineResponses, blocked, (request.Operation == v1beta1.Update))
+	ws.eventGen.Add(events...)
+	if blocked {
+		glog.V(4).Infof("resource %s/%s/%s is blocked\n", newR.GetKind(), newR.GetNamespace(), newR.GetName())
+		return nil, false, getEnforceFailureErrorMsg(engineResponses)
+	}
+
+	// violations are created with resource on "audit"
+	pvInfos := policyviolation.GeneratePVsFromEngineResponse(engineResponses)
+	ws.pvGenerator.Add(pvInfos...)
+	return engineutils.JoinPatches(patches), true, ""
+}
+
+func filterVerifyImagesPolicies(policies []kyverno.ClusterPolicy) []kyverno.ClusterPolicy {
+	var filtered []kyverno.ClusterPolicy
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			if rule.HasVerifyImages() {
+				filtered = append(filtered, policy)
+				break
+			}
+		}
+	}
+	return filtered
+}
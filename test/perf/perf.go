@@ -0,0 +1,266 @@
+// Package perf provides synthetic policies, resources and an admission load
+// generator used to benchmark the policy engine
+package perf
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Config describes the shape of a synthetic admission load
+type Config struct {
+	// number of cluster policies, each with a mutate and a validate rule
+	Policies int
+	// number of distinct pods submitted as admission requests
+	Resources int
+	// number of goroutines processing requests concurrently
+	Workers int
+}
+
+// Result stores the latency statistics of a load run
+type Result struct {
+	Requests int
+	Duration time.Duration
+	P50      time.Duration
+	P95      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+}
+
+// Throughput returns the number of admission requests processed per second
+func (r Result) Throughput() float64 {
+	if r.Duration == 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("requests=%d duration=%v throughput=%.1f/s p50=%v p95=%v p99=%v max=%v",
+		r.Requests, r.Duration, r.Throughput(), r.P50, r.P95, r.P99, r.Max)
+}
+
+const policyTemplate = `{
+	"apiVersion": "kyverno.io/v1",
+	"kind": "ClusterPolicy",
+	"metadata": {
+		"name": "perf-policy-%[1]d"
+	},
+	"spec": {
+		"validationFailureAction": "audit",
+		"rules": [
+			{
+				"name": "add-label-%[1]d",
+				"match": {
+					"resources": {
+						"kinds": ["Pod"]
+					}
+				},
+				"mutate": {
+					"overlay": {
+						"metadata": {
+							"labels": {
+								"+(perf.kyverno.io/policy-%[1]d)": "{{request.object.metadata.name}}"
+							}
+						}
+					}
+				}
+			},
+			{
+				"name": "check-image-%[1]d",
+				"match": {
+					"resources": {
+						"kinds": ["Pod"]
+					}
+				},
+				"validate": {
+					"message": "An image tag is required",
+					"pattern": {
+						"spec": {
+							"containers": [
+								{
+									"image": "*:*",
+									"resources": {
+										"limits": {
+											"memory": "?*"
+										}
+									}
+								}
+							]
+						}
+					}
+				}
+			}
+		]
+	}
+}`
+
+const resourceTemplate = `{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {
+		"name": "perf-pod-%[1]d",
+		"namespace": "perf-%[2]d",
+		"labels": {
+			"app": "perf-%[1]d"
+		}
+	},
+	"spec": {
+		"containers": [
+			{
+				"name": "app",
+				"image": "nginx:1.17",
+				"resources": {
+					"limits": {
+						"memory": "64Mi"
+					}
+				}
+			},
+			{
+				"name": "sidecar",
+				"image": "busybox:%[1]d",
+				"resources": {
+					"limits": {
+						"memory": "16Mi"
+					}
+				}
+			}
+		]
+	}
+}`
+
+// NewPolicies returns n distinct cluster policies
+func NewPolicies(n int) ([]kyverno.ClusterPolicy, error) {
+	policies := make([]kyverno.ClusterPolicy, 0, n)
+	for i := 0; i < n; i++ {
+		var policy kyverno.ClusterPolicy
+		if err := json.Unmarshal([]byte(fmt.Sprintf(policyTemplate, i)), &policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// NewResources returns m distinct pods spread across ten namespaces
+func NewResources(m int) ([]unstructured.Unstructured, error) {
+	resources := make([]unstructured.Unstructured, 0, m)
+	for i := 0; i < m; i++ {
+		resource, err := utils.ConvertToUnstructured([]byte(fmt.Sprintf(resourceTemplate, i, i%10)))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// Admit processes a single admission request the same way the webhook does:
+// all mutate rules are applied first and the patched resource is validated
+func Admit(policies []kyverno.ClusterPolicy, resource unstructured.Unstructured) error {
+	raw, err := resource.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	ctx := context.NewContext()
+	if err := ctx.AddResource(raw); err != nil {
+		return err
+	}
+
+	policyContext := engine.PolicyContext{
+		NewResource: resource,
+		Context:     ctx,
+	}
+	for _, policy := range policies {
+		policyContext.Policy = policy
+		engineResponse := engine.Mutate(policyContext)
+		policyContext.NewResource = engineResponse.PatchedResource
+	}
+	for _, policy := range policies {
+		policyContext.Policy = policy
+		engineResponse := engine.Validate(policyContext)
+		if !engineResponse.IsSuccesful() {
+			return fmt.Errorf("policy %s failed on resource %s/%s", policy.Name, resource.GetNamespace(), resource.GetName())
+		}
+	}
+	return nil
+}
+
+// Run submits every synthetic resource as an admission request against
+// every synthetic policy and reports the observed latencies
+func Run(cfg Config) (Result, error) {
+	policies, err := NewPolicies(cfg.Policies)
+	if err != nil {
+		return Result{}, err
+	}
+	resources, err := NewResources(cfg.Resources)
+	if err != nil {
+		return Result{}, err
+	}
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan unstructured.Unstructured)
+	latencies := make([]time.Duration, 0, len(resources))
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+
+	startTime := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resource := range queue {
+				reqTime := time.Now()
+				err := Admit(policies, resource)
+				latency := time.Since(reqTime)
+
+				mu.Lock()
+				latencies = append(latencies, latency)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, resource := range resources {
+		queue <- resource
+	}
+	close(queue)
+	wg.Wait()
+
+	result := summarize(latencies)
+	result.Duration = time.Since(startTime)
+	return result, firstErr
+}
+
+func summarize(latencies []time.Duration) Result {
+	result := Result{Requests: len(latencies)}
+	if len(latencies) == 0 {
+		return result
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+	result.P50 = percentile(50)
+	result.P95 = percentile(95)
+	result.P99 = percentile(99)
+	result.Max = latencies[len(latencies)-1]
+	return result
+}
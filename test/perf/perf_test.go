@@ -0,0 +1,103 @@
+package perf
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+)
+
+var (
+	load      = flag.Bool("perf.load", false, "run the synthetic admission load test")
+	policies  = flag.Int("perf.policies", 50, "number of policies used by the load test")
+	resources = flag.Int("perf.resources", 1000, "number of resources used by the load test")
+	workers   = flag.Int("perf.workers", 4, "number of concurrent workers used by the load test")
+)
+
+func newPolicyContext(b *testing.B, policyIdx, resourceIdx int) engine.PolicyContext {
+	policies, err := NewPolicies(policyIdx + 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	resources, err := NewResources(resourceIdx + 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	resource := resources[resourceIdx]
+	raw, err := resource.MarshalJSON()
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.NewContext()
+	if err := ctx.AddResource(raw); err != nil {
+		b.Fatal(err)
+	}
+	return engine.PolicyContext{
+		Policy:      policies[policyIdx],
+		NewResource: resource,
+		Context:     ctx,
+	}
+}
+
+func BenchmarkMutate(b *testing.B) {
+	policyContext := newPolicyContext(b, 0, 0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.Mutate(policyContext)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	policyContext := newPolicyContext(b, 0, 0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.Validate(policyContext)
+	}
+}
+
+func BenchmarkAdmit(b *testing.B) {
+	for _, n := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("policies=%d", n), func(b *testing.B) {
+			policies, err := NewPolicies(n)
+			if err != nil {
+				b.Fatal(err)
+			}
+			resources, err := NewResources(1)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := Admit(policies, resources[0]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	result, err := Run(Config{Policies: 2, Resources: 10, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Requests != 10 {
+		t.Errorf("expected 10 requests, got %d", result.Requests)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if !*load {
+		t.Skip("load test disabled, enable with -perf.load")
+	}
+	result, err := Run(Config{Policies: *policies, Resources: *resources, Workers: *workers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("policies=%d resources=%d workers=%d: %v", *policies, *resources, *workers, result)
+}